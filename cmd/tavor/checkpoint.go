@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/zimmski/tavor/log"
+)
+
+// fuzzCheckpoint holds the position of a fuzzing campaign
+// Resumable fuzzing strategies save their position after the last finished generation as state, which is restored on resume. Other fuzzing strategies are deterministic for the same token graph, random seed and seed files. Their position is therefore given by the count of their finished generations, which are regenerated and skipped on resume.
+type fuzzCheckpoint struct {
+	Hash     string          `json:"hash"`
+	Strategy string          `json:"strategy"`
+	Seed     int64           `json:"seed"`
+	Steps    int             `json:"steps"`
+	State    json.RawMessage `json:"state,omitempty"`
+	Finished bool            `json:"finished"`
+
+	file     string
+	interval int
+}
+
+// readFuzzCheckpoint reads the checkpoint of the given state file
+func readFuzzCheckpoint(file string) (*fuzzCheckpoint, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read state file: %v", err)
+	}
+
+	var c fuzzCheckpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("cannot parse state file %s: %v", file, err)
+	}
+
+	c.file = file
+
+	return &c, nil
+}
+
+// fuzzCampaignHash returns the hash of everything that defines the token graph of a fuzzing campaign besides the random seed
+func fuzzCampaignHash(opts *options, format []byte) (string, error) {
+	h := sha256.New()
+
+	write := func(data []byte) {
+		// the length separates the data of different parts
+		_, _ = fmt.Fprintf(h, "%d:", len(data))
+		_, _ = h.Write(data)
+	}
+
+	write(format)
+	write([]byte("format-type=" + formatType(opts)))
+	write([]byte(fmt.Sprintf("max-repeat=%d", opts.Global.MaxRepeat)))
+	write([]byte("character-universe=" + opts.Global.CharacterUniverse))
+	write([]byte("output-encoding=" + opts.Fuzz.OutputEncoding))
+	write([]byte("encoding-errors=" + opts.Global.EncodingErrors))
+
+	if opts.Fuzz.Strategy == "TWay" {
+		write([]byte(fmt.Sprintf("tway-strength=%d", opts.Fuzz.TWayStrength)))
+	}
+
+	for _, filter := range opts.Fuzz.Filter.Filters {
+		write([]byte("filter=" + string(filter)))
+	}
+
+	seeds, err := fuzzSeeds(opts)
+	if err != nil {
+		return "", err
+	}
+
+	for _, seed := range seeds {
+		data, err := ioutil.ReadFile(seed)
+		if err != nil {
+			return "", fmt.Errorf("cannot read seed %s: %v", seed, err)
+		}
+
+		write(data)
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// done records that the given count of generations is finished with the given state of the strategy and writes the state file every interval
+func (c *fuzzCheckpoint) done(steps int, state []byte) error {
+	if c == nil {
+		return nil
+	}
+
+	c.Steps = steps
+	c.State = state
+
+	if c.interval > 1 && steps%c.interval != 0 {
+		return nil
+	}
+
+	return c.write()
+}
+
+// finish writes the final state file. Finished is set if the strategy has no further generations.
+func (c *fuzzCheckpoint) finish(finished bool) error {
+	if c == nil {
+		return nil
+	}
+
+	c.Finished = finished
+
+	log.Infof("checkpoint of %d generations written to %s", c.Steps, c.file)
+
+	return c.write()
+}
+
+func (c *fuzzCheckpoint) write() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// a crash while writing must not destroy the previous state
+	tmp := c.file + ".tmp"
+
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("cannot write state file: %v", err)
+	}
+
+	if err := os.Rename(tmp, c.file); err != nil {
+		return fmt.Errorf("cannot write state file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMainFuzzResume(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = 1 | 2 | 3 | 4 | 5 | 6\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	folder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.Remove(f.Name()))
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	state := folder + "/state.json"
+
+	exitCode, out := execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "AllPermutations", "--max-steps", "2", "--checkpoint", state})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, "1\n2", out)
+
+	c, err := readFuzzCheckpoint(state)
+	assert.Nil(t, err)
+	assert.Equal(t, "AllPermutations", c.Strategy)
+	assert.Equal(t, 2, c.Steps)
+	assert.False(t, c.Finished)
+	// the position of the strategy is restored instead of regenerated
+	assert.NotEmpty(t, c.State)
+
+	// the strategy and seed are taken from the state file
+	exitCode, out = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--max-steps", "4", "--resume", state})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, "3\n4", out)
+
+	exitCode, out = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--resume", state})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, "5\n6", out)
+
+	c, err = readFuzzCheckpoint(state)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, c.Steps)
+	assert.True(t, c.Finished)
+
+	exitCode, out = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--resume", state})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, "", out)
+
+	// a campaign which mutates seeds
+	g, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = g.WriteString("START = +(1 | 2) ?(\"x\")\n")
+	assert.Nil(t, err)
+
+	err = g.Close()
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.Remove(g.Name()))
+	}()
+
+	seed := folder + "/seed"
+	assert.Nil(t, ioutil.WriteFile(seed, []byte("12x"), 0644))
+
+	exitCode, all := execMain(t, []string{"--format-file", g.Name(), "--seed", "3", "fuzz", "--input-file", seed, "--result-separator", ";"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, 4, len(strings.Split(all, ";")))
+
+	exitCode, first := execMain(t, []string{"--format-file", g.Name(), "--seed", "3", "fuzz", "--input-file", seed, "--result-separator", ";", "--max-steps", "2", "--checkpoint", state})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	exitCode, second := execMain(t, []string{"--format-file", g.Name(), "fuzz", "--input-file", seed, "--result-separator", ";", "--resume", state})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	assert.Equal(t, all, first+";"+second)
+
+	// changed seed files are a different campaign
+	assert.Nil(t, ioutil.WriteFile(seed, []byte("21x"), 0644))
+
+	exitCode, _ = execMain(t, []string{"--format-file", g.Name(), "fuzz", "--input-file", seed, "--resume", state})
+	assert.Equal(t, exitCodeError, exitCode)
+
+	// a different campaign cannot be resumed
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "--max-repeat", "3", "fuzz", "--resume", state})
+	assert.Equal(t, exitCodeError, exitCode)
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "--seed", "8", "fuzz", "--resume", state})
+	assert.Equal(t, exitCodeError, exitCode)
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "FeedbackRandom", "--checkpoint", state})
+	assert.Equal(t, exitCodeError, exitCode)
+}
+
+func TestMainFuzzExecResume(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = 1 | 2 | 3 | 4 | 5 | 6\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	folder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.Remove(f.Name()))
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	state := folder + "/state.json"
+	results := folder + "/results"
+	assert.Nil(t, os.Mkdir(results, 0755))
+
+	args := []string{"--format-file", f.Name(), "fuzz", "--exec", "cat", "--exec-exact-stdout", "1", "--workers", "2", "--result-folder", results}
+
+	exitCode, _ := execMain(t, append(args, "--strategy", "AllPermutations", "--max-steps", "3", "--checkpoint", state))
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	exitCode, _ = execMain(t, append(args, "--resume", state))
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	files, err := ioutil.ReadDir(results)
+	assert.Nil(t, err)
+
+	var failed []string
+	for _, file := range files {
+		data, err := ioutil.ReadFile(results + "/" + file.Name())
+		assert.Nil(t, err)
+
+		// the step IDs continue after the resumed position
+		step := strings.Split(file.Name(), "-")[1]
+		assert.Equal(t, string(data), step)
+
+		failed = append(failed, string(data))
+	}
+	sort.Strings(failed)
+
+	assert.Equal(t, []string{"2", "3", "4", "5", "6"}, failed)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zimmski/tavor/log"
+)
+
+// connectTarget sends generations to a TCP, UDP or Unix socket target and reads the responses
+type connectTarget struct {
+	network string
+	address string
+	timeout time.Duration
+	reuse   bool
+
+	mutex sync.Mutex
+	conn  net.Conn
+	// connected is set to 1 after the first successful connection
+	connected int32
+}
+
+// connectFailure is returned if the target was reachable before but fails for a generation, e.g. because it crashed on the generation and refuses or resets the connection
+type connectFailure struct {
+	err error
+}
+
+func (e *connectFailure) Error() string {
+	return e.err.Error()
+}
+
+// newConnectTarget returns a new target for the given URL e.g. "tcp://localhost:1234", "udp://localhost:1234" or "unix:///tmp/target.sock".
+// The timeout is used for connecting, writing and reading. If reuse is set the same connection is used for all generations.
+func newConnectTarget(target string, timeout time.Duration, reuse bool) (*connectTarget, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connect target %q: %v", target, err)
+	}
+
+	c := &connectTarget{
+		network: u.Scheme,
+		timeout: timeout,
+		reuse:   reuse,
+	}
+
+	switch u.Scheme {
+	case "tcp", "udp":
+		c.address = u.Host
+	case "unix":
+		c.address = u.Path
+	default:
+		return nil, fmt.Errorf("unknown network %q of connect target %q", u.Scheme, target)
+	}
+
+	if c.address == "" {
+		return nil, fmt.Errorf("connect target %q has no address", target)
+	}
+
+	return c, nil
+}
+
+func (c *connectTarget) String() string {
+	return c.network + "://" + c.address
+}
+
+// send writes the data to the target and returns the response. The response ends when the target closes the connection or when no more data is received within the timeout. UDP responses consist of exactly one datagram.
+// Errors after the target was reachable once are returned as connectFailure since they are an outcome of the generation. Otherwise the target cannot be used at all.
+func (c *connectTarget) send(data []byte) ([]byte, error) {
+	if c.reuse {
+		// generations must not interleave on a shared connection
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+	}
+
+	conn := c.conn
+
+	if conn == nil {
+		var err error
+
+		conn, err = net.DialTimeout(c.network, c.address, c.timeout)
+		if err != nil {
+			err = fmt.Errorf("Could not connect to %s: %v", c, err)
+
+			if atomic.LoadInt32(&c.connected) == 1 {
+				return nil, &connectFailure{err: err}
+			}
+
+			return nil, err
+		}
+
+		atomic.StoreInt32(&c.connected, 1)
+
+		if c.reuse {
+			c.conn = conn
+		}
+	}
+
+	response, err := c.exchange(conn, data)
+
+	if !c.reuse || err != nil {
+		if c.reuse {
+			// connect again on the next generation
+			c.conn = nil
+		}
+
+		if err := conn.Close(); err != nil {
+			log.Errorf("Could not close connection to %s: %v", c, err)
+		}
+	}
+
+	if err != nil {
+		return response, &connectFailure{err: err}
+	}
+
+	return response, nil
+}
+
+func (c *connectTarget) exchange(conn net.Conn, data []byte) ([]byte, error) {
+	if err := conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write(data); err != nil {
+		return nil, fmt.Errorf("Could not write to %s: %v", c, err)
+	}
+
+	// signal the end of the generation if the connection is not used again
+	if w, ok := conn.(interface {
+		CloseWrite() error
+	}); ok && !c.reuse && c.network != "udp" {
+		if err := w.CloseWrite(); err != nil {
+			return nil, fmt.Errorf("Could not close writing to %s: %v", c, err)
+		}
+	}
+
+	var response bytes.Buffer
+	buf := make([]byte, 64*1024)
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+			return nil, err
+		}
+
+		n, err := conn.Read(buf)
+		response.Write(buf[:n])
+
+		if err == io.EOF {
+			break
+		} else if e, ok := err.(net.Error); ok && e.Timeout() {
+			log.Debugf("read timeout of %s reached", c)
+
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("Could not read from %s: %v", c, err)
+		}
+
+		// a datagram is the whole response
+		if c.network == "udp" {
+			break
+		}
+	}
+
+	return response.Bytes(), nil
+}
+
+// close closes the reused connection
+func (c *connectTarget) close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.conn != nil {
+		if err := c.conn.Close(); err != nil {
+			log.Errorf("Could not close connection to %s: %v", c, err)
+		}
+
+		c.conn = nil
+	}
+}
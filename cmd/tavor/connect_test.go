@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serveConnectTarget answers every connection of the listener with the handled data. If untilEOF is set the whole data until the client stops writing is handled, otherwise every single read.
+func serveConnectTarget(t *testing.T, l net.Listener, untilEOF bool, handle func(data []byte) []byte) {
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer func() {
+					assert.Nil(t, conn.Close())
+				}()
+
+				if untilEOF {
+					data, err := ioutil.ReadAll(conn)
+					if err != nil {
+						return
+					}
+
+					_, _ = conn.Write(handle(data))
+
+					return
+				}
+
+				buf := make([]byte, 1024)
+
+				for {
+					n, err := conn.Read(buf)
+					if n > 0 {
+						_, _ = conn.Write(handle(buf[:n]))
+					}
+					if err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+}
+
+func TestConnectTarget(t *testing.T) {
+	for _, target := range []string{"", "tcp://", "http://localhost:80", "unix://"} {
+		_, err := newConnectTarget(target, time.Second, false)
+		assert.NotNil(t, err, target)
+	}
+
+	upper := func(data []byte) []byte {
+		return bytes.ToUpper(data)
+	}
+
+	// TCP with a connection per generation
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, l.Close())
+	}()
+	serveConnectTarget(t, l, true, upper)
+
+	c, err := newConnectTarget("tcp://"+l.Addr().String(), time.Second, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "tcp://"+l.Addr().String(), c.String())
+
+	for _, data := range []string{"abc", "def"} {
+		response, err := c.send([]byte(data))
+		assert.Nil(t, err)
+		assert.Equal(t, strings.ToUpper(data), string(response))
+	}
+	assert.Nil(t, c.conn)
+
+	// TCP with a reused connection where the response ends with the timeout
+	lr, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, lr.Close())
+	}()
+	serveConnectTarget(t, lr, false, upper)
+
+	c, err = newConnectTarget("tcp://"+lr.Addr().String(), 100*time.Millisecond, true)
+	assert.Nil(t, err)
+
+	response, err := c.send([]byte("abc"))
+	assert.Nil(t, err)
+	assert.Equal(t, "ABC", string(response))
+	conn := c.conn
+	assert.NotNil(t, conn)
+
+	response, err = c.send([]byte("def"))
+	assert.Nil(t, err)
+	assert.Equal(t, "DEF", string(response))
+	assert.Equal(t, conn, c.conn)
+
+	c.close()
+	assert.Nil(t, c.conn)
+
+	// Unix socket
+	dir, err := ioutil.TempDir("", "tavor-connect-test")
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, os.RemoveAll(dir))
+	}()
+
+	socket := filepath.Join(dir, "target.sock")
+
+	lu, err := net.Listen("unix", socket)
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, lu.Close())
+	}()
+	serveConnectTarget(t, lu, true, upper)
+
+	c, err = newConnectTarget("unix://"+socket, time.Second, false)
+	assert.Nil(t, err)
+
+	response, err = c.send([]byte("xyz"))
+	assert.Nil(t, err)
+	assert.Equal(t, "XYZ", string(response))
+
+	// nobody is listening anymore
+	c, err = newConnectTarget("unix://"+filepath.Join(dir, "missing.sock"), time.Second, false)
+	assert.Nil(t, err)
+
+	_, err = c.send([]byte("xyz"))
+	assert.NotNil(t, err)
+	_, ok := err.(*connectFailure)
+	assert.False(t, ok)
+
+	// failures after the target was reachable are outcomes of the generation
+	lc, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	serveConnectTarget(t, lc, true, upper)
+
+	c, err = newConnectTarget("tcp://"+lc.Addr().String(), time.Second, false)
+	assert.Nil(t, err)
+
+	_, err = c.send([]byte("abc"))
+	assert.Nil(t, err)
+
+	assert.Nil(t, lc.Close())
+
+	_, err = c.send([]byte("abc"))
+	assert.NotNil(t, err)
+	_, ok = err.(*connectFailure)
+	assert.True(t, ok)
+}
+
+func TestMainConnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, l.Close())
+	}()
+
+	// the target crashes on every "c"
+	serveConnectTarget(t, l, true, func(data []byte) []byte {
+		if bytes.Contains(data, []byte("c")) {
+			return []byte("crash")
+		}
+
+		return []byte("ok")
+	})
+
+	target := "tcp://" + l.Addr().String()
+
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = \"a\" ?(\"b\") ?(\"c\")\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	folder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.Remove(f.Name()))
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	exitCode, _ := execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "AllPermutations", "--connect", target, "--exec-match-stdout", "^ok$", "--result-folder", folder})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	files, err := ioutil.ReadDir(folder)
+	assert.Nil(t, err)
+
+	var failed []string
+	for _, file := range files {
+		data, err := ioutil.ReadFile(folder + "/" + file.Name())
+		assert.Nil(t, err)
+
+		failed = append(failed, string(data))
+	}
+
+	assert.Equal(t, 2, len(failed))
+	for _, data := range failed {
+		assert.Contains(t, data, "c")
+	}
+
+	// failures are bucketed by their response
+	triageFolder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, os.RemoveAll(triageFolder))
+	}()
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "AllPermutations", "--connect", target, "--exec-match-stdout", "^ok$", "--result-folder", triageFolder, "--result-extension", ".txt", "--triage"})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	files, err = ioutil.ReadDir(triageFolder)
+	assert.Nil(t, err)
+
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	sort.Strings(names)
+
+	assert.Equal(t, 2, len(names))
+	assert.True(t, strings.HasPrefix(names[0], "bucket-"))
+	assert.True(t, strings.HasSuffix(names[0], ".txt"))
+	assert.Equal(t, triageReportFile, names[1])
+
+	input := folder + "/input"
+	assert.Nil(t, ioutil.WriteFile(input, []byte("abc"), 0644))
+
+	exitCode, out := execMain(t, []string{"--format-file", f.Name(), "reduce", "--input-file", input, "--connect", target, "--exec-match-stdout", "crash"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, "ac", strings.TrimSpace(out))
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--connect", target, "--exec", "cat"})
+	assert.Equal(t, exitCodeError, exitCode)
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--connect", "http://localhost", "--exec-exact-stdout"})
+	assert.Equal(t, exitCodeError, exitCode)
+}
+
+func TestMainConnectCrash(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	// the target crashes on "2" and refuses all further connections
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			data, err := ioutil.ReadAll(conn)
+			if err == nil && string(data) != "2" {
+				_, _ = conn.Write([]byte("ok"))
+			} else {
+				_ = l.Close()
+			}
+
+			assert.Nil(t, conn.Close())
+		}
+	}()
+
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = 1 | 2 | 3\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	folder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.Remove(f.Name()))
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	exitCode, _ := execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "AllPermutations", "--connect", "tcp://" + l.Addr().String(), "--exec-match-stdout", "^ok$", "--result-folder", folder})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	files, err := ioutil.ReadDir(folder)
+	assert.Nil(t, err)
+
+	var failed []string
+	for _, file := range files {
+		data, err := ioutil.ReadFile(folder + "/" + file.Name())
+		assert.Nil(t, err)
+
+		failed = append(failed, string(data))
+	}
+	sort.Strings(failed)
+
+	assert.Equal(t, []string{"2", "3"}, failed)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zimmski/tavor/parser"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/constraints"
+	"github.com/zimmski/tavor/token/lists"
+)
+
+// coverage tracks which alternatives, optional states and repeat counts of the format file are generated by a fuzzing campaign
+type coverage struct {
+	generations int
+
+	items []*coverageItem
+	keys  map[coverageKey]*coverageItem
+}
+
+type coverageKey struct {
+	offset int
+	kind   string
+}
+
+type coverageItem struct {
+	Definition string           `json:"definition"`
+	Kind       string           `json:"kind"`
+	Line       int              `json:"line"`
+	Column     int              `json:"column"`
+	Values     []*coverageValue `json:"values"`
+
+	offset int
+}
+
+type coverageValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// newCoverage returns a new coverage which tracks the choices of the given token definitions
+func newCoverage(definitions []parser.Definition) *coverage {
+	c := &coverage{
+		keys: make(map[coverageKey]*coverageItem),
+	}
+
+	for _, d := range definitions {
+		for _, tok := range d.Choices {
+			kind := coverageKind(tok)
+			key := coverageKey{
+				offset: tok.Position().Offset,
+				kind:   kind,
+			}
+
+			// parameterised token definitions share the positions of their instances
+			if _, ok := c.keys[key]; ok {
+				continue
+			}
+
+			item := &coverageItem{
+				Definition: d.Name,
+				Kind:       kind,
+				Line:       tok.Position().Line,
+				Column:     tok.Position().Column,
+
+				offset: key.offset,
+			}
+
+			for _, v := range coverageValues(tok) {
+				item.Values = append(item.Values, &coverageValue{
+					Value: v,
+				})
+			}
+
+			c.keys[key] = item
+			c.items = append(c.items, item)
+		}
+	}
+
+	sort.SliceStable(c.items, func(i, j int) bool {
+		return c.items[i].offset < c.items[j].offset
+	})
+
+	return c
+}
+
+func coverageKind(tok token.Token) string {
+	switch tok.(type) {
+	case *lists.One:
+		return "alternation"
+	case *constraints.Optional:
+		return "optional"
+	case *lists.Repeat:
+		return "repeat"
+	}
+
+	return ""
+}
+
+// coverageValues returns the names of all values of the choice
+func coverageValues(tok token.Token) []string {
+	var values []string
+
+	switch t := tok.(type) {
+	case *lists.One:
+		for i := 1; i <= t.InternalLen(); i++ {
+			values = append(values, "alternative "+strconv.Itoa(i))
+		}
+	case *constraints.Optional:
+		values = []string{"absent", "present"}
+	case *lists.Repeat:
+		for i := t.From(); i <= t.To(); i++ {
+			values = append(values, strconv.FormatInt(i, 10)+" repetitions")
+		}
+	}
+
+	return values
+}
+
+// coverageValueOf returns the index of the current value of the choice
+func coverageValueOf(tok token.Token) (int, bool) {
+	switch t := tok.(type) {
+	case *lists.One:
+		current, _ := t.Get(0)
+
+		for i := 0; i < t.InternalLen(); i++ {
+			if c, _ := t.InternalGet(i); c == current {
+				return i, true
+			}
+		}
+	case *constraints.Optional:
+		if t.Get() == nil {
+			return 0, true
+		}
+
+		return 1, true
+	case *lists.Repeat:
+		return t.Len() - int(t.From()), true
+	}
+
+	return 0, false
+}
+
+// add records the choices of the current generation of the token graph
+func (c *coverage) add(root token.Token) {
+	if c == nil {
+		return
+	}
+
+	c.generations++
+
+	_ = token.Walk(root, func(tok token.Token) error {
+		t, ok := tok.(token.PositionedToken)
+		if !ok {
+			return nil
+		}
+
+		pos := t.Position()
+		if !pos.IsValid() {
+			return nil
+		}
+
+		item, ok := c.keys[coverageKey{
+			offset: pos.Offset,
+			kind:   coverageKind(tok),
+		}]
+		if !ok {
+			return nil
+		}
+
+		if v, ok := coverageValueOf(tok); ok && v >= 0 && v < len(item.Values) {
+			item.Values[v].Count++
+		}
+
+		return nil
+	})
+}
+
+// uncovered returns the values of the item which were never generated
+func (i *coverageItem) uncovered() []string {
+	var values []string
+
+	for _, v := range i.Values {
+		if v.Count == 0 {
+			values = append(values, v.Value)
+		}
+	}
+
+	return values
+}
+
+// report writes the coverage as JSON to the given file and a summary with all uncovered choices ordered by their position to the writer
+func (c *coverage) report(file string, w io.Writer) error {
+	if c == nil {
+		return nil
+	}
+
+	covered, total := 0, 0
+
+	for _, item := range c.items {
+		total += len(item.Values)
+		covered += len(item.Values) - len(item.uncovered())
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Generations int             `json:"generations"`
+		Covered     int             `json:"covered"`
+		Total       int             `json:"total"`
+		Items       []*coverageItem `json:"items"`
+	}{
+		Generations: c.generations,
+		Covered:     covered,
+		Total:       total,
+		Items:       c.items,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("Cannot write coverage report: %v", err)
+	}
+
+	percent := 100.0
+	if total != 0 {
+		percent = float64(covered) * 100 / float64(total)
+	}
+
+	fmt.Fprintf(w, "Coverage of %d generations: %d of %d choice values generated (%.1f%%)\n", c.generations, covered, total, percent)
+
+	for _, item := range c.items {
+		if uncovered := item.uncovered(); len(uncovered) != 0 {
+			fmt.Fprintf(w, "%d:%d %s %s never generated %s\n", item.Line, item.Column, item.Definition, item.Kind, strings.Join(uncovered, ", "))
+		}
+	}
+
+	return nil
+}
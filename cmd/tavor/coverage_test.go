@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zimmski/tavor/parser"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/lists"
+)
+
+func TestCoverage(t *testing.T) {
+	doc, definitions, err := parser.ParseTavorDefinitions(strings.NewReader(`START = Digit ?("x") +1,2("y")
+Digit = 0 | 1 | 2
+`))
+	assert.Nil(t, err)
+
+	cov := newCoverage(definitions)
+
+	var kinds []string
+	for _, item := range cov.items {
+		kinds = append(kinds, item.Definition+" "+item.Kind)
+	}
+	assert.Equal(t, []string{"START optional", "START repeat", "Digit alternation"}, kinds)
+
+	// the initial generation is 0 with an active optional and one repetition
+	cov.add(doc)
+	assert.Equal(t, "0xy", doc.String())
+
+	assert.Equal(t, []string{"absent"}, cov.items[0].uncovered())
+	assert.Equal(t, []string{"2 repetitions"}, cov.items[1].uncovered())
+	assert.Equal(t, []string{"alternative 2", "alternative 3"}, cov.items[2].uncovered())
+
+	// choose the second digit
+	err = token.Walk(doc, func(tok token.Token) error {
+		if d, ok := tok.(*lists.One); ok {
+			return d.Permutation(1)
+		}
+
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "1xy", doc.String())
+
+	cov.add(doc)
+	assert.Equal(t, []string{"alternative 3"}, cov.items[2].uncovered())
+
+	f, err := ioutil.TempFile("", "tavor-coverage-test")
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	defer func() {
+		assert.Nil(t, os.Remove(f.Name()))
+	}()
+
+	var summary bytes.Buffer
+	assert.Nil(t, cov.report(f.Name(), &summary))
+
+	assert.Equal(t, `Coverage of 2 generations: 4 of 7 choice values generated (57.1%)
+1:15 START optional never generated absent
+1:22 START repeat never generated 2 repetitions
+2:9 Digit alternation never generated alternative 3
+`, summary.String())
+
+	data, err := ioutil.ReadFile(f.Name())
+	assert.Nil(t, err)
+
+	var report struct {
+		Generations int
+		Covered     int
+		Total       int
+		Items       []*coverageItem
+	}
+	assert.Nil(t, json.Unmarshal(data, &report))
+
+	assert.Equal(t, 2, report.Generations)
+	assert.Equal(t, 4, report.Covered)
+	assert.Equal(t, 7, report.Total)
+	assert.Equal(t, "Digit", report.Items[2].Definition)
+	assert.Equal(t, []*coverageValue{
+		{Value: "alternative 1", Count: 1},
+		{Value: "alternative 2", Count: 1},
+		{Value: "alternative 3", Count: 0},
+	}, report.Items[2].Values)
+
+	// a nil coverage is not tracked
+	var nilCoverage *coverage
+	nilCoverage.add(doc)
+	assert.Nil(t, nilCoverage.report("", nil))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"github.com/zimmski/tavor/encoding"
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+// encodeDocument wraps the token graph into the given character encoding. The token graph is returned unchanged if no encoding is given.
+func encodeDocument(doc token.Token, name string, mode string) (token.Token, error) {
+	if name == "" {
+		return doc, nil
+	}
+
+	log.Infof("using %s encoding", name)
+
+	return primitives.NewEncoded(doc, name, encoding.ErrorMode(mode))
+}
+
+// hasEncodedTokens returns if the token graph holds tokens which transcode their text into a character encoding
+func hasEncodedTokens(doc token.Token) bool {
+	found := false
+
+	_ = token.WalkInternal(doc, func(tok token.Token) error {
+		if _, ok := tok.(*primitives.Encoded); ok {
+			found = true
+		}
+
+		return nil
+	})
+
+	return found
+}
+
+// logEncodingErrors logs the invalid and unrepresentable characters of all encoded tokens of the current generation and returns their count
+func logEncodingErrors(doc token.Token) int {
+	count := 0
+
+	_ = token.Walk(doc, func(tok token.Token) error {
+		if e, ok := tok.(*primitives.Encoded); ok {
+			if err := e.Error(); err != nil {
+				log.Errorf("cannot encode generation as %s: %v", e.Name(), err)
+
+				count++
+			}
+		}
+
+		return nil
+	})
+
+	return count
+}
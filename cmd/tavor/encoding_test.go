@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zimmski/tavor/parser"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/lists"
+)
+
+func TestEncodingErrors(t *testing.T) {
+	doc, err := parser.ParseTavor(strings.NewReader("START = \"a\" | \"€\"\n"))
+	assert.Nil(t, err)
+	assert.False(t, hasEncodedTokens(doc))
+
+	doc, err = encodeDocument(doc, "", "report")
+	assert.Nil(t, err)
+	assert.False(t, hasEncodedTokens(doc))
+
+	doc, err = encodeDocument(doc, "latin1", "report")
+	assert.Nil(t, err)
+	assert.True(t, hasEncodedTokens(doc))
+
+	assert.Equal(t, "a", doc.String())
+	assert.Equal(t, 0, logEncodingErrors(doc))
+
+	err = token.Walk(doc, func(tok token.Token) error {
+		if o, ok := tok.(*lists.One); ok {
+			return o.Permutation(1)
+		}
+
+		return nil
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, "?", doc.String())
+	assert.Equal(t, 1, logEncodingErrors(doc))
+
+	_, err = encodeDocument(doc, "latin1", "ignore")
+	assert.NotNil(t, err)
+}
+
+func TestMainEncoding(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = \"Zoë\" | \"Léa\"\n")
+	assert.Nil(t, err)
+
+	assert.Nil(t, f.Close())
+
+	input, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = input.WriteString("L\x00\xe9\x00a\x00")
+	assert.Nil(t, err)
+
+	assert.Nil(t, input.Close())
+
+	defer func() {
+		assert.Nil(t, os.Remove(f.Name()))
+		assert.Nil(t, os.Remove(input.Name()))
+	}()
+
+	exitCode, out := execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "AllPermutations", "--output-encoding", "latin1", "--result-separator", ";"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, "Zo\xeb;L\xe9a", out)
+
+	exitCode, out = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "AllPermutations", "--output-encoding", "utf16be", "--filter", "EncodingInjection", "--result-separator", ";"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, "\x00Z\x00o\x00\xeb;\x00L\x00\xe9\x00a;\xd8\x00\x00Z\x00o\x00\xeb;\xd8\x00\x00L\x00\xe9\x00a", out)
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "validate", "--input-file", input.Name(), "--input-encoding", "utf16le"})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "validate", "--input-file", input.Name()})
+	assert.Equal(t, exitCodeInvalidInputFile, exitCode)
+}
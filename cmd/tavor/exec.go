@@ -0,0 +1,391 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	tavorFuzzStrategy "github.com/zimmski/tavor/fuzz/strategy"
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/parser"
+	tavorReduceStrategy "github.com/zimmski/tavor/reduce/strategy"
+	"github.com/zimmski/tavor/token"
+)
+
+// errNotReducible is returned if the generation cannot be parsed into a token graph of the format for reducing
+var errNotReducible = errors.New("generation cannot be parsed by the format")
+
+type fuzzExecOptions struct {
+	execs             []string
+	execFileArguments []int
+	argumentType      string
+	folder            string
+
+	// parse returns a new token graph of the format which is used for reducing failures
+	parse func() (token.Token, error)
+
+	// connect is used instead of executing a binary if it is set
+	connect *connectTarget
+	// timeout kills an execution which takes longer, 0 means no timeout
+	timeout time.Duration
+
+	exactExitCode int
+	exactStderr   string
+	exactStdout   string
+	matchStderr   *regexp.Regexp
+	matchStdout   *regexp.Regexp
+}
+
+type fuzzGeneration struct {
+	stepID   int
+	rendered *tavorFuzzStrategy.Generation
+	// state is the position of a resumable fuzzing strategy after the generation
+	state []byte
+}
+
+type fuzzExecResult struct {
+	generation fuzzGeneration
+
+	tmp *os.File
+
+	exitCode int
+	signal   syscall.Signal
+	hang     bool
+	// connect is set if the generation was sent to a connect target whose response is held by stdout
+	connect bool
+	// connectFailure is set if the connect target failed for the generation
+	connectFailure error
+	stderr         bytes.Buffer
+	stdout         bytes.Buffer
+
+	err error
+}
+
+func (o *fuzzExecOptions) writeTmpFile(kind string, stepID int, docOut string) (*os.File, error) {
+	tmp, err := ioutil.TempFile(o.folder, fmt.Sprintf("%s-%d-", kind, stepID))
+	if err != nil {
+		return nil, fmt.Errorf("Cannot create tmp file: %v", err)
+	}
+	_, err = tmp.WriteString(docOut)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot write to tmp file: %v", err)
+	}
+
+	return tmp, nil
+}
+
+// execute runs the exec command once for the given generation. It does not evaluate the outcome, this is done by the caller in the order of the fuzzing steps.
+func (o *fuzzExecOptions) execute(g fuzzGeneration) *fuzzExecResult {
+	res := &fuzzExecResult{
+		generation: g,
+	}
+
+	if o.connect != nil {
+		res.connect = true
+
+		response, err := o.connect.send([]byte(g.rendered.Data))
+		if e, ok := err.(*connectFailure); ok {
+			res.connectFailure = e
+		} else if err != nil {
+			res.err = err
+
+			return res
+		}
+
+		res.stdout.Write(response)
+
+		return res
+	}
+
+	execs := make([]string, len(o.execs))
+	copy(execs, o.execs)
+
+	var err error
+
+	if o.argumentType == "argument" {
+		res.tmp, err = o.writeTmpFile("fuzz", g.stepID, g.rendered.Data)
+		if err != nil {
+			res.err = err
+
+			return res
+		}
+
+		for _, v := range o.execFileArguments {
+			execs[v] = res.tmp.Name()
+		}
+	}
+
+	execCommand := exec.Command(execs[0], execs[1:]...)
+
+	if o.argumentType == "environment" {
+		res.tmp, err = o.writeTmpFile("fuzz", g.stepID, g.rendered.Data)
+		if err != nil {
+			res.err = err
+
+			return res
+		}
+
+		execCommand.Env = []string{fmt.Sprintf("TAVOR_FUZZ_FILE=%s", res.tmp.Name())}
+	}
+
+	execCommand.Stderr = &res.stderr
+	execCommand.Stdout = &res.stdout
+
+	var stdin []byte
+	if o.argumentType == "stdin" {
+		stdin = []byte(g.rendered.Data)
+	}
+
+	res.exitCode, res.hang, res.err = runExec(execCommand, o.timeout, stdin)
+
+	if execCommand.ProcessState != nil {
+		if ws, ok := execCommand.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			res.signal = ws.Signal()
+		}
+	}
+
+	return res
+}
+
+// runExec starts the command, feeds it the given stdin data if it is not nil and waits for the command to finish. If the timeout is reached the whole process group of the command is killed and hang is true.
+func runExec(execCommand *exec.Cmd, timeout time.Duration, stdin []byte) (exitCode int, hang bool, err error) {
+	if timeout > 0 {
+		// children of the command have to be killed too, otherwise they keep the output pipes open
+		execCommand.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+
+	stdinPipe, err := execCommand.StdinPipe()
+	if err != nil {
+		return 0, false, fmt.Errorf("Could not get stdin pipe: %s", err)
+	}
+
+	err = execCommand.Start()
+	if err != nil {
+		return 0, false, fmt.Errorf("Could not start exce: %s", err)
+	}
+
+	var timer *time.Timer
+	if timeout > 0 {
+		pid := execCommand.Process.Pid
+
+		timer = time.AfterFunc(timeout, func() {
+			log.Infof("Execution reached the timeout of %s", timeout)
+
+			if err := syscall.Kill(-pid, syscall.SIGKILL); err != nil {
+				log.Errorf("Could not kill exec: %s", err)
+			}
+		})
+	}
+
+	if stdin != nil {
+		_, err := stdinPipe.Write(stdin)
+		if err != nil && (timer == nil || timer.Stop()) {
+			return 0, false, fmt.Errorf("Could not write stdin to exec: %s", err)
+		}
+
+		// the exec was killed while writing if the write failed
+		if err == nil {
+			if err := stdinPipe.Close(); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	err = execCommand.Wait()
+
+	if timer != nil && !timer.Stop() {
+		hang = true
+	}
+
+	if err == nil {
+		exitCode = 0
+	} else if e, ok := err.(*exec.ExitError); ok {
+		exitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
+	} else {
+		return 0, hang, fmt.Errorf("Could not execute exec successfully: %s", err)
+	}
+
+	return exitCode, hang, nil
+}
+
+// validate compares the outcome of an execution with the exec options and returns false if the outcome is not the expected one.
+func (o *fuzzExecOptions) validate(res *fuzzExecResult) bool {
+	if res.hang {
+		log.Infof("Execution hung")
+
+		return false
+	}
+	if res.connectFailure != nil {
+		log.Infof("Connect target failed: %s", res.connectFailure)
+
+		return false
+	}
+
+	log.Infof("Exit status was %d", res.exitCode)
+
+	oks := 0
+	oksNeeded := 0
+
+	if o.exactExitCode != -1 {
+		oksNeeded++
+
+		if o.exactExitCode == res.exitCode {
+			log.Infof("Same exit code")
+
+			oks++
+		} else {
+			log.Infof("Not the same exit code")
+		}
+	}
+	if o.exactStderr != "" {
+		oksNeeded++
+
+		if o.exactStderr == res.stderr.String() {
+			log.Infof("Same stderr")
+
+			oks++
+		} else {
+			log.Infof("Not the same stderr")
+		}
+	}
+	if o.exactStdout != "" {
+		oksNeeded++
+
+		if o.exactStdout == res.stdout.String() {
+			log.Infof("Same stdout")
+
+			oks++
+		} else {
+			log.Infof("Not the same stdout")
+		}
+	}
+	if o.matchStderr != nil {
+		oksNeeded++
+
+		if o.matchStderr.Match(res.stderr.Bytes()) {
+			log.Infof("Same stderr matching")
+
+			oks++
+		} else {
+			log.Infof("Not the same stderr matching")
+		}
+	}
+	if o.matchStdout != nil {
+		oksNeeded++
+
+		if o.matchStdout.Match(res.stdout.Bytes()) {
+			log.Infof("Same stdout matching")
+
+			oks++
+		} else {
+			log.Infof("Not the same stdout matching")
+		}
+	}
+
+	if oksNeeded == 0 {
+		log.Warnf("Not defined what to compare")
+
+		return true
+	}
+
+	if oks != oksNeeded {
+		log.Infof("Not the same output")
+
+		return false
+	}
+
+	log.Infof("Same output")
+
+	return true
+}
+
+// reduce minimizes the failing generation of the given result with the reduce strategy. The generation is parsed into a new token graph of the format since the token graph of the fuzzing strategy is permuted further while failures are reduced. Every reduction step is executed and kept if it fails with the same signature as the original generation. The minimized generation is returned.
+func (o *fuzzExecOptions) reduce(res *fuzzExecResult, strat tavorReduceStrategy.Strategy, signature func(res *fuzzExecResult) string) (string, error) {
+	root, err := o.parse()
+	if err != nil {
+		return "", err
+	}
+
+	if errs := parser.ParseInternal(root, strings.NewReader(res.generation.rendered.Data)); len(errs) != 0 {
+		log.Debugf("Cannot parse generation of step %d: %v", res.generation.stepID, errs)
+
+		return "", errNotReducible
+	}
+
+	original := signature(res)
+
+	contin, feedback, err := strat(root)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range contin {
+		r := o.execute(fuzzGeneration{
+			stepID: res.generation.stepID,
+			rendered: &tavorFuzzStrategy.Generation{
+				Data: root.String(),
+			},
+		})
+
+		if r.tmp != nil {
+			if err := os.Remove(r.tmp.Name()); err != nil {
+				log.Errorf("Could not remove tmp file %q: %s", r.tmp.Name(), err)
+			}
+		}
+
+		if r.err != nil {
+			// the strategy stops as soon as its feedback channel is closed
+			close(feedback)
+
+			return "", r.err
+		}
+
+		if !o.validate(r) && signature(r) == original {
+			log.Infof("Same failure, continue reducing")
+
+			feedback <- tavorReduceStrategy.Good
+		} else {
+			log.Infof("Not the same failure, do another step")
+
+			feedback <- tavorReduceStrategy.Bad
+		}
+
+		contin <- i
+	}
+
+	return root.String(), nil
+}
+
+// runFuzzExecWorkers reads the generations of the given channel and executes them using the given amount of workers. The results are returned in arbitrary order. The returned channel is closed after all generations have been executed.
+func runFuzzExecWorkers(o *fuzzExecOptions, workers int, generations <-chan fuzzGeneration) <-chan *fuzzExecResult {
+	results := make(chan *fuzzExecResult)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for g := range generations {
+				results <- o.execute(g)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/zimmski/tavor/importer"
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/parser"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+// importGrammar translates the grammar file of the import command into the format file
+// Every construct of the grammar which cannot be represented in the Tavor format is logged as warning.
+func importGrammar(opts *options) exitCodeType {
+	imp, err := importer.New(opts.Import.From)
+	if err != nil {
+		return exitError(err.Error())
+	}
+
+	log.Infof("open file %s", opts.Import.InputFile)
+
+	file, err := os.Open(string(opts.Import.InputFile))
+	if err != nil {
+		return exitError("cannot open grammar file %s: %v", opts.Import.InputFile, err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			panic(err)
+		}
+	}()
+
+	g, err := imp(file)
+	if err != nil {
+		return exitError("cannot parse %s grammar file: %v", opts.Import.From, err)
+	}
+
+	format, err := g.Tavor(opts.Import.Start)
+
+	for _, w := range g.Warnings {
+		log.Warnf("%s", w)
+	}
+
+	if err != nil {
+		return exitError("cannot translate grammar: %v", err)
+	}
+
+	if _, err := parser.ParseTavorWithUniverse(strings.NewReader(format), primitives.CharacterUniverse(opts.Global.CharacterUniverse)); err != nil {
+		return exitError("cannot parse translated format file: %v", err)
+	}
+
+	if !opts.Import.Overwrite {
+		if _, err := os.Stat(string(opts.Format.FormatFile)); err == nil {
+			return exitError("tavor file %s already exists", opts.Format.FormatFile)
+		}
+	}
+
+	if err := ioutil.WriteFile(string(opts.Format.FormatFile), []byte(format), 0644); err != nil {
+		return exitError("cannot write tavor file %s: %v", opts.Format.FormatFile, err)
+	}
+
+	log.Infof("wrote tavor file %s with %d warnings", opts.Format.FormatFile, len(g.Warnings))
+
+	return exitCodeOk
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMainImport(t *testing.T) {
+	folder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	grammar := filepath.Join(folder, "grammar.abnf")
+	format := filepath.Join(folder, "grammar.tavor")
+
+	assert.Nil(t, ioutil.WriteFile(grammar, []byte("answer = %s\"yes\" / %s\"no\" / <maybe>\n"), 0644))
+
+	exitCode, _ := execMain(t, []string{"--format-file", format, "import", "--from", "abnf", "--input-file", grammar})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	data, err := ioutil.ReadFile(format)
+	assert.Nil(t, err)
+	assert.Equal(t, "START = answer\n\nanswer = ?(\"yes\" | \"no\")\n", string(data))
+
+	exitCode, out := execMain(t, []string{"--format-file", format, "fuzz", "--strategy", "AllPermutations", "--result-separator", ";"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, ";yes;no", out)
+
+	// existing format files are only replaced on request
+	exitCode, _ = execMain(t, []string{"--format-file", format, "import", "--from", "abnf", "--input-file", grammar, "--start", "answer"})
+	assert.Equal(t, exitCodeError, exitCode)
+
+	exitCode, _ = execMain(t, []string{"--format-file", format, "import", "--from", "abnf", "--input-file", grammar, "--overwrite"})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	// invalid grammars and start rules
+	exitCode, _ = execMain(t, []string{"--format-file", format, "import", "--from", "antlr", "--input-file", grammar, "--overwrite"})
+	assert.Equal(t, exitCodeError, exitCode)
+
+	exitCode, _ = execMain(t, []string{"--format-file", format, "import", "--from", "abnf", "--input-file", grammar, "--overwrite", "--start", "question"})
+	assert.Equal(t, exitCodeError, exitCode)
+}
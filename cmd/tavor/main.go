@@ -8,9 +8,12 @@ import (
 	"io"
 	"io/ioutil"
 	"math/rand"
+	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -27,6 +30,7 @@ import (
 	"github.com/zimmski/tavor/parser"
 	tavorReduceStrategy "github.com/zimmski/tavor/reduce/strategy"
 	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/primitives"
 )
 
 type exitCodeType int
@@ -50,11 +54,16 @@ type options struct {
 	Global struct {
 		Seed      int64 `long:"seed" description:"Seed for all the randomness"`
 		MaxRepeat int   `long:"max-repeat" description:"How many times loops and repetitions should be repeated" default:"2"`
+
+		CharacterUniverse string `long:"character-universe" description:"All characters which are used to complement character classes like [^a-z] and \\D" choice:"ascii" choice:"printable-ascii" choice:"unicode" default:"printable-ascii"`
+		EncodingErrors    string `long:"encoding-errors" description:"How invalid and unrepresentable characters of character encodings are handled. report logs them and replaces them like replace, raw copies their bytes unchanged" choice:"raw" choice:"replace" choice:"report" default:"report"`
 	} `group:"Global options"`
 
 	Format struct {
 		Check         bool           `long:"check" description:"Checks the syntax of the format file and exits"`
-		FormatFile    flags.Filename `long:"format-file" description:"Input Tavor format file" required:"true"`
+		Count         bool           `long:"count" description:"Prints the permutation count of every token definition of the format file and exits"`
+		FormatFile    flags.Filename `long:"format-file" description:"Input Tavor format file, which is written by the import command" required:"true"`
+		FormatType    string         `long:"format-type" description:"Type of the format file, defaults to jsonschema for files with the extension .json and to tavor otherwise" choice:"tavor" choice:"jsonschema"`
 		Print         bool           `long:"print" description:"Prints the AST of the parsed format file and exits"`
 		PrintInternal bool           `long:"print-internal" description:"Prints the internal AST of the parsed format file and exits"`
 	} `group:"Format file options"`
@@ -71,16 +80,43 @@ type options struct {
 			ExecDoNotRemoveTmpFilesOnError bool             `long:"exec-do-not-remove-tmp-files-on-error" description:"If set, tmp files are not removed on error"`
 			ExecArgumentType               execArgumentType `long:"exec-argument-type" description:"How the generation is given to the binary" default:"stdin"`
 			ListExecArgumentTypes          bool             `long:"list-exec-argument-types" description:"List all available exec argument types"`
+			ExecTimeout                    time.Duration    `long:"exec-timeout" description:"Kill the binary if an execution takes longer and report the generation as hang, 0 means no timeout" default:"0"`
+
+			Connect        string        `long:"connect" description:"Send every generation to this target instead of executing a binary e.g. tcp://host:port, udp://host:port or unix:///path. The response is validated like stdout"`
+			ConnectTimeout time.Duration `long:"connect-timeout" description:"Timeout for connecting, sending and waiting for further response data" default:"1s"`
+			ConnectReuse   bool          `long:"connect-reuse" description:"Reuse one connection for all generations instead of connecting for every generation"`
 
 			Script string `long:"script" description:"Execute this binary which gets fed with the generation and should return feedback"`
 
 			ExitOnError bool `long:"exit-on-error" description:"Exit if an execution fails"`
+
+			Workers int `long:"workers" description:"How many executions of the exec binary are done in parallel" default:"1"`
+
+			Triage          bool   `long:"triage" description:"Bucket failures by their exit code, signal, hang or connect failure and their stderr or response frames. Only one representative per bucket and a triage.json report are saved to the result folder"`
+			TriageFrames    string `long:"triage-frames" description:"Regex which matches the stderr frames, or the response frames of a connect target, of the failure signature. The first submatch is used as frame if present"`
+			TriageMaxFrames int    `long:"triage-max-frames" description:"How many stderr frames are used for the failure signature" default:"5"`
+
+			ReduceFailures bool           `long:"reduce-failures" description:"Reduce every failing generation, or only the first one of every triage bucket, with the same oracle and save the result next to the failure with the extension .reduced"`
+			ReduceStrategy reduceStrategy `long:"reduce-strategy" description:"The reducing strategy for reduce-failures" default:"Linear"`
 		}
 
 		Filter optsFuzzingFilters
 
-		Strategy       fuzzStrategy `long:"strategy" description:"The fuzzing strategy" default:"random"`
+		InputFiles []flags.Filename `long:"input-file" description:"Seed file which gets parsed via the format file and is then mutated. Can be given multiple times"`
+		CorpusDir  flags.Filename   `long:"corpus-dir" description:"Folder of seed files which get parsed via the format file and are then mutated"`
+
+		Strategy       fuzzStrategy `long:"strategy" description:"The fuzzing strategy, defaults to random or Mutation if seeds are given"`
 		ListStrategies bool         `long:"list-strategies" description:"List all available fuzzing strategies"`
+		MaxSteps       int          `long:"max-steps" description:"Stop fuzzing after this many generations, 0 means no limit" default:"0"`
+		TWayStrength   int          `long:"tway-strength" description:"Size of the choice combinations which are all covered by the TWay fuzzing strategy" default:"2"`
+
+		Checkpoint         flags.Filename `long:"checkpoint" description:"Save the position of the fuzzing strategy together with the seed and the format hash to this state file"`
+		CheckpointInterval int            `long:"checkpoint-interval" description:"Save the state file after this many generations" default:"1"`
+		Resume             flags.Filename `long:"resume" description:"Continue fuzzing exactly at the position of this state file which is then updated"`
+
+		OutputEncoding string `long:"output-encoding" description:"Transcode every generation from UTF-8 into this character encoding" choice:"latin1" choice:"shiftjis" choice:"utf16be" choice:"utf16le" choice:"utf8"`
+
+		CoverageReport flags.Filename `long:"coverage-report" description:"Track which alternatives, optional states and repeat counts of every token definition are generated and write the coverage as JSON to this file. A summary of all uncovered choices is printed to stderr"`
 
 		ResultFolder     flags.Filename `long:"result-folder" description:"Save every fuzzing result with the MD5 checksum as filename in this folder"`
 		ResultExtensions string         `long:"result-extension" description:"If result-folder is used this will be the extension of every filename"`
@@ -91,6 +127,13 @@ type options struct {
 		Filter optsFuzzingFilters
 	} `command:"graph" description:"Generate a DOT file out of the internal AST"`
 
+	Import struct {
+		From      string         `long:"from" description:"Notation of the grammar file" choice:"abnf" choice:"antlr" choice:"ebnf" required:"true"`
+		InputFile flags.Filename `long:"input-file" description:"Grammar file which gets translated into the format file" required:"true"`
+		Overwrite bool           `long:"overwrite" description:"Overwrite the format file if it already exists"`
+		Start     string         `long:"start" description:"Rule of the grammar which is used as START token, defaults to the first rule"`
+	} `command:"import" description:"Translate the given grammar into the format file"`
+
 	Reduce struct {
 		Exec struct {
 			Exec                    string           `long:"exec" description:"Execute this binary with possible arguments to test a generation"`
@@ -102,11 +145,18 @@ type options struct {
 			ExecDoNotRemoveTmpFiles bool             `long:"exec-do-not-remove-tmp-files" description:"If set, tmp files are not removed"`
 			ExecArgumentType        execArgumentType `long:"exec-argument-type" description:"How the generation is given to the binary" default:"stdin"`
 			ListExecArgumentTypes   bool             `long:"list-exec-argument-types" description:"List all available exec argument types"`
+			ExecTimeout             time.Duration    `long:"exec-timeout" description:"Kill the binary if an execution takes longer which counts as hang, 0 means no timeout" default:"0"`
+			ExecExactHang           bool             `long:"exec-exact-hang" description:"The hang of the original input has to be present, which needs exec-timeout"`
+
+			Connect        string        `long:"connect" description:"Send every generation to this target instead of executing a binary e.g. tcp://host:port, udp://host:port or unix:///path. The response is validated like stdout"`
+			ConnectTimeout time.Duration `long:"connect-timeout" description:"Timeout for connecting, sending and waiting for further response data" default:"1s"`
+			ConnectReuse   bool          `long:"connect-reuse" description:"Reuse one connection for all generations instead of connecting for every generation"`
 
 			Script string `long:"script" description:"Execute this binary which gets fed with the generation and should return feedback"`
 		}
 
-		InputFile flags.Filename `long:"input-file" description:"Input file which gets parsed, validated and delta-debugged via the format file" required:"true"`
+		InputFile     flags.Filename `long:"input-file" description:"Input file which gets parsed, validated and delta-debugged via the format file" required:"true"`
+		InputEncoding string         `long:"input-encoding" description:"Decode the input file from this character encoding into UTF-8 before it is parsed. Reduced results are written in the same encoding" choice:"latin1" choice:"shiftjis" choice:"utf16be" choice:"utf16le" choice:"utf8"`
 
 		Strategy       reduceStrategy `long:"strategy" description:"The reducing strategy" default:"Linear"`
 		ListStrategies bool           `long:"list-strategies" description:"List all available reducing strategies"`
@@ -114,8 +164,16 @@ type options struct {
 		ResultSeparator string `long:"result-separator" description:"Separates result outputs of each reducing step" default:"\n"`
 	} `command:"reduce" description:"Reduce the given input file"`
 
+	Serve struct {
+		Listen         string        `long:"listen" description:"Address the HTTP server listens on" default:"localhost:8080"`
+		SessionTimeout time.Duration `long:"session-timeout" description:"Stop and remove sessions which were not used for this duration, 0 keeps sessions until they are deleted" default:"10m"`
+
+		Filter optsFuzzingFilters
+	} `command:"serve" description:"Serve fuzzing, validating and reducing of the given format file over a HTTP/JSON API"`
+
 	Validate struct {
-		InputFile flags.Filename `long:"input-file" description:"Input file which gets parsed and validated via the format file" required:"true"`
+		InputFile     flags.Filename `long:"input-file" description:"Input file which gets parsed and validated via the format file" required:"true"`
+		InputEncoding string         `long:"input-encoding" description:"Decode the input file from this character encoding into UTF-8 before it is parsed" choice:"latin1" choice:"shiftjis" choice:"utf16be" choice:"utf16le" choice:"utf8"`
 	} `command:"validate" description:"Validate the given input file"`
 }
 
@@ -168,7 +226,7 @@ type fuzzStrategy string
 func (s *fuzzStrategy) Complete(match string) []flags.Completion {
 	var items []flags.Completion
 
-	for _, name := range tavorFuzzStrategy.List() {
+	for _, name := range fuzzStrategies() {
 		if strings.HasPrefix(name, match) {
 			items = append(items, flags.Completion{
 				Item: name,
@@ -195,6 +253,15 @@ func (s *reduceStrategy) Complete(match string) []flags.Completion {
 	return items
 }
 
+// fuzzStrategies returns the names of all fuzzing strategies and feedback fuzzing strategies
+func fuzzStrategies() []string {
+	names := append(tavorFuzzStrategy.List(), tavorFuzzStrategy.ListFeedback()...)
+
+	sort.Strings(names)
+
+	return names
+}
+
 func checkArguments(args []string, opts *options) (string, exitCodeType) {
 	p := flags.NewNamedParser("tavor", flags.None)
 
@@ -215,14 +282,14 @@ func checkArguments(args []string, opts *options) (string, exitCodeType) {
 		fmt.Printf("Tavor v%s\n", tavor.Version)
 
 		return "", exitCodeHelp
-	} else if opts.Fuzz.Filter.ListFilters || opts.Graph.Filter.ListFilters {
+	} else if opts.Fuzz.Filter.ListFilters || opts.Graph.Filter.ListFilters || opts.Serve.Filter.ListFilters {
 		for _, name := range tavorFuzzFilter.List() {
 			fmt.Println(name)
 		}
 
 		return "", exitCodeHelp
 	} else if opts.Fuzz.ListStrategies {
-		for _, name := range tavorFuzzStrategy.List() {
+		for _, name := range fuzzStrategies() {
 			fmt.Println(name)
 		}
 
@@ -260,6 +327,28 @@ func checkArguments(args []string, opts *options) (string, exitCodeType) {
 		log.LevelWarn()
 	}
 
+	if opts.Fuzz.Resume != "" {
+		state, err := readFuzzCheckpoint(string(opts.Fuzz.Resume))
+		if err != nil {
+			return "", exitError(err.Error())
+		}
+
+		// the campaign is continued with its seed and strategy
+		if opts.Global.Seed == 0 {
+			opts.Global.Seed = state.Seed
+		} else if opts.Global.Seed != state.Seed {
+			return "", exitError("seed %d differs from seed %d of the state file", opts.Global.Seed, state.Seed)
+		}
+		if opts.Fuzz.Strategy == "" {
+			opts.Fuzz.Strategy = fuzzStrategy(state.Strategy)
+		} else if string(opts.Fuzz.Strategy) != state.Strategy {
+			return "", exitError("strategy %q differs from strategy %q of the state file", opts.Fuzz.Strategy, state.Strategy)
+		}
+		if opts.Fuzz.Checkpoint == "" {
+			opts.Fuzz.Checkpoint = opts.Fuzz.Resume
+		}
+	}
+
 	if opts.Global.Seed == 0 {
 		opts.Global.Seed = time.Now().UTC().UnixNano()
 	}
@@ -268,6 +357,68 @@ func checkArguments(args []string, opts *options) (string, exitCodeType) {
 		return "", exitError("max repeats has to be at least 1")
 	}
 
+	if opts.Fuzz.CorpusDir != "" {
+		if err := osutil.DirExists(string(opts.Fuzz.CorpusDir)); err != nil {
+			return "", exitError("corpus-dir invalid: %v", err)
+		}
+	}
+	if opts.Fuzz.Strategy == "" {
+		if len(opts.Fuzz.InputFiles) > 0 || opts.Fuzz.CorpusDir != "" {
+			opts.Fuzz.Strategy = "Mutation"
+		} else {
+			opts.Fuzz.Strategy = "random"
+		}
+	}
+	if opts.Fuzz.MaxSteps < 0 {
+		return "", exitError("max steps has to be at least 0")
+	}
+	if opts.Fuzz.TWayStrength < 1 {
+		return "", exitError("t-way strength has to be at least 1")
+	}
+	if opts.Fuzz.Exec.Workers < 1 {
+		return "", exitError("workers has to be at least 1")
+	}
+	if opts.Fuzz.CheckpointInterval < 1 {
+		return "", exitError("checkpoint interval has to be at least 1")
+	}
+	if opts.Fuzz.Checkpoint != "" {
+		if _, err := tavorFuzzStrategy.NewFeedback(string(opts.Fuzz.Strategy)); err == nil {
+			return "", exitError("feedback fuzzing strategy %q cannot be resumed since it depends on the feedback", opts.Fuzz.Strategy)
+		}
+	}
+	if opts.Fuzz.Exec.ExecTimeout < 0 {
+		return "", exitError("exec timeout has to be at least 0")
+	}
+	if opts.Fuzz.Exec.ReduceFailures {
+		if (opts.Fuzz.Exec.Exec == "" && opts.Fuzz.Exec.Connect == "") || opts.Fuzz.ResultFolder == "" {
+			return "", exitError("reduce-failures needs exec or connect and result-folder")
+		}
+		if _, err := tavorReduceStrategy.New(string(opts.Fuzz.Exec.ReduceStrategy)); err != nil {
+			return "", exitError(err.Error())
+		}
+	}
+	if opts.Fuzz.Exec.Triage {
+		if (opts.Fuzz.Exec.Exec == "" && opts.Fuzz.Exec.Connect == "") || opts.Fuzz.ResultFolder == "" {
+			return "", exitError("triage needs exec or connect and result-folder")
+		}
+		if opts.Fuzz.Exec.TriageMaxFrames < 1 {
+			return "", exitError("triage max frames has to be at least 1")
+		}
+		if opts.Fuzz.Exec.TriageFrames != "" {
+			if _, err := regexp.Compile(opts.Fuzz.Exec.TriageFrames); err != nil {
+				return "", exitError("triage frames invalid: %v", err)
+			}
+		}
+	}
+	if opts.Fuzz.Exec.Connect != "" {
+		if opts.Fuzz.Exec.Exec != "" || opts.Fuzz.Exec.Script != "" {
+			return "", exitError("connect cannot be used together with exec or script")
+		}
+		if opts.Fuzz.Exec.ConnectTimeout <= 0 {
+			return "", exitError("connect timeout has to be positive")
+		}
+	}
+
 	if opts.Fuzz.ResultFolder != "" {
 		if err := osutil.DirExists(string(opts.Fuzz.ResultFolder)); err != nil {
 			return "", exitError("result-folder invalid: %v", err)
@@ -294,8 +445,22 @@ func checkArguments(args []string, opts *options) (string, exitCodeType) {
 			return "", exitError(fmt.Sprintf("%q is an unknown exec argument type", opts.Reduce.Exec.ExecArgumentType))
 		}
 	}
-	if opts.Reduce.Exec.Exec != "" {
-		if !opts.Reduce.Exec.ExecExactExitCode && !opts.Reduce.Exec.ExecExactStderr && !opts.Reduce.Exec.ExecExactStdout && opts.Reduce.Exec.ExecMatchStderr == "" && opts.Reduce.Exec.ExecMatchStdout == "" {
+	if opts.Reduce.Exec.Connect != "" {
+		if opts.Reduce.Exec.Exec != "" || opts.Reduce.Exec.Script != "" {
+			return "", exitError("connect cannot be used together with exec or script")
+		}
+		if opts.Reduce.Exec.ConnectTimeout <= 0 {
+			return "", exitError("connect timeout has to be positive")
+		}
+	}
+	if opts.Reduce.Exec.ExecTimeout < 0 {
+		return "", exitError("exec timeout has to be at least 0")
+	}
+	if opts.Reduce.Exec.ExecExactHang && (opts.Reduce.Exec.ExecTimeout == 0 || opts.Reduce.Exec.Exec == "") {
+		return "", exitError("exec-exact-hang needs exec and exec-timeout")
+	}
+	if opts.Reduce.Exec.Exec != "" || opts.Reduce.Exec.Connect != "" {
+		if !opts.Reduce.Exec.ExecExactExitCode && !opts.Reduce.Exec.ExecExactStderr && !opts.Reduce.Exec.ExecExactStdout && !opts.Reduce.Exec.ExecExactHang && opts.Reduce.Exec.ExecMatchStderr == "" && opts.Reduce.Exec.ExecMatchStdout == "" {
 			return "", exitError("At least one exec-exact or exec-match argument has to be given")
 		}
 	}
@@ -304,9 +469,14 @@ func checkArguments(args []string, opts *options) (string, exitCodeType) {
 			opts.Reduce.ResultSeparator = t
 		}
 	}
+	if opts.Serve.SessionTimeout < 0 {
+		return "", exitError("session timeout has to be at least 0")
+	}
 
 	log.Infof("using seed %d", opts.Global.Seed)
 	log.Infof("using max repeat %d", opts.Global.MaxRepeat)
+	log.Infof("using character universe %s", opts.Global.CharacterUniverse)
+	log.Infof("using encoding errors mode %s", opts.Global.EncodingErrors)
 
 	var cmd string
 	if p.Active != nil {
@@ -322,6 +492,28 @@ func exitError(format string, args ...interface{}) exitCodeType {
 	return exitCodeError
 }
 
+// formatType returns the type of the format file which is either given explicitly or detected by the extension of the format file
+func formatType(opts *options) string {
+	if opts.Format.FormatType != "" {
+		return opts.Format.FormatType
+	}
+
+	if strings.EqualFold(filepath.Ext(string(opts.Format.FormatFile)), ".json") {
+		return "jsonschema"
+	}
+
+	return "tavor"
+}
+
+// parseFormat parses the format file according to its type into a new token graph
+func parseFormat(opts *options, src io.Reader) (token.Token, error) {
+	if formatType(opts) == "jsonschema" {
+		return parser.ParseJSONSchema(src)
+	}
+
+	return parser.ParseTavorWithUniverse(src, primitives.CharacterUniverse(opts.Global.CharacterUniverse))
+}
+
 func applyFilters(opts *options, filterNames []fuzzFilter, doc token.Token) (token.Token, error) {
 	if len(filterNames) > 0 {
 		var err error
@@ -359,6 +551,274 @@ func applyFilters(opts *options, filterNames []fuzzFilter, doc token.Token) (tok
 	return doc, nil
 }
 
+// fuzzFlow controls the iteration flow of a fuzzing strategy
+type fuzzFlow struct {
+	ch       chan struct{}
+	feedback chan<- tavorFuzzStrategy.FeedbackScore
+
+	// state returns the position of a resumable strategy
+	state func() ([]byte, error)
+
+	maxSteps int
+	steps    int
+
+	// waiting is true while the strategy waits for the continuation of the current generation
+	waiting bool
+	// ended is true if the strategy has no further generations
+	ended bool
+}
+
+// newFuzzFlow starts the fuzzing strategy of the options on the token graph. A resumable strategy continues after the state of the given checkpoint.
+func newFuzzFlow(opts *options, doc token.Token, r *rand.Rand, resume *fuzzCheckpoint) (*fuzzFlow, error) {
+	seeds, err := fuzzSeeds(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(seeds) == 0 {
+		if strat, err := tavorFuzzStrategy.NewResumable(string(opts.Fuzz.Strategy)); err == nil {
+			f := &fuzzFlow{
+				maxSteps: opts.Fuzz.MaxSteps,
+			}
+
+			var state []byte
+			if resume != nil && resume.State != nil {
+				state = resume.State
+				f.steps = resume.Steps
+			}
+
+			f.ch, f.state, err = strat(doc, r, state)
+			if err != nil {
+				return nil, err
+			}
+
+			return f, nil
+		}
+
+		return newStrategyFuzzFlow(string(opts.Fuzz.Strategy), opts.Fuzz.TWayStrength, opts.Fuzz.MaxSteps, doc, r)
+	}
+
+	if _, err := tavorFuzzStrategy.NewFeedback(string(opts.Fuzz.Strategy)); err == nil {
+		return nil, fmt.Errorf("feedback fuzzing strategy %q cannot be used with seeds", opts.Fuzz.Strategy)
+	}
+
+	strat, err := newFuzzStrategy(string(opts.Fuzz.Strategy), opts.Fuzz.TWayStrength)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &fuzzFlow{
+		maxSteps: opts.Fuzz.MaxSteps,
+	}
+
+	// the strategy is applied to every seed one after another using the same iteration flow
+	f.ch = make(chan struct{})
+
+	go func() {
+		for _, seed := range seeds {
+			if !parseFuzzSeed(doc, seed) {
+				continue
+			}
+
+			ch, err := strat(doc, r)
+			if err != nil {
+				log.Errorf("cannot fuzz seed %s: %v", seed, err)
+
+				continue
+			}
+
+			for i := range ch {
+				f.ch <- i
+
+				if _, ok := <-f.ch; !ok {
+					close(ch)
+
+					return
+				}
+
+				ch <- i
+			}
+		}
+
+		close(f.ch)
+	}()
+
+	return f, nil
+}
+
+// newFuzzStrategy returns the fuzzing strategy with the given name. The TWay strategy is created with the given strength.
+func newFuzzStrategy(name string, tWayStrength int) (tavorFuzzStrategy.Strategy, error) {
+	if name == "TWay" {
+		return tavorFuzzStrategy.NewTWay(tWayStrength), nil
+	}
+
+	return tavorFuzzStrategy.New(name)
+}
+
+// newStrategyFuzzFlow starts the fuzzing strategy or feedback fuzzing strategy with the given name on the token graph
+func newStrategyFuzzFlow(name string, tWayStrength int, maxSteps int, doc token.Token, r *rand.Rand) (*fuzzFlow, error) {
+	f := &fuzzFlow{
+		maxSteps: maxSteps,
+	}
+
+	if strat, err := tavorFuzzStrategy.NewFeedback(name); err == nil {
+		f.ch, f.feedback, err = strat(doc, r)
+		if err != nil {
+			return nil, err
+		}
+
+		return f, nil
+	}
+
+	strat, err := newFuzzStrategy(name, tWayStrength)
+	if err != nil {
+		return nil, err
+	}
+
+	f.ch, err = strat(doc, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// fuzzSeeds returns the seed files of the input file and corpus dir options
+func fuzzSeeds(opts *options) ([]string, error) {
+	var seeds []string
+
+	for _, file := range opts.Fuzz.InputFiles {
+		seeds = append(seeds, string(file))
+	}
+
+	if opts.Fuzz.CorpusDir != "" {
+		files, err := ioutil.ReadDir(string(opts.Fuzz.CorpusDir))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read corpus dir: %v", err)
+		}
+
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+
+			seeds = append(seeds, filepath.Join(string(opts.Fuzz.CorpusDir), file.Name()))
+		}
+	}
+
+	return seeds, nil
+}
+
+// parseFuzzSeed parses the given seed file into the token graph. Invalid seeds are logged and false is returned.
+func parseFuzzSeed(doc token.Token, seed string) bool {
+	log.Infof("parse seed %s", seed)
+
+	input, err := os.Open(seed)
+	if err != nil {
+		log.Errorf("cannot open seed %s: %v", seed, err)
+
+		return false
+	}
+	defer func() {
+		if err := input.Close(); err != nil {
+			panic(err)
+		}
+	}()
+
+	if errs := parser.ParseInternal(doc, input); len(errs) > 0 {
+		log.Errorf("seed %s is invalid", seed)
+
+		for _, err := range errs {
+			log.Error(err)
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// wait waits for the next generation of the strategy. False is returned if the strategy has no further generations.
+func (f *fuzzFlow) wait() bool {
+	if f.ended {
+		return false
+	}
+
+	if _, ok := <-f.ch; !ok {
+		f.ended = true
+
+		return false
+	}
+
+	f.waiting = true
+
+	return true
+}
+
+// position returns the position of a resumable strategy after the current generation and nil for other strategies
+func (f *fuzzFlow) position() ([]byte, error) {
+	if f.state == nil {
+		return nil, nil
+	}
+
+	return f.state()
+}
+
+// next hands the score of the current generation to a feedback strategy and continues the strategy. False is returned if no further generation should be fuzzed.
+func (f *fuzzFlow) next(score tavorFuzzStrategy.FeedbackScore) bool {
+	f.steps++
+
+	if f.maxSteps > 0 && f.steps >= f.maxSteps {
+		log.Infof("reached maximum of %d fuzzing steps", f.maxSteps)
+
+		f.stop()
+
+		return false
+	}
+
+	f.waiting = false
+
+	if f.feedback != nil {
+		f.feedback <- score
+	}
+
+	f.ch <- struct{}{}
+
+	return true
+}
+
+// skip continues the strategy without handing out generations until the given count of generations is reached. False is returned if the strategy ended before.
+func (f *fuzzFlow) skip(steps int) bool {
+	for f.steps < steps {
+		if !f.wait() || !f.next(0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// stop ends the strategy and waits until it has ended. Stopping an already ended strategy does nothing.
+func (f *fuzzFlow) stop() {
+	// the strategy can only be stopped while it waits for the continuation of a generation
+	if !f.waiting && !f.wait() {
+		return
+	}
+
+	f.waiting = false
+	f.ended = true
+
+	if f.feedback != nil {
+		// feedback strategies close the iteration channel on their own
+		close(f.feedback)
+
+		for range f.ch {
+		}
+	} else {
+		close(f.ch)
+	}
+}
+
 func mainCmd(args []string) exitCodeType {
 	var opts = new(options)
 
@@ -369,6 +829,10 @@ func mainCmd(args []string) exitCodeType {
 
 	tavor.MaxRepeat = opts.Global.MaxRepeat
 
+	if command == "import" {
+		return importGrammar(opts)
+	}
+
 	log.Infof("open file %s", opts.Format.FormatFile)
 
 	file, err := os.Open(string(opts.Format.FormatFile))
@@ -381,13 +845,32 @@ func mainCmd(args []string) exitCodeType {
 		}
 	}()
 
-	doc, err := parser.ParseTavor(file)
+	var doc token.Token
+	var definitions []parser.Definition
+
+	if opts.Format.Count || (command == "fuzz" && opts.Fuzz.CoverageReport != "") {
+		if formatType(opts) != "tavor" {
+			return exitError("counting permutations and coverage reports need token definitions which are only available for Tavor format files")
+		}
+
+		doc, definitions, err = parser.ParseTavorDefinitionsWithUniverse(file, primitives.CharacterUniverse(opts.Global.CharacterUniverse))
+	} else {
+		doc, err = parseFormat(opts, file)
+	}
 	if err != nil {
-		return exitError("cannot parse tavor file: %v", err)
+		return exitError("cannot parse %s file: %v", formatType(opts), err)
 	}
 
 	log.Info("format file is valid")
 
+	if opts.Format.Count {
+		for _, d := range definitions {
+			fmt.Printf("%s\t%s\n", d.Name, token.FormatPermutations(d.Token.PermutationsAll()))
+		}
+
+		return exitCodeOk
+	}
+
 	if opts.Format.PrintInternal {
 		log.Info("Internal AST:")
 
@@ -416,243 +899,426 @@ func mainCmd(args []string) exitCodeType {
 
 	switch command {
 	case "fuzz":
-		doc, err = applyFilters(opts, opts.Fuzz.Filter.Filters, doc)
+		doc, err = encodeDocument(doc, opts.Fuzz.OutputEncoding, opts.Global.EncodingErrors)
 		if err != nil {
-			return exitError("cannot apply filters: %v", err)
+			return exitError("cannot encode generations: %v", err)
 		}
 
-		log.Infof("counted %d overall permutations", doc.PermutationsAll())
-
-		strat, err := tavorFuzzStrategy.New(string(opts.Fuzz.Strategy))
+		doc, err = applyFilters(opts, opts.Fuzz.Filter.Filters, doc)
 		if err != nil {
-			return exitError(err.Error())
+			return exitError("cannot apply filters: %v", err)
 		}
 
-		log.Infof("using %s fuzzing strategy", opts.Fuzz.Strategy)
+		log.Infof("counted %s overall permutations", token.FormatPermutations(doc.PermutationsAll()))
 
 		folder := opts.Fuzz.ResultFolder
 		if len(folder) > 0 && folder[len(folder)-1] != '/' {
 			folder += "/"
 		}
 
-		ch, err := strat(doc, r)
-		if err != nil {
-			return exitError(err.Error())
-		}
+		var checkpoint *fuzzCheckpoint
+		if opts.Fuzz.Checkpoint != "" {
+			format, err := ioutil.ReadFile(string(opts.Format.FormatFile))
+			if err != nil {
+				return exitError("cannot read tavor file %s: %v", opts.Format.FormatFile, err)
+			}
 
-		if opts.Fuzz.Exec.Exec != "" {
-			execs := strings.Split(opts.Fuzz.Exec.Exec, " ")
-			var execFileArguments []int
-			for i, v := range execs {
-				if v == "TAVOR_FUZZ_FILE" {
-					execFileArguments = append(execFileArguments, i)
-				}
+			hash, err := fuzzCampaignHash(opts, format)
+			if err != nil {
+				return exitError(err.Error())
 			}
 
-			var matchStderr *regexp.Regexp
-			var matchStdout *regexp.Regexp
+			checkpoint = &fuzzCheckpoint{
+				Hash:     hash,
+				Strategy: string(opts.Fuzz.Strategy),
+				Seed:     opts.Global.Seed,
 
-			if opts.Fuzz.Exec.ExecMatchStderr != "" {
-				matchStderr = regexp.MustCompile(opts.Fuzz.Exec.ExecMatchStderr)
-			}
-			if opts.Fuzz.Exec.ExecMatchStdout != "" {
-				matchStdout = regexp.MustCompile(opts.Fuzz.Exec.ExecMatchStdout)
+				file:     string(opts.Fuzz.Checkpoint),
+				interval: opts.Fuzz.CheckpointInterval,
 			}
+		}
 
-			stepID := 1
+		var cov *coverage
+		if opts.Fuzz.CoverageReport != "" {
+			cov = newCoverage(definitions)
+		}
 
-			writeTmpFile := func(docOut string) (*os.File, error) {
-				tmp, err := ioutil.TempFile(string(folder), fmt.Sprintf("fuzz-%d-", stepID))
-				if err != nil {
-					return nil, fmt.Errorf("Cannot create tmp file: %v", err)
-				}
-				_, err = tmp.WriteString(docOut)
-				if err != nil {
-					return nil, fmt.Errorf("Cannot write to tmp file: %v", err)
-				}
+		reportEncodingErrors := hasEncodedTokens(doc)
 
-				return tmp, nil
+		var resume *fuzzCheckpoint
+		if opts.Fuzz.Resume != "" {
+			resume, err = readFuzzCheckpoint(string(opts.Fuzz.Resume))
+			if err != nil {
+				return exitError(err.Error())
 			}
 
-		GENERATION:
-			for i := range ch {
-				docOut := doc.String()
+			if resume.Hash != checkpoint.Hash {
+				return exitError("format file, max repeat, filters or seed files differ from the state file")
+			}
+			if resume.Finished || (opts.Fuzz.MaxSteps > 0 && resume.Steps >= opts.Fuzz.MaxSteps) {
+				log.Infof("fuzzing campaign of %s is already finished", opts.Fuzz.Resume)
 
-				log.Infof("Test %d", stepID)
+				return exitCodeOk
+			}
+		}
 
-				var tmp *os.File
+		flow, err := newFuzzFlow(opts, doc, r, resume)
+		if err != nil {
+			return exitError(err.Error())
+		}
+		defer flow.stop()
 
-				var cmdExitCode int
-				var cmdStderr bytes.Buffer
-				var cmdStdout bytes.Buffer
+		log.Infof("using %s fuzzing strategy", opts.Fuzz.Strategy)
 
-				if string(opts.Fuzz.Exec.ExecArgumentType) == "argument" {
-					tmp, err = writeTmpFile(docOut)
-					if err != nil {
-						return exitError(err.Error())
-					}
+		if resume != nil {
+			log.Infof("resume after %d generations", resume.Steps)
 
-					for _, v := range execFileArguments {
-						execs[v] = tmp.Name()
-					}
-				}
+			// strategies without a saved state are resumed by regenerating their finished generations
+			if !flow.skip(resume.Steps) {
+				return exitError("fuzzing strategy ended before the position of the state file")
+			}
 
-				execCommand := exec.Command(execs[0], execs[1:]...)
+			checkpoint.Steps = resume.Steps
+		}
 
-				if string(opts.Fuzz.Exec.ExecArgumentType) == "environment" {
-					tmp, err = writeTmpFile(docOut)
-					if err != nil {
-						return exitError(err.Error())
-					}
+		if opts.Fuzz.Exec.Exec != "" || opts.Fuzz.Exec.Connect != "" {
+			execOpts := &fuzzExecOptions{
+				argumentType:  string(opts.Fuzz.Exec.ExecArgumentType),
+				folder:        string(folder),
+				exactExitCode: opts.Fuzz.Exec.ExecExactExitCode,
+				exactStderr:   opts.Fuzz.Exec.ExecExactStderr,
+				exactStdout:   opts.Fuzz.Exec.ExecExactStdout,
+				timeout:       opts.Fuzz.Exec.ExecTimeout,
+			}
+			if opts.Fuzz.Exec.Connect != "" {
+				execOpts.connect, err = newConnectTarget(opts.Fuzz.Exec.Connect, opts.Fuzz.Exec.ConnectTimeout, opts.Fuzz.Exec.ConnectReuse)
+				if err != nil {
+					return exitError(err.Error())
+				}
+				defer execOpts.connect.close()
 
-					execCommand.Env = []string{fmt.Sprintf("TAVOR_FUZZ_FILE=%s", tmp.Name())}
+				log.Infof("send generations to %s", execOpts.connect)
+			} else {
+				execOpts.execs = strings.Split(opts.Fuzz.Exec.Exec, " ")
+			}
+			for i, v := range execOpts.execs {
+				if v == "TAVOR_FUZZ_FILE" {
+					execOpts.execFileArguments = append(execOpts.execFileArguments, i)
 				}
+			}
 
-				if opts.General.Verbose || opts.General.Debug {
-					execCommand.Stderr = io.MultiWriter(&cmdStderr, os.Stderr)
-					execCommand.Stdout = io.MultiWriter(&cmdStdout, os.Stdout)
-				} else {
-					execCommand.Stderr = &cmdStderr
-					execCommand.Stdout = &cmdStdout
+			if opts.Fuzz.Exec.ExecMatchStderr != "" {
+				execOpts.matchStderr = regexp.MustCompile(opts.Fuzz.Exec.ExecMatchStderr)
+			}
+			if opts.Fuzz.Exec.ExecMatchStdout != "" {
+				execOpts.matchStdout = regexp.MustCompile(opts.Fuzz.Exec.ExecMatchStdout)
+			}
+
+			log.Infof("using %d workers", opts.Fuzz.Exec.Workers)
+
+			var tri *triage
+			if opts.Fuzz.Exec.Triage {
+				var frames *regexp.Regexp
+				if opts.Fuzz.Exec.TriageFrames != "" {
+					frames = regexp.MustCompile(opts.Fuzz.Exec.TriageFrames)
 				}
 
-				stdin, err := execCommand.StdinPipe()
+				tri = newTriage(string(folder), opts.Fuzz.ResultExtensions, frames, opts.Fuzz.Exec.TriageMaxFrames)
+			}
+
+			// the outcome of an execution without triage is its exit code, signal or hang
+			signature := newTriage("", "", nil, 1).signature
+			if tri != nil {
+				signature = tri.signature
+			}
+
+			// reduceFailure reduces the failing generation and saves the reduced generation next to the saved failure
+			var reduceFailure func(res *fuzzExecResult, file string) error
+			if opts.Fuzz.Exec.ReduceFailures {
+				strat, err := tavorReduceStrategy.New(string(opts.Fuzz.Exec.ReduceStrategy))
 				if err != nil {
-					return exitError("Could not get stdin pipe: %s", err)
+					return exitError(err.Error())
 				}
 
-				if tmp != nil {
-					log.Infof("Test %q", tmp.Name())
-				}
+				log.Infof("using %s reducing strategy for failures", opts.Fuzz.Exec.ReduceStrategy)
 
-				err = execCommand.Start()
+				format, err := ioutil.ReadFile(string(opts.Format.FormatFile))
 				if err != nil {
-					return exitError("Could not start exce: %s", err)
+					return exitError("cannot read tavor file %s: %v", opts.Format.FormatFile, err)
 				}
 
-				if string(opts.Fuzz.Exec.ExecArgumentType) == "stdin" {
-					_, err := stdin.Write([]byte(docOut))
+				execOpts.parse = func() (token.Token, error) {
+					doc, err := parseFormat(opts, bytes.NewReader(format))
 					if err != nil {
-						return exitError("Could not write stdin to exec: %s", err)
+						return nil, err
 					}
 
-					if err := stdin.Close(); err != nil {
-						panic(err)
+					doc, err = encodeDocument(doc, opts.Fuzz.OutputEncoding, opts.Global.EncodingErrors)
+					if err != nil {
+						return nil, err
 					}
+
+					return applyFilters(opts, opts.Fuzz.Filter.Filters, doc)
 				}
 
-				err = execCommand.Wait()
+				reduceFailure = func(res *fuzzExecResult, file string) error {
+					log.Infof("Reduce failure of step %d", res.generation.stepID)
 
-				if err == nil {
-					cmdExitCode = 0
-				} else if e, ok := err.(*exec.ExitError); ok {
-					cmdExitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
-				} else {
-					return exitError("Could not execute exec successfully: %s", err)
+					reduced, err := execOpts.reduce(res, strat, signature)
+					if err == errNotReducible {
+						log.Warnf("Cannot reduce failure of step %d: %s", res.generation.stepID, err)
+
+						return nil
+					} else if err != nil {
+						return err
+					}
+
+					if err := ioutil.WriteFile(file+".reduced", []byte(reduced), 0644); err != nil {
+						return fmt.Errorf("Cannot write reduced failure: %v", err)
+					}
+
+					log.Infof("Reduced failure from %d to %d bytes and written to %q", len(res.generation.rendered.Data), len(reduced), file+".reduced")
+
+					return nil
 				}
+			}
 
-				log.Infof("Exit status was %d", cmdExitCode)
+			generations := make(chan fuzzGeneration)
+			stop := make(chan struct{})
+			firstStepID := flow.steps + 1
+			exhausted := false
 
-				oks := 0
-				oksNeeded := 0
+			// feedback strategies need the score of a generation before the next generation can be fuzzed
+			var scores chan tavorFuzzStrategy.FeedbackScore
+			if flow.feedback != nil {
+				scores = make(chan tavorFuzzStrategy.FeedbackScore, 1)
+			}
 
-				gotError := false
+			go func() {
+				defer close(generations)
+				// the strategy has to end before the fuzzing ends
+				defer flow.stop()
 
-				if opts.Fuzz.Exec.ExecExactExitCode != -1 {
-					oksNeeded++
+				stepID := firstStepID
 
-					if opts.Fuzz.Exec.ExecExactExitCode == cmdExitCode {
-						log.Infof("Same exit code")
+				for flow.wait() {
+					cov.add(doc)
+					if reportEncodingErrors {
+						logEncodingErrors(doc)
+					}
 
-						oks++
-					} else {
-						log.Infof("Not the same exit code")
+					g := fuzzGeneration{
+						stepID:   stepID,
+						rendered: tavorFuzzStrategy.NewGeneration(doc),
 					}
-				}
-				if opts.Fuzz.Exec.ExecExactStderr != "" {
-					oksNeeded++
+					if checkpoint != nil {
+						state, err := flow.position()
+						if err != nil {
+							log.Errorf("cannot save the state of the fuzzing strategy: %v", err)
+						}
 
-					if opts.Fuzz.Exec.ExecExactStderr == cmdStderr.String() {
-						log.Infof("Same stderr")
+						g.state = state
+					}
 
-						oks++
-					} else {
-						log.Infof("Not the same stderr")
+					select {
+					case generations <- g:
+					case <-stop:
+						return
 					}
-				}
-				if opts.Fuzz.Exec.ExecExactStdout != "" {
-					oksNeeded++
 
-					if opts.Fuzz.Exec.ExecExactStdout == cmdStdout.String() {
-						log.Infof("Same stdout")
+					select {
+					case <-stop:
+						return
+					default:
+					}
 
-						oks++
-					} else {
-						log.Infof("Not the same stdout")
+					var score tavorFuzzStrategy.FeedbackScore
+					if scores != nil {
+						select {
+						case score = <-scores:
+						case <-stop:
+							return
+						}
+					}
+
+					if !flow.next(score) {
+						return
 					}
+
+					stepID++
 				}
-				if matchStderr != nil {
-					oksNeeded++
 
-					if matchStderr.Match(cmdStderr.Bytes()) {
-						log.Infof("Same stderr matching")
+				exhausted = true
+			}()
 
-						oks++
-					} else {
-						log.Infof("Not the same stderr matching")
-					}
+			results := runFuzzExecWorkers(execOpts, opts.Fuzz.Exec.Workers, generations)
+
+			removeTmpFile := func(tmp *os.File) {
+				if tmp == nil {
+					return
 				}
-				if matchStdout != nil {
-					oksNeeded++
 
-					if matchStdout.Match(cmdStdout.Bytes()) {
-						log.Infof("Same stdout matching")
+				if err := os.Remove(tmp.Name()); err != nil {
+					log.Errorf("Could not remove tmp file %q: %s", tmp.Name(), err)
+				}
+			}
 
-						oks++
-					} else {
-						log.Infof("Not the same stdout matching")
+			// results are reported in the order of the fuzzing steps
+			report := func(res *fuzzExecResult) (bool, error) {
+				log.Infof("Test %d", res.generation.stepID)
+
+				if res.tmp != nil {
+					log.Infof("Test %q", res.tmp.Name())
+				}
+
+				if res.err != nil {
+					return false, res.err
+				}
+
+				if opts.General.Verbose || opts.General.Debug {
+					if _, err := os.Stderr.Write(res.stderr.Bytes()); err != nil {
+						return false, err
+					}
+					if _, err := os.Stdout.Write(res.stdout.Bytes()); err != nil {
+						return false, err
 					}
 				}
 
-				if oksNeeded == 0 {
-					log.Warnf("Not defined what to compare")
-				} else {
-					if oks == oksNeeded {
-						log.Infof("Same output")
-					} else {
-						log.Infof("Not the same output")
+				gotError := !execOpts.validate(res)
+
+				if gotError && tri != nil {
+					// the generation is saved by the triage if it is the first one of its bucket
+					if !opts.Fuzz.Exec.ExecDoNotRemoveTmpFiles {
+						removeTmpFile(res.tmp)
+					}
 
-						gotError = true
+					b, isNew, err := tri.add(res)
+					if err != nil {
+						return false, err
+					}
 
-						if opts.Fuzz.Exec.ExecDoNotRemoveTmpFilesOnError || string(folder) != "" {
-							if tmp == nil {
-								tmp, err = writeTmpFile(docOut)
-								if err != nil {
-									return exitError(err.Error())
-								}
-							}
+					if isNew && reduceFailure != nil {
+						if err := reduceFailure(res, filepath.Join(string(folder), b.File)); err != nil {
+							return false, err
+						}
+					}
+
+					return gotError, nil
+				}
 
-							log.Infof("Written to %q", tmp.Name())
+				if gotError && (opts.Fuzz.Exec.ExecDoNotRemoveTmpFilesOnError || string(folder) != "") {
+					if res.hang {
+						// hangs are saved separately to distinguish them from other failures
+						removeTmpFile(res.tmp)
+						res.tmp = nil
+
+						res.tmp, err = execOpts.writeTmpFile("hang", res.generation.stepID, res.generation.rendered.Data)
+						if err != nil {
+							return false, err
 						}
+					} else if res.tmp == nil {
+						res.tmp, err = execOpts.writeTmpFile("fuzz", res.generation.stepID, res.generation.rendered.Data)
+						if err != nil {
+							return false, err
+						}
+					}
+
+					log.Infof("Written to %q", res.tmp.Name())
 
-						if opts.Fuzz.Exec.ExitOnError {
-							break GENERATION
+					if reduceFailure != nil {
+						if err := reduceFailure(res, res.tmp.Name()); err != nil {
+							return false, err
 						}
 					}
 				}
 
 				if !opts.Fuzz.Exec.ExecDoNotRemoveTmpFiles && (!gotError || (!opts.Fuzz.Exec.ExecDoNotRemoveTmpFilesOnError && string(folder) == "")) {
-					if tmp != nil {
-						err = os.Remove(tmp.Name())
-						if err != nil {
-							log.Errorf("Could not remove tmp file %q: %s", tmp.Name(), err)
+					removeTmpFile(res.tmp)
+				}
+
+				return gotError, nil
+			}
+
+			pending := make(map[int]*fuzzExecResult)
+			nextStepID := firstStepID
+			stopped := false
+			var execErr error
+
+			// a generation scores if its outcome was not seen before, e.g. a new exit code or a new failure signature
+			outcomes := make(map[string]bool)
+
+			for res := range results {
+				if stopped {
+					// executions which were already running when the fuzzing stopped are not reported
+					if !opts.Fuzz.Exec.ExecDoNotRemoveTmpFiles {
+						removeTmpFile(res.tmp)
+					}
+
+					continue
+				}
+
+				pending[res.generation.stepID] = res
+
+				for !stopped {
+					res, ok := pending[nextStepID]
+					if !ok {
+						break
+					}
+
+					delete(pending, nextStepID)
+					nextStepID++
+
+					gotError, err := report(res)
+					if err == nil {
+						err = checkpoint.done(res.generation.stepID, res.generation.state)
+					}
+					if err == nil && scores != nil {
+						outcome := fmt.Sprintf("%t %s", gotError, signature(res))
+
+						var score tavorFuzzStrategy.FeedbackScore
+						if !outcomes[outcome] {
+							outcomes[outcome] = true
+							score = 1
+						}
+
+						scores <- score
+					}
+					if err != nil {
+						execErr = err
+					}
+
+					if err != nil || (gotError && opts.Fuzz.Exec.ExitOnError) {
+						stopped = true
+
+						close(stop)
+
+						for _, res := range pending {
+							if !opts.Fuzz.Exec.ExecDoNotRemoveTmpFiles {
+								removeTmpFile(res.tmp)
+							}
 						}
 					}
 				}
+			}
 
-				ch <- i
+			if tri != nil {
+				buckets, err := tri.report()
+				if err != nil {
+					return exitError(err.Error())
+				}
 
-				stepID++
+				log.Infof("Found %d failure buckets", len(buckets))
+				for _, b := range buckets {
+					log.Infof("Bucket %s with %d failures saved as %q: %q", b.ID, b.Count, b.File, b.Signature)
+				}
+			}
+
+			if execErr != nil {
+				return exitError(execErr.Error())
+			}
+
+			if err := checkpoint.finish(exhausted && !stopped); err != nil {
+				return exitError(err.Error())
 			}
 		} else if opts.Fuzz.Exec.Script != "" {
 			execs := strings.Split(opts.Fuzz.Exec.Script, " ")
@@ -690,8 +1356,15 @@ func mainCmd(args []string) exitCodeType {
 				return exitError("Could not start script: %s", err)
 			}
 
+			stopped := false
+
 		GENERATIONSC:
-			for i := range ch {
+			for flow.wait() {
+				cov.add(doc)
+				if reportEncodingErrors {
+					logEncodingErrors(doc)
+				}
+
 				_, err = stdin.Write([]byte("Generation\n"))
 				if err != nil {
 					return exitError("Could not write stdin to script: %s", err)
@@ -710,20 +1383,59 @@ func mainCmd(args []string) exitCodeType {
 					return exitError("Could not read stdout from script: %s", err)
 				}
 
-				switch feed {
-				case "YES\n":
+				// the feedback can be followed by the score of the generation e.g. "YES 12"
+				feeds := strings.Fields(feed)
+				if len(feeds) == 0 || len(feeds) > 2 || !strings.HasSuffix(feed, "\n") {
+					return exitError("Feedback from script was not YES nor NO: %s", feed)
+				}
+
+				var score tavorFuzzStrategy.FeedbackScore
+				if len(feeds) == 2 {
+					v, err := strconv.Atoi(feeds[1])
+					if err != nil {
+						return exitError("Feedback score from script is not an integer: %s", feed)
+					}
+
+					score = tavorFuzzStrategy.FeedbackScore(v)
+				}
+
+				if feeds[0] != "YES" && feeds[0] != "NO" {
+					return exitError("Feedback from script was not YES nor NO: %s", feed)
+				}
+
+				if checkpoint != nil {
+					state, err := flow.position()
+					if err != nil {
+						return exitError("cannot save the state of the fuzzing strategy: %v", err)
+					}
+
+					if err := checkpoint.done(flow.steps+1, state); err != nil {
+						return exitError(err.Error())
+					}
+				}
+
+				switch feeds[0] {
+				case "YES":
 					log.Infof("Same output")
-				case "NO\n":
+				case "NO":
 					log.Infof("Not the same output")
 
 					if opts.Fuzz.Exec.ExitOnError {
+						stopped = true
+
 						break GENERATIONSC
 					}
-				default:
-					return exitError("Feedback from script was not YES nor NO: %s", feed)
 				}
 
-				ch <- i
+				if !flow.next(score) {
+					stopped = true
+
+					break
+				}
+			}
+
+			if err := checkpoint.finish(!stopped); err != nil {
+				return exitError(err.Error())
 			}
 
 			_, err = stdin.Write([]byte("Exit\n"))
@@ -753,8 +1465,14 @@ func mainCmd(args []string) exitCodeType {
 			log.Infof("Exit status was %d", execExitCode)
 		} else {
 			another := false
+			stopped := false
+
+			for flow.wait() {
+				cov.add(doc)
+				if reportEncodingErrors {
+					logEncodingErrors(doc)
+				}
 
-			for i := range ch {
 				if folder == "" {
 					log.Debug("result:")
 
@@ -781,9 +1499,32 @@ func mainCmd(args []string) exitCodeType {
 					}
 				}
 
-				ch <- i
+				if checkpoint != nil {
+					state, err := flow.position()
+					if err != nil {
+						return exitError("cannot save the state of the fuzzing strategy: %v", err)
+					}
+
+					if err := checkpoint.done(flow.steps+1, state); err != nil {
+						return exitError(err.Error())
+					}
+				}
+
+				if !flow.next(0) {
+					stopped = true
+
+					break
+				}
+			}
+
+			if err := checkpoint.finish(!stopped); err != nil {
+				return exitError(err.Error())
 			}
 		}
+
+		if err := cov.report(string(opts.Fuzz.CoverageReport), os.Stderr); err != nil {
+			return exitError(err.Error())
+		}
 	case "graph":
 		doc, err = applyFilters(opts, opts.Graph.Filter.Filters, doc)
 		if err != nil {
@@ -791,11 +1532,32 @@ func mainCmd(args []string) exitCodeType {
 		}
 
 		graph.WriteDot(doc, os.Stdout)
+	case "serve":
+		format, err := ioutil.ReadFile(string(opts.Format.FormatFile))
+		if err != nil {
+			return exitError("cannot read tavor file %s: %v", opts.Format.FormatFile, err)
+		}
+
+		s := newServer(opts, format)
+		defer s.close()
+
+		log.Infof("listen on %s", opts.Serve.Listen)
+
+		if err := http.ListenAndServe(opts.Serve.Listen, s); err != nil {
+			return exitError("cannot serve: %v", err)
+		}
 	case "reduce", "validate":
 		inputFile := opts.Validate.InputFile
+		inputEncoding := opts.Validate.InputEncoding
 
 		if command == "reduce" {
 			inputFile = opts.Reduce.InputFile
+			inputEncoding = opts.Reduce.InputEncoding
+		}
+
+		doc, err = encodeDocument(doc, inputEncoding, opts.Global.EncodingErrors)
+		if err != nil {
+			return exitError("cannot decode input file: %v", err)
 		}
 
 		input, err := os.Open(string(inputFile))
@@ -830,31 +1592,99 @@ func mainCmd(args []string) exitCodeType {
 
 			log.Infof("using %s reducing strategy", opts.Reduce.Strategy)
 
-			if opts.Reduce.Exec.Exec != "" {
-				execs := strings.Split(opts.Reduce.Exec.Exec, " ")
+			if opts.Reduce.Exec.Exec != "" || opts.Reduce.Exec.Connect != "" {
+				var connect *connectTarget
+				var execs []string
 				var execFileArguments []int
-				for i, v := range execs {
-					if v == "TAVOR_DD_FILE" {
-						execFileArguments = append(execFileArguments, i)
+
+				if opts.Reduce.Exec.Connect != "" {
+					connect, err = newConnectTarget(opts.Reduce.Exec.Connect, opts.Reduce.Exec.ConnectTimeout, opts.Reduce.Exec.ConnectReuse)
+					if err != nil {
+						return exitError(err.Error())
+					}
+					defer connect.close()
+				} else {
+					execs = strings.Split(opts.Reduce.Exec.Exec, " ")
+					for i, v := range execs {
+						if v == "TAVOR_DD_FILE" {
+							execFileArguments = append(execFileArguments, i)
+						}
 					}
 				}
 
-				stepID := 1
+				// run sends the current reduction step to the connect target or executes the binary with it and returns the exit code and if the execution hung
+				run := func(stepID int, docOut string, stderr *bytes.Buffer, stdout *bytes.Buffer) (int, bool, error) {
+					if connect != nil {
+						log.Infof("Send step %d to %s", stepID, connect)
 
-				docOut := doc.String()
+						response, err := connect.send([]byte(docOut))
+						if err != nil {
+							return 0, false, err
+						}
 
-				tmp, err := ioutil.TempFile("", fmt.Sprintf("dd-%d-", stepID))
-				if err != nil {
-					return exitError("Cannot create tmp file: %s", err)
-				}
-				_, err = tmp.WriteString(docOut)
-				if err != nil {
-					return exitError("Cannot write to tmp file: %s", err)
+						stdout.Write(response)
+
+						if opts.General.Verbose || opts.General.Debug {
+							if _, err := os.Stdout.Write(response); err != nil {
+								return 0, false, err
+							}
+						}
+
+						return 0, false, nil
+					}
+
+					tmp, err := ioutil.TempFile("", fmt.Sprintf("dd-%d-", stepID))
+					if err != nil {
+						return 0, false, fmt.Errorf("Cannot create tmp file: %s", err)
+					}
+					_, err = tmp.WriteString(docOut)
+					if err != nil {
+						return 0, false, fmt.Errorf("Cannot write to tmp file: %s", err)
+					}
+
+					if !opts.Reduce.Exec.ExecDoNotRemoveTmpFiles {
+						defer func() {
+							err := os.Remove(tmp.Name())
+							if err != nil {
+								log.Errorf("Could not remove tmp file %q: %s", tmp.Name(), err)
+							}
+						}()
+					}
+
+					log.Infof("Execute %q with %q", opts.Reduce.Exec.Exec, tmp.Name())
+
+					if string(opts.Reduce.Exec.ExecArgumentType) == "argument" {
+						for _, v := range execFileArguments {
+							execs[v] = tmp.Name()
+						}
+					}
+
+					execCommand := exec.Command(execs[0], execs[1:]...)
+
+					if string(opts.Reduce.Exec.ExecArgumentType) == "environment" {
+						execCommand.Env = []string{fmt.Sprintf("TAVOR_DD_FILE=%s", tmp.Name())}
+					}
+
+					if opts.General.Verbose || opts.General.Debug {
+						execCommand.Stderr = io.MultiWriter(stderr, os.Stderr)
+						execCommand.Stdout = io.MultiWriter(stdout, os.Stdout)
+					} else {
+						execCommand.Stderr = stderr
+						execCommand.Stdout = stdout
+					}
+
+					var stdin []byte
+					if string(opts.Reduce.Exec.ExecArgumentType) == "stdin" {
+						stdin = []byte(docOut)
+					}
+
+					return runExec(execCommand, opts.Reduce.Exec.ExecTimeout, stdin)
 				}
 
-				log.Infof("Execute %q to get original outputs with %q", opts.Reduce.Exec.Exec, tmp.Name())
+				stepID := 1
+
+				log.Info("Get original outputs")
 
-				var execExitCode int
 				var execStderr bytes.Buffer
 				var execStdout bytes.Buffer
 
@@ -868,59 +1698,19 @@ func mainCmd(args []string) exitCodeType {
 					matchStdout = regexp.MustCompile(opts.Reduce.Exec.ExecMatchStdout)
 				}
 
-				if string(opts.Reduce.Exec.ExecArgumentType) == "argument" {
-					for _, v := range execFileArguments {
-						execs[v] = tmp.Name()
-					}
-				}
-
-				execCommand := exec.Command(execs[0], execs[1:]...)
-
-				if string(opts.Reduce.Exec.ExecArgumentType) == "environment" {
-					execCommand.Env = []string{fmt.Sprintf("TAVOR_DD_FILE=%s", tmp.Name())}
-				}
-
-				if opts.General.Verbose || opts.General.Debug {
-					execCommand.Stderr = io.MultiWriter(&execStderr, os.Stderr)
-					execCommand.Stdout = io.MultiWriter(&execStdout, os.Stdout)
-				} else {
-					execCommand.Stderr = &execStderr
-					execCommand.Stdout = &execStdout
-				}
-
-				stdin, err := execCommand.StdinPipe()
+				execExitCode, execHang, err := run(stepID, doc.String(), &execStderr, &execStdout)
 				if err != nil {
-					return exitError("Could not get stdin pipe: %s", err)
-				}
-
-				err = execCommand.Start()
-				if err != nil {
-					return exitError("Could not start exce: %s", err)
-				}
-
-				if string(opts.Reduce.Exec.ExecArgumentType) == "stdin" {
-					_, err := stdin.Write([]byte(docOut))
-					if err != nil {
-						return exitError("Could not write stdin to exec: %s", err)
-					}
-
-					if err := stdin.Close(); err != nil {
-						panic(err)
-					}
+					return exitError(err.Error())
 				}
 
-				err = execCommand.Wait()
+				log.Infof("Exit status was %d", execExitCode)
 
-				if err == nil {
-					execExitCode = 0
-				} else if e, ok := err.(*exec.ExitError); ok {
-					execExitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
-				} else {
-					return exitError("Could not execute exec successfully: %s", err)
+				if execHang {
+					log.Infof("Execution hung")
+				} else if opts.Reduce.Exec.ExecExactHang {
+					return exitError("Original execution does not hang")
 				}
 
-				log.Infof("Exit status was %d", execExitCode)
-
 				if matchStderr != nil && !matchStderr.Match(execStderr.Bytes()) {
 					return exitError("Original output does not match stderr match pattern")
 				}
@@ -928,13 +1718,6 @@ func mainCmd(args []string) exitCodeType {
 					return exitError("Original output does not match stdout match pattern")
 				}
 
-				if !opts.Reduce.Exec.ExecDoNotRemoveTmpFiles {
-					err = os.Remove(tmp.Name())
-					if err != nil {
-						log.Errorf("Could not remove tmp file %q: %s", tmp.Name(), err)
-					}
-				}
-
 				contin, feedback, err := strat(doc)
 				if err != nil {
 					return exitError(err.Error())
@@ -943,79 +1726,33 @@ func mainCmd(args []string) exitCodeType {
 				for i := range contin {
 					stepID++
 
-					docOut := doc.String()
+					log.Infof("Test step %d", stepID)
 
-					tmp, err := ioutil.TempFile("", fmt.Sprintf("dd-%d-", stepID))
-					if err != nil {
-						return exitError("Cannot create tmp file: %s", err)
-					}
-					_, err = tmp.WriteString(docOut)
-					if err != nil {
-						return exitError("Cannot write to tmp file: %s", err)
-					}
-
-					log.Infof("Test %q", tmp.Name())
-
-					var cmdExitCode int
 					var cmdStderr bytes.Buffer
 					var cmdStdout bytes.Buffer
 
-					if string(opts.Reduce.Exec.ExecArgumentType) == "argument" {
-						for _, v := range execFileArguments {
-							execs[v] = tmp.Name()
-						}
-					}
-
-					execCommand := exec.Command(execs[0], execs[1:]...)
-
-					if string(opts.Reduce.Exec.ExecArgumentType) == "environment" {
-						execCommand.Env = []string{fmt.Sprintf("TAVOR_DD_FILE=%s", tmp.Name())}
-					}
-
-					if opts.General.Verbose || opts.General.Debug {
-						execCommand.Stderr = io.MultiWriter(&cmdStderr, os.Stderr)
-						execCommand.Stdout = io.MultiWriter(&cmdStdout, os.Stdout)
-					} else {
-						execCommand.Stderr = &cmdStderr
-						execCommand.Stdout = &cmdStdout
-					}
-
-					stdin, err := execCommand.StdinPipe()
-					if err != nil {
-						return exitError("Could not get stdin pipe: %s", err)
-					}
-
-					err = execCommand.Start()
+					cmdExitCode, cmdHang, err := run(stepID, doc.String(), &cmdStderr, &cmdStdout)
 					if err != nil {
-						return exitError("Could not start exce: %s", err)
+						return exitError(err.Error())
 					}
 
-					if string(opts.Reduce.Exec.ExecArgumentType) == "stdin" {
-						_, err := stdin.Write([]byte(docOut))
-						if err != nil {
-							return exitError("Could not write stdin to exec: %s", err)
-						}
+					log.Infof("Exit status was %d", cmdExitCode)
+					oks := 0
+					oksNeeded := 0
 
-						if err := stdin.Close(); err != nil {
-							panic(err)
-						}
-					}
+					// a hang is an outcome of its own which has to be the same as the original one
+					if opts.Reduce.Exec.ExecTimeout > 0 {
+						oksNeeded++
 
-					err = execCommand.Wait()
+						if execHang == cmdHang {
+							log.Infof("Same hang outcome")
 
-					if err == nil {
-						cmdExitCode = 0
-					} else if e, ok := err.(*exec.ExitError); ok {
-						cmdExitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
-					} else {
-						return exitError("Could not execute exec successfully: %s", err)
+							oks++
+						} else {
+							log.Infof("Not the same hang outcome")
+						}
 					}
 
-					log.Infof("Exit status was %d", cmdExitCode)
-
-					oks := 0
-					oksNeeded := 0
-
 					if opts.Reduce.Exec.ExecExactExitCode {
 						oksNeeded++
 
@@ -1086,13 +1823,6 @@ func mainCmd(args []string) exitCodeType {
 						}
 					}
 
-					if !opts.Reduce.Exec.ExecDoNotRemoveTmpFiles {
-						err = os.Remove(tmp.Name())
-						if err != nil {
-							log.Errorf("Could not remove tmp file %q: %s", tmp.Name(), err)
-						}
-					}
-
 					contin <- i
 				}
 			} else if opts.Reduce.Exec.Script != "" {
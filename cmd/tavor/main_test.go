@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"testing"
 
@@ -32,6 +33,203 @@ func TestMain(t *testing.T) {
 	assert.Contains(t, out, "1\n2\n3")
 }
 
+func TestMainFuzzWorkers(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = 1 | 2 | 3 | 4 | 5 | 6\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	defer func() {
+		err := os.Remove(f.Name())
+		assert.Nil(t, err)
+	}()
+
+	for _, exitOnError := range []bool{false, true} {
+		folder, err := ioutil.TempDir("", "tavor-main-test")
+		assert.Nil(t, err)
+
+		args := []string{"--format-file", f.Name(), "fuzz", "--strategy", "AllPermutations", "--exec", "cat", "--exec-exact-stdout", "1", "--workers", "4", "--result-folder", folder}
+		if exitOnError {
+			args = append(args, "--exit-on-error")
+		}
+
+		exitCode, _ := execMain(t, args)
+		assert.Equal(t, exitCodeOk, exitCode)
+
+		files, err := ioutil.ReadDir(folder)
+		assert.Nil(t, err)
+
+		var failed []string
+		for _, file := range files {
+			data, err := ioutil.ReadFile(folder + "/" + file.Name())
+			assert.Nil(t, err)
+
+			failed = append(failed, string(data))
+		}
+		sort.Strings(failed)
+
+		if exitOnError {
+			assert.Equal(t, []string{"2"}, failed)
+		} else {
+			assert.Equal(t, []string{"2", "3", "4", "5", "6"}, failed)
+		}
+
+		assert.Nil(t, os.RemoveAll(folder))
+	}
+
+	exitCode, _ := execMain(t, []string{"--format-file", f.Name(), "fuzz", "--exec", "cat", "--workers", "0"})
+	assert.Equal(t, exitCodeError, exitCode)
+}
+
+func TestMainFuzzExecFeedback(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = 1 | 2 | 3 | 4 | 5 | 6\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	folder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.Remove(f.Name()))
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	script := folder + "/exec.sh"
+	assert.Nil(t, ioutil.WriteFile(script, []byte(`x=$(cat)
+echo $x >> `+folder+`/executed
+exit $x
+`), 0644))
+
+	// every generation is executed and scored before the next one is fuzzed
+	exitCode, _ := execMain(t, []string{"--format-file", f.Name(), "--seed", "1", "fuzz", "--strategy", "FeedbackRandom", "--max-steps", "20", "--exec", "sh " + script, "--exec-exact-exit-code", "1", "--workers", "4"})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	data, err := ioutil.ReadFile(folder + "/executed")
+	assert.Nil(t, err)
+	assert.Equal(t, 20, len(strings.Split(strings.TrimSpace(string(data)), "\n")))
+}
+
+func TestMainCount(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = +(Item)\nItem = +(Digit Digit Digit Digit Digit Digit Digit Digit Digit)\nDigit = [0-9]\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	defer func() {
+		err := os.Remove(f.Name())
+		assert.Nil(t, err)
+	}()
+
+	exitCode, out := execMain(t, []string{"--format-file", f.Name(), "--count"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, "START\toverflow (at least 18446744073709551615)\nItem\t1000000001000000000\nDigit\t10\n", out)
+}
+
+func TestMainFuzzMaxSteps(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = 1 | 2 | 3\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	defer func() {
+		err := os.Remove(f.Name())
+		assert.Nil(t, err)
+	}()
+
+	exitCode, out := execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "AllPermutations", "--max-steps", "2"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, "1\n2", out)
+
+	exitCode, out = execMain(t, []string{"--format-file", f.Name(), "--seed", "1", "fuzz", "--strategy", "FeedbackRandom", "--max-steps", "5", "--result-separator", ";"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, 5, len(strings.Split(out, ";")))
+
+	exitCode, out = execMain(t, []string{"fuzz", "--list-strategies"})
+	assert.Equal(t, exitCodeHelp, exitCode)
+	assert.Contains(t, out, "FeedbackRandom\n")
+	assert.Contains(t, out, "random\n")
+}
+
+func TestMainFuzzTWay(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = (1 | 2) (1 | 2) (1 | 2)\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	defer func() {
+		err := os.Remove(f.Name())
+		assert.Nil(t, err)
+	}()
+
+	for strength, generations := range map[string]int{"1": 2, "3": 8} {
+		exitCode, out := execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "TWay", "--tway-strength", strength, "--result-separator", ";"})
+		assert.Equal(t, exitCodeOk, exitCode)
+		assert.Equal(t, generations, len(strings.Split(out, ";")), out)
+	}
+
+	exitCode, _ := execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "TWay", "--tway-strength", "0"})
+	assert.Equal(t, exitCodeError, exitCode)
+}
+
+func TestMainFuzzSeeds(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = +(1 | 2) ?(\"x\")\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	folder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.Remove(f.Name()))
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	assert.Nil(t, ioutil.WriteFile(folder+"/a", []byte("12x"), 0644))
+	assert.Nil(t, ioutil.WriteFile(folder+"/b", []byte("invalid"), 0644))
+
+	exitCode, out := execMain(t, []string{"--format-file", f.Name(), "fuzz", "--input-file", folder + "/a", "--result-separator", ";"})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	// swap of both repeat items, removal of one repeat item and deactivating the optional
+	generations := strings.Split(out, ";")
+	assert.Equal(t, 4, len(generations))
+	assert.NotContains(t, generations, "12x")
+	assert.Contains(t, generations, "11x")
+	assert.Contains(t, generations, "22x")
+	assert.Contains(t, generations, "12")
+
+	exitCode, out = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--corpus-dir", folder, "--result-separator", ";"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	// the invalid seed is skipped
+	assert.Equal(t, 4, len(strings.Split(out, ";")))
+}
+
 func TestMainCommandListingOptions(t *testing.T) {
 
 	exitCode, out := execMain(t, []string{"fuzz", "--list-exec-argument-types"})
@@ -40,6 +238,192 @@ func TestMainCommandListingOptions(t *testing.T) {
 	assert.Contains(t, out, strings.Join(execArgumentTypes, "\n"))
 }
 
+func TestMainJSONSchema(t *testing.T) {
+	folder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	schema := `{"type": "array", "items": {"enum": ["a", "b"]}, "maxItems": 2}`
+
+	assert.Nil(t, ioutil.WriteFile(folder+"/schema.json", []byte(schema), 0644))
+	assert.Nil(t, ioutil.WriteFile(folder+"/schema.tavor", []byte(schema), 0644))
+	assert.Nil(t, ioutil.WriteFile(folder+"/valid", []byte(`["b","a"]`), 0644))
+	assert.Nil(t, ioutil.WriteFile(folder+"/invalid", []byte(`["c"]`), 0644))
+
+	// the format type is detected by the extension of the format file
+	exitCode, out := execMain(t, []string{"--format-file", folder + "/schema.json", "fuzz", "--strategy", "AllPermutations", "--result-separator", ";"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, `[];["a"];["b"];["a","a"];["b","a"];["a","b"];["b","b"]`, out)
+
+	exitCode, _ = execMain(t, []string{"--format-file", folder + "/schema.json", "validate", "--input-file", folder + "/valid"})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	exitCode, _ = execMain(t, []string{"--format-file", folder + "/schema.json", "validate", "--input-file", folder + "/invalid"})
+	assert.Equal(t, exitCodeInvalidInputFile, exitCode)
+
+	exitCode, out = execMain(t, []string{"--format-file", folder + "/schema.json", "reduce", "--input-file", folder + "/valid", "--exec", "grep b", "--exec-exact-exit-code"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, "[\"b\"]\n", out)
+
+	// the format type can be given explicitly
+	exitCode, _ = execMain(t, []string{"--format-file", folder + "/schema.tavor", "validate", "--input-file", folder + "/valid"})
+	assert.Equal(t, exitCodeError, exitCode)
+
+	exitCode, _ = execMain(t, []string{"--format-file", folder + "/schema.tavor", "--format-type", "jsonschema", "validate", "--input-file", folder + "/valid"})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	// JSON Schemas have no token definitions
+	exitCode, _ = execMain(t, []string{"--format-file", folder + "/schema.json", "--count"})
+	assert.Equal(t, exitCodeError, exitCode)
+}
+
+func TestMainFuzzReduceFailures(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = \"a\" ?(\"b\") ?(\"c\") ?(\"d\")\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	folder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.Remove(f.Name()))
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	// the binary fails on every "c"
+	script := folder + "/fail.sh"
+	assert.Nil(t, ioutil.WriteFile(script, []byte("case \"$(cat)\" in *c*) exit 3;; esac\n"), 0644))
+
+	// failures are also reduced while other workers execute further generations
+	for _, workers := range []string{"1", "4"} {
+		for _, triage := range []bool{false, true} {
+			results, err := ioutil.TempDir(folder, "results")
+			assert.Nil(t, err)
+
+			args := []string{"--format-file", f.Name(), "fuzz", "--strategy", "AllPermutations", "--exec", "sh " + script, "--exec-exact-exit-code", "0", "--result-folder", results, "--reduce-failures", "--reduce-strategy", "Hierarchical", "--workers", workers}
+			if triage {
+				args = append(args, "--triage")
+			}
+
+			exitCode, _ := execMain(t, args)
+			assert.Equal(t, exitCodeOk, exitCode)
+
+			files, err := ioutil.ReadDir(results)
+			assert.Nil(t, err)
+
+			var failures, reduced []string
+			for _, file := range files {
+				data, err := ioutil.ReadFile(results + "/" + file.Name())
+				assert.Nil(t, err)
+
+				if strings.HasSuffix(file.Name(), ".reduced") {
+					reduced = append(reduced, string(data))
+				} else if file.Name() != triageReportFile {
+					failures = append(failures, string(data))
+				}
+			}
+			sort.Strings(failures)
+
+			if triage {
+				// only the representative of the bucket is reduced
+				assert.Equal(t, []string{"ac"}, failures)
+				assert.Equal(t, []string{"ac"}, reduced)
+			} else {
+				assert.Equal(t, []string{"abc", "abcd", "ac", "acd"}, failures)
+				assert.Equal(t, []string{"ac", "ac", "ac", "ac"}, reduced)
+			}
+		}
+	}
+
+	// the executable cannot be started anymore while the children of a reduced token are reduced
+	nested := folder + "/nested.tavor"
+	assert.Nil(t, ioutil.WriteFile(nested, []byte("START = \"a\" ?(\"b\" ?(\"c\") \"d\") ?(\"x\")\n"), 0644))
+
+	vanish := folder + "/vanish.sh"
+	assert.Nil(t, ioutil.WriteFile(vanish, []byte("#!/bin/sh\ncase \"$(cat)\" in\n*c*) exit 3;;\nax) if [ -e \""+folder+"/seen\" ]; then rm \"$0\"; fi; touch \""+folder+"/seen\";;\nesac\n"), 0755))
+
+	results, err := ioutil.TempDir(folder, "results")
+	assert.Nil(t, err)
+
+	exitCode, _ := execMain(t, []string{"--format-file", nested, "fuzz", "--strategy", "AllPermutations", "--exec", vanish, "--exec-exact-exit-code", "0", "--result-folder", results, "--reduce-failures", "--reduce-strategy", "Linear"})
+	assert.Equal(t, exitCodeError, exitCode)
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--exec", "sh " + script, "--reduce-failures"})
+	assert.Equal(t, exitCodeError, exitCode)
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--exec", "sh " + script, "--result-folder", folder, "--reduce-failures", "--reduce-strategy", "unknown"})
+	assert.Equal(t, exitCodeError, exitCode)
+}
+
+func TestMainExecTimeout(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = \"a\" ?(\"b\") ?(\"c\")\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	folder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.Remove(f.Name()))
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	// the binary hangs on every "c"
+	script := folder + "/hang.sh"
+	assert.Nil(t, ioutil.WriteFile(script, []byte("case \"$(cat)\" in *c*) sleep 10;; esac\n"), 0644))
+
+	results := folder + "/results"
+	assert.Nil(t, os.Mkdir(results, 0755))
+
+	exitCode, _ := execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "AllPermutations", "--exec", "sh " + script, "--exec-exact-exit-code", "0", "--exec-timeout", "200ms", "--result-folder", results})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	files, err := ioutil.ReadDir(results)
+	assert.Nil(t, err)
+
+	var hangs []string
+	for _, file := range files {
+		assert.True(t, strings.HasPrefix(file.Name(), "hang-"), file.Name())
+
+		data, err := ioutil.ReadFile(results + "/" + file.Name())
+		assert.Nil(t, err)
+
+		hangs = append(hangs, string(data))
+	}
+	sort.Strings(hangs)
+
+	assert.Equal(t, []string{"abc", "ac"}, hangs)
+
+	input := folder + "/input"
+	assert.Nil(t, ioutil.WriteFile(input, []byte("abc"), 0644))
+
+	exitCode, out := execMain(t, []string{"--format-file", f.Name(), "reduce", "--input-file", input, "--exec", "sh " + script, "--exec-timeout", "200ms", "--exec-exact-hang"})
+	assert.Equal(t, exitCodeOk, exitCode)
+	assert.Equal(t, "ac", strings.TrimSpace(out))
+
+	// the original input has to hang
+	assert.Nil(t, ioutil.WriteFile(input, []byte("ab"), 0644))
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "reduce", "--input-file", input, "--exec", "sh " + script, "--exec-timeout", "200ms", "--exec-exact-hang"})
+	assert.Equal(t, exitCodeError, exitCode)
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "reduce", "--input-file", input, "--exec", "sh " + script, "--exec-exact-hang"})
+	assert.Equal(t, exitCodeError, exitCode)
+}
+
 func execMain(t *testing.T, args []string) (exitCodeType, string) {
 	saveStderr := os.Stderr
 	saveStdout := os.Stdout
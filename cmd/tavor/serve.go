@@ -0,0 +1,718 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tavorFuzzStrategy "github.com/zimmski/tavor/fuzz/strategy"
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/parser"
+	tavorReduceStrategy "github.com/zimmski/tavor/reduce/strategy"
+	"github.com/zimmski/tavor/token"
+)
+
+// server implements the HTTP/JSON API of the serve command
+// Every fuzzing and reducing session works on its own token graph which is parsed out of the format file that was loaded on startup. Sessions are identified by a number which is unique over all sessions of the server. Sessions which are not used for the session timeout are stopped and removed.
+type server struct {
+	opts    *options
+	format  []byte
+	timeout time.Duration
+
+	mux *http.ServeMux
+
+	// done stops the expiring of sessions when the server is closed
+	done chan struct{}
+
+	mutex    sync.Mutex
+	closed   bool
+	lastID   int
+	fuzzing  map[string]*fuzzSession
+	reducing map[string]*reduceSession
+}
+
+type fuzzSession struct {
+	mutex sync.Mutex
+
+	doc      token.Token
+	flow     *fuzzFlow
+	encoding string
+
+	started  bool
+	done     bool
+	step     int
+	score    tavorFuzzStrategy.FeedbackScore
+	lastUsed time.Time
+}
+
+type reduceSession struct {
+	mutex sync.Mutex
+
+	doc      token.Token
+	contin   chan struct{}
+	feedback chan<- tavorReduceStrategy.ReduceFeedbackType
+	encoding string
+
+	done     bool
+	step     int
+	lastUsed time.Time
+}
+
+type serveRequest struct {
+	// Strategy is the name of the fuzzing or reducing strategy
+	Strategy string `json:"strategy"`
+	// Seed is the seed of a fuzzing session, 0 derives the seed from the seed of the server
+	Seed int64 `json:"seed"`
+	// Encoding is the encoding of all data of a request or session, which is either empty for plain text or "base64"
+	Encoding string `json:"encoding"`
+	// Data is the input for validating and reducing
+	Data string `json:"data"`
+	// Score is the feedback of a fuzzing generation
+	Score int `json:"score"`
+	// Good is the feedback of a reducing step
+	Good *bool `json:"good"`
+}
+
+type serveStrategies struct {
+	Fuzz   []string `json:"fuzz"`
+	Reduce []string `json:"reduce"`
+}
+
+type serveSession struct {
+	ID string `json:"id"`
+}
+
+type serveGeneration struct {
+	ID   string `json:"id,omitempty"`
+	Step int    `json:"step"`
+	Data string `json:"data"`
+	Done bool   `json:"done"`
+}
+
+type serveValidation struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors"`
+}
+
+type serveError struct {
+	Error string `json:"error"`
+}
+
+func newServer(opts *options, format []byte) *server {
+	s := &server{
+		opts:    opts,
+		format:  format,
+		timeout: opts.Serve.SessionTimeout,
+
+		mux: http.NewServeMux(),
+
+		done: make(chan struct{}),
+
+		fuzzing:  make(map[string]*fuzzSession),
+		reducing: make(map[string]*reduceSession),
+	}
+
+	s.mux.HandleFunc("/strategies", s.handleStrategies)
+	s.mux.HandleFunc("/validate", s.handleValidate)
+	s.mux.HandleFunc("/fuzz", s.handleFuzz)
+	s.mux.HandleFunc("/fuzz/", s.handleFuzzSession)
+	s.mux.HandleFunc("/reduce", s.handleReduce)
+	s.mux.HandleFunc("/reduce/", s.handleReduceSession)
+
+	if s.timeout > 0 {
+		ticker := time.NewTicker(s.timeout / 2)
+
+		go func() {
+			defer ticker.Stop()
+
+			for {
+				select {
+				case now := <-ticker.C:
+					s.expire(now)
+				case <-s.done:
+					return
+				}
+			}
+		}()
+	}
+
+	return s
+}
+
+// close stops the expiring of sessions and stops and removes all sessions of the server
+func (s *server) close() {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+
+		return
+	}
+	s.closed = true
+
+	close(s.done)
+
+	fuzzing := s.fuzzing
+	reducing := s.reducing
+	s.fuzzing = make(map[string]*fuzzSession)
+	s.reducing = make(map[string]*reduceSession)
+	s.mutex.Unlock()
+
+	for _, sess := range fuzzing {
+		sess.mutex.Lock()
+		sess.stop()
+		sess.mutex.Unlock()
+	}
+
+	for _, sess := range reducing {
+		sess.mutex.Lock()
+		sess.stop()
+		sess.mutex.Unlock()
+	}
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Infof("%s %s", r.Method, r.URL.Path)
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *server) nextID() (string, int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastID++
+
+	return strconv.Itoa(s.lastID), s.lastID
+}
+
+// expire stops and removes all sessions which were not used for the session timeout
+func (s *server) expire(now time.Time) {
+	s.mutex.Lock()
+	fuzzing := make(map[string]*fuzzSession, len(s.fuzzing))
+	for id, sess := range s.fuzzing {
+		fuzzing[id] = sess
+	}
+	reducing := make(map[string]*reduceSession, len(s.reducing))
+	for id, sess := range s.reducing {
+		reducing[id] = sess
+	}
+	s.mutex.Unlock()
+
+	for id, sess := range fuzzing {
+		sess.mutex.Lock()
+		if now.Sub(sess.lastUsed) >= s.timeout {
+			sess.stop()
+
+			s.mutex.Lock()
+			delete(s.fuzzing, id)
+			s.mutex.Unlock()
+
+			log.Infof("fuzzing session %s expired", id)
+		}
+		sess.mutex.Unlock()
+	}
+
+	for id, sess := range reducing {
+		sess.mutex.Lock()
+		if now.Sub(sess.lastUsed) >= s.timeout {
+			sess.stop()
+
+			s.mutex.Lock()
+			delete(s.reducing, id)
+			s.mutex.Unlock()
+
+			log.Infof("reducing session %s expired", id)
+		}
+		sess.mutex.Unlock()
+	}
+}
+
+// parse parses the format file into a new token graph and applies the fuzzing filters of the serve command
+func (s *server) parse() (token.Token, error) {
+	doc, err := parseFormat(s.opts, bytes.NewReader(s.format))
+	if err != nil {
+		return nil, err
+	}
+
+	return applyFilters(s.opts, s.opts.Serve.Filter.Filters, doc)
+}
+
+func (s *server) handleStrategies(w http.ResponseWriter, r *http.Request) {
+	if !expectMethod(w, r, "GET") {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &serveStrategies{
+		Fuzz:   fuzzStrategies(),
+		Reduce: tavorReduceStrategy.List(),
+	})
+}
+
+func (s *server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	if !expectMethod(w, r, "POST") {
+		return
+	}
+
+	req, data, ok := readRequest(w, r)
+	if !ok {
+		return
+	}
+
+	doc, err := s.parse()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	errs := parser.ParseInternal(doc, bytes.NewReader(data))
+
+	log.Infof("validated input with encoding %q, %d errors", req.Encoding, len(errs))
+
+	writeJSON(w, http.StatusOK, validation(errs))
+}
+
+func (s *server) handleFuzz(w http.ResponseWriter, r *http.Request) {
+	if !expectMethod(w, r, "POST") {
+		return
+	}
+
+	req, _, ok := readRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if req.Strategy == "" {
+		req.Strategy = "random"
+	}
+
+	doc, err := s.parse()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	id, n := s.nextID()
+
+	seed := req.Seed
+	if seed == 0 {
+		seed = s.opts.Global.Seed + int64(n)
+	}
+
+	flow, err := newStrategyFuzzFlow(req.Strategy, tavorFuzzStrategy.DefaultTWayStrength, 0, doc, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+
+		flow.stop()
+
+		writeError(w, http.StatusServiceUnavailable, "server is closed")
+
+		return
+	}
+	s.fuzzing[id] = &fuzzSession{
+		doc:      doc,
+		flow:     flow,
+		encoding: req.Encoding,
+		lastUsed: time.Now(),
+	}
+	s.mutex.Unlock()
+
+	log.Infof("started fuzzing session %s using %s fuzzing strategy with seed %d", id, req.Strategy, seed)
+
+	writeJSON(w, http.StatusCreated, &serveSession{
+		ID: id,
+	})
+}
+
+func (s *server) handleFuzzSession(w http.ResponseWriter, r *http.Request) {
+	id, action := splitSessionPath(r.URL.Path, "/fuzz/")
+
+	s.mutex.Lock()
+	sess, ok := s.fuzzing[id]
+	s.mutex.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown fuzzing session %q", id))
+
+		return
+	}
+
+	sess.mutex.Lock()
+	defer sess.mutex.Unlock()
+
+	sess.lastUsed = time.Now()
+
+	switch action {
+	case "":
+		if !expectMethod(w, r, "DELETE") {
+			return
+		}
+
+		sess.stop()
+
+		s.mutex.Lock()
+		delete(s.fuzzing, id)
+		s.mutex.Unlock()
+
+		log.Infof("stopped fuzzing session %s", id)
+
+		w.WriteHeader(http.StatusNoContent)
+	case "next":
+		if !expectMethod(w, r, "POST") {
+			return
+		}
+
+		g := &serveGeneration{}
+
+		if sess.next() {
+			g.Step = sess.step
+			g.Data = encode(sess.encoding, sess.doc.String())
+		} else {
+			g.Step = sess.step
+			g.Done = true
+
+			log.Infof("fuzzing session %s is done after %d steps", id, sess.step)
+		}
+
+		writeJSON(w, http.StatusOK, g)
+	case "feedback":
+		if !expectMethod(w, r, "POST") {
+			return
+		}
+
+		req, _, ok := readRequest(w, r)
+		if !ok {
+			return
+		}
+
+		if !sess.started || sess.done {
+			writeError(w, http.StatusConflict, "there is no generation for feedback")
+
+			return
+		}
+
+		sess.score = tavorFuzzStrategy.FeedbackScore(req.Score)
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown action %q", action))
+	}
+}
+
+// next continues the fuzzing strategy with the feedback of the current generation and waits for the next generation. False is returned if the strategy has no further generations.
+func (sess *fuzzSession) next() bool {
+	if sess.done {
+		return false
+	}
+
+	if sess.started {
+		sess.flow.next(sess.score)
+	}
+
+	sess.started = true
+	sess.score = 0
+
+	if !sess.flow.wait() {
+		sess.done = true
+
+		return false
+	}
+
+	sess.step++
+
+	return true
+}
+
+func (sess *fuzzSession) stop() {
+	if sess.done {
+		return
+	}
+
+	sess.done = true
+
+	sess.flow.stop()
+}
+
+func (s *server) handleReduce(w http.ResponseWriter, r *http.Request) {
+	if !expectMethod(w, r, "POST") {
+		return
+	}
+
+	req, data, ok := readRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if req.Strategy == "" {
+		req.Strategy = "Linear"
+	}
+
+	strat, err := tavorReduceStrategy.New(req.Strategy)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	doc, err := s.parse()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	if errs := parser.ParseInternal(doc, bytes.NewReader(data)); len(errs) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, validation(errs))
+
+		return
+	}
+
+	contin, feedback, err := strat(doc)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	id, _ := s.nextID()
+
+	sess := &reduceSession{
+		doc:      doc,
+		contin:   contin,
+		feedback: feedback,
+		encoding: req.Encoding,
+		lastUsed: time.Now(),
+	}
+
+	log.Infof("started reducing session %s using %s reducing strategy", id, req.Strategy)
+
+	g := sess.next()
+	g.ID = id
+
+	if !g.Done {
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+
+			sess.stop()
+
+			writeError(w, http.StatusServiceUnavailable, "server is closed")
+
+			return
+		}
+		s.reducing[id] = sess
+		s.mutex.Unlock()
+	}
+
+	writeJSON(w, http.StatusCreated, g)
+}
+
+func (s *server) handleReduceSession(w http.ResponseWriter, r *http.Request) {
+	id, action := splitSessionPath(r.URL.Path, "/reduce/")
+
+	s.mutex.Lock()
+	sess, ok := s.reducing[id]
+	s.mutex.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown reducing session %q", id))
+
+		return
+	}
+
+	sess.mutex.Lock()
+	defer sess.mutex.Unlock()
+
+	sess.lastUsed = time.Now()
+
+	switch action {
+	case "":
+		if !expectMethod(w, r, "DELETE") {
+			return
+		}
+
+		sess.stop()
+
+		s.mutex.Lock()
+		delete(s.reducing, id)
+		s.mutex.Unlock()
+
+		log.Infof("stopped reducing session %s", id)
+
+		w.WriteHeader(http.StatusNoContent)
+	case "feedback":
+		if !expectMethod(w, r, "POST") {
+			return
+		}
+
+		req, _, ok := readRequest(w, r)
+		if !ok {
+			return
+		}
+
+		if req.Good == nil {
+			writeError(w, http.StatusBadRequest, "feedback \"good\" is missing")
+
+			return
+		}
+
+		if sess.done {
+			writeError(w, http.StatusConflict, "reducing is already done")
+
+			return
+		}
+
+		if *req.Good {
+			sess.feedback <- tavorReduceStrategy.Good
+		} else {
+			sess.feedback <- tavorReduceStrategy.Bad
+		}
+
+		sess.contin <- struct{}{}
+
+		g := sess.next()
+		if g.Done {
+			s.mutex.Lock()
+			delete(s.reducing, id)
+			s.mutex.Unlock()
+
+			log.Infof("reducing session %s is done after %d steps", id, sess.step)
+		}
+
+		writeJSON(w, http.StatusOK, g)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown action %q", action))
+	}
+}
+
+// next waits for the next step of the reducing strategy. If the strategy has no further steps the reduced data is returned.
+func (sess *reduceSession) next() *serveGeneration {
+	if _, ok := <-sess.contin; ok {
+		sess.step++
+	} else {
+		sess.done = true
+	}
+
+	return &serveGeneration{
+		Step: sess.step,
+		Data: encode(sess.encoding, sess.doc.String()),
+		Done: sess.done,
+	}
+}
+
+func (sess *reduceSession) stop() {
+	if sess.done {
+		return
+	}
+
+	sess.done = true
+
+	close(sess.feedback)
+}
+
+func splitSessionPath(path string, prefix string) (string, string) {
+	ps := strings.SplitN(strings.TrimPrefix(path, prefix), "/", 2)
+
+	if len(ps) == 1 {
+		return ps[0], ""
+	}
+
+	return ps[0], ps[1]
+}
+
+func expectMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed, use %s", r.Method, method))
+
+		return false
+	}
+
+	return true
+}
+
+// readRequest decodes the JSON body of the request and its data. An error response is written and false is returned if the request is invalid.
+func readRequest(w http.ResponseWriter, r *http.Request) (*serveRequest, []byte, bool) {
+	req := &serveRequest{}
+
+	// an empty body is an empty request
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON request: %v", err))
+
+		return nil, nil, false
+	}
+
+	var data []byte
+
+	switch req.Encoding {
+	case "":
+		data = []byte(req.Data)
+	case "base64":
+		var err error
+
+		data, err = base64.StdEncoding.DecodeString(req.Data)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid base64 data: %v", err))
+
+			return nil, nil, false
+		}
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown encoding %q", req.Encoding))
+
+		return nil, nil, false
+	}
+
+	return req, data, true
+}
+
+func encode(encoding string, data string) string {
+	if encoding == "base64" {
+		return base64.StdEncoding.EncodeToString([]byte(data))
+	}
+
+	return data
+}
+
+func validation(errs []error) *serveValidation {
+	v := &serveValidation{
+		Valid:  len(errs) == 0,
+		Errors: []string{},
+	}
+
+	for _, err := range errs {
+		v.Errors = append(v.Errors, err.Error())
+	}
+
+	return v
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("cannot write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, &serveError{
+		Error: message,
+	})
+}
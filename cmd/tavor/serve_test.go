@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func newServeTest(t *testing.T, format string) *httptest.Server {
+	opts := new(options)
+	opts.Global.Seed = 1
+	opts.Global.CharacterUniverse = string(primitives.DefaultCharacterUniverse)
+
+	return httptest.NewServer(newServer(opts, []byte(format)))
+}
+
+func serveRequestTest(t *testing.T, s *httptest.Server, method string, path string, req interface{}, res interface{}) int {
+	var body bytes.Buffer
+
+	if req != nil {
+		assert.Nil(t, json.NewEncoder(&body).Encode(req))
+	}
+
+	r, err := http.NewRequest(method, s.URL+path, &body)
+	assert.Nil(t, err)
+
+	resp, err := http.DefaultClient.Do(r)
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, resp.Body.Close())
+	}()
+
+	if res != nil {
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(res))
+	}
+
+	return resp.StatusCode
+}
+
+func TestServeStrategies(t *testing.T) {
+	s := newServeTest(t, "START = 1\n")
+	defer s.Close()
+
+	var strategies serveStrategies
+	assert.Equal(t, http.StatusOK, serveRequestTest(t, s, "GET", "/strategies", nil, &strategies))
+	assert.Contains(t, strategies.Fuzz, "random")
+	assert.Contains(t, strategies.Fuzz, "FeedbackRandom")
+	assert.Contains(t, strategies.Reduce, "Linear")
+
+	var e serveError
+	assert.Equal(t, http.StatusMethodNotAllowed, serveRequestTest(t, s, "POST", "/strategies", nil, &e))
+}
+
+func TestServeValidate(t *testing.T) {
+	s := newServeTest(t, "START = \"a\" +(\"b\")\n")
+	defer s.Close()
+
+	var v serveValidation
+	assert.Equal(t, http.StatusOK, serveRequestTest(t, s, "POST", "/validate", &serveRequest{Data: "abb"}, &v))
+	assert.True(t, v.Valid)
+	assert.Empty(t, v.Errors)
+
+	v = serveValidation{}
+	assert.Equal(t, http.StatusOK, serveRequestTest(t, s, "POST", "/validate", &serveRequest{Data: "ba"}, &v))
+	assert.False(t, v.Valid)
+	assert.NotEmpty(t, v.Errors)
+
+	v = serveValidation{}
+	assert.Equal(t, http.StatusOK, serveRequestTest(t, s, "POST", "/validate", &serveRequest{Data: "YWI=", Encoding: "base64"}, &v))
+	assert.True(t, v.Valid)
+
+	var e serveError
+	assert.Equal(t, http.StatusBadRequest, serveRequestTest(t, s, "POST", "/validate", &serveRequest{Data: "ab", Encoding: "rot13"}, &e))
+	assert.Contains(t, e.Error, "unknown encoding")
+}
+
+func TestServeFuzz(t *testing.T) {
+	s := newServeTest(t, "START = 1 | 2 | 3\n")
+	defer s.Close()
+
+	var sess serveSession
+	assert.Equal(t, http.StatusCreated, serveRequestTest(t, s, "POST", "/fuzz", &serveRequest{Strategy: "AllPermutations"}, &sess))
+
+	var got []string
+	for i := 1; ; i++ {
+		var g serveGeneration
+		assert.Equal(t, http.StatusOK, serveRequestTest(t, s, "POST", "/fuzz/"+sess.ID+"/next", nil, &g))
+
+		if g.Done {
+			break
+		}
+
+		assert.Equal(t, i, g.Step)
+		got = append(got, g.Data)
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, got)
+
+	assert.Equal(t, http.StatusNoContent, serveRequestTest(t, s, "DELETE", "/fuzz/"+sess.ID, nil, nil))
+
+	var e serveError
+	assert.Equal(t, http.StatusNotFound, serveRequestTest(t, s, "POST", "/fuzz/"+sess.ID+"/next", nil, &e))
+
+	assert.Equal(t, http.StatusBadRequest, serveRequestTest(t, s, "POST", "/fuzz", &serveRequest{Strategy: "unknown"}, &e))
+}
+
+func TestServeFuzzFeedback(t *testing.T) {
+	s := newServeTest(t, "START = +([a-z])\n")
+	defer s.Close()
+
+	var sess serveSession
+	assert.Equal(t, http.StatusCreated, serveRequestTest(t, s, "POST", "/fuzz", &serveRequest{Strategy: "FeedbackRandom", Encoding: "base64"}, &sess))
+
+	var e serveError
+	assert.Equal(t, http.StatusConflict, serveRequestTest(t, s, "POST", "/fuzz/"+sess.ID+"/feedback", &serveRequest{Score: 1}, &e))
+
+	for i := 1; i <= 10; i++ {
+		var g serveGeneration
+		assert.Equal(t, http.StatusOK, serveRequestTest(t, s, "POST", "/fuzz/"+sess.ID+"/next", nil, &g))
+		assert.Equal(t, i, g.Step)
+		assert.False(t, g.Done)
+		assert.NotEmpty(t, g.Data)
+
+		assert.Equal(t, http.StatusNoContent, serveRequestTest(t, s, "POST", "/fuzz/"+sess.ID+"/feedback", &serveRequest{Score: i}, nil))
+	}
+
+	assert.Equal(t, http.StatusNoContent, serveRequestTest(t, s, "DELETE", "/fuzz/"+sess.ID, nil, nil))
+
+	// sessions which never generated can be stopped too
+	assert.Equal(t, http.StatusCreated, serveRequestTest(t, s, "POST", "/fuzz", &serveRequest{Strategy: "random"}, &sess))
+	assert.Equal(t, http.StatusNoContent, serveRequestTest(t, s, "DELETE", "/fuzz/"+sess.ID, nil, nil))
+}
+
+func TestServeReduce(t *testing.T) {
+	s := newServeTest(t, "START = \"a\" ?(\"b\") ?(\"c\")\n")
+	defer s.Close()
+
+	var v serveValidation
+	assert.Equal(t, http.StatusUnprocessableEntity, serveRequestTest(t, s, "POST", "/reduce", &serveRequest{Data: "x"}, &v))
+	assert.False(t, v.Valid)
+
+	var g serveGeneration
+	assert.Equal(t, http.StatusCreated, serveRequestTest(t, s, "POST", "/reduce", &serveRequest{Data: "abc"}, &g))
+	assert.NotEmpty(t, g.ID)
+	assert.False(t, g.Done)
+
+	id := g.ID
+
+	// everything containing a c is good
+	for !g.Done {
+		good := bytes.Contains([]byte(g.Data), []byte("c"))
+
+		g = serveGeneration{}
+		assert.Equal(t, http.StatusOK, serveRequestTest(t, s, "POST", "/reduce/"+id+"/feedback", &serveRequest{Good: &good}, &g))
+	}
+	assert.Equal(t, "ac", g.Data)
+
+	var e serveError
+	assert.Equal(t, http.StatusNotFound, serveRequestTest(t, s, "DELETE", "/reduce/"+id, nil, &e))
+
+	assert.Equal(t, http.StatusCreated, serveRequestTest(t, s, "POST", "/reduce", &serveRequest{Data: "abc"}, &g))
+	assert.Equal(t, http.StatusBadRequest, serveRequestTest(t, s, "POST", "/reduce/"+g.ID+"/feedback", nil, &e))
+	assert.Equal(t, http.StatusNoContent, serveRequestTest(t, s, "DELETE", "/reduce/"+g.ID, nil, nil))
+}
+
+func TestServeSessionTimeout(t *testing.T) {
+	opts := new(options)
+	opts.Global.Seed = 1
+	opts.Global.CharacterUniverse = string(primitives.DefaultCharacterUniverse)
+
+	srv := newServer(opts, []byte("START = \"a\" ?(\"b\")\n"))
+	srv.timeout = time.Minute
+	defer srv.close()
+
+	s := httptest.NewServer(srv)
+	defer s.Close()
+
+	var sess serveSession
+	assert.Equal(t, http.StatusCreated, serveRequestTest(t, s, "POST", "/fuzz", &serveRequest{}, &sess))
+
+	var g serveGeneration
+	assert.Equal(t, http.StatusCreated, serveRequestTest(t, s, "POST", "/reduce", &serveRequest{Data: "ab"}, &g))
+	assert.False(t, g.Done)
+
+	// sessions which are used are kept
+	srv.expire(time.Now())
+
+	assert.Equal(t, http.StatusOK, serveRequestTest(t, s, "POST", "/fuzz/"+sess.ID+"/next", nil, &serveGeneration{}))
+
+	srv.expire(time.Now().Add(2 * time.Minute))
+
+	var e serveError
+	assert.Equal(t, http.StatusNotFound, serveRequestTest(t, s, "POST", "/fuzz/"+sess.ID+"/next", nil, &e))
+	assert.Equal(t, http.StatusNotFound, serveRequestTest(t, s, "DELETE", "/reduce/"+g.ID, nil, &e))
+}
+
+func TestServeClose(t *testing.T) {
+	opts := new(options)
+	opts.Global.Seed = 1
+	opts.Global.CharacterUniverse = string(primitives.DefaultCharacterUniverse)
+	opts.Serve.SessionTimeout = time.Minute
+
+	srv := newServer(opts, []byte("START = \"a\" ?(\"b\")\n"))
+
+	s := httptest.NewServer(srv)
+	defer s.Close()
+
+	var sess serveSession
+	assert.Equal(t, http.StatusCreated, serveRequestTest(t, s, "POST", "/fuzz", &serveRequest{}, &sess))
+
+	var g serveGeneration
+	assert.Equal(t, http.StatusCreated, serveRequestTest(t, s, "POST", "/reduce", &serveRequest{Data: "ab"}, &g))
+	assert.False(t, g.Done)
+
+	srv.close()
+
+	// closing is idempotent
+	srv.close()
+
+	var e serveError
+	assert.Equal(t, http.StatusNotFound, serveRequestTest(t, s, "POST", "/fuzz/"+sess.ID+"/next", nil, &e))
+	assert.Equal(t, http.StatusNotFound, serveRequestTest(t, s, "DELETE", "/reduce/"+g.ID, nil, &e))
+
+	// no sessions are started after the server is closed
+	assert.Equal(t, http.StatusServiceUnavailable, serveRequestTest(t, s, "POST", "/fuzz", &serveRequest{}, &e))
+	assert.Equal(t, "server is closed", e.Error)
+	assert.Equal(t, http.StatusServiceUnavailable, serveRequestTest(t, s, "POST", "/reduce", &serveRequest{Data: "ab"}, &e))
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zimmski/tavor/log"
+)
+
+const triageReportFile = "triage.json"
+
+var triageNormalize = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+
+// triage buckets failing executions by a signature of their outcome and keeps one representative generation per bucket
+type triage struct {
+	folder    string
+	extension string
+	frames    *regexp.Regexp
+	maxFrames int
+
+	buckets map[string]*triageBucket
+}
+
+type triageBucket struct {
+	ID        string `json:"id"`
+	Signature string `json:"signature"`
+	Count     int    `json:"count"`
+	FirstStep int    `json:"firstStep"`
+	File      string `json:"file"`
+}
+
+// newTriage returns a new triage which saves the representatives with the given file extension and the report to the given folder. The frames regex extracts the frames of stderr, or of the response of a connect target, which are part of the signature and can be nil.
+func newTriage(folder string, extension string, frames *regexp.Regexp, maxFrames int) *triage {
+	return &triage{
+		folder:    folder,
+		extension: extension,
+		frames:    frames,
+		maxFrames: maxFrames,
+
+		buckets: make(map[string]*triageBucket),
+	}
+}
+
+// signature returns the signature of the execution outcome which consists of the hang, connect failure, signal or exit code and the normalised stderr frames. Outcomes of connect targets consist of the connect failure or response and the normalised frames of the response.
+func (t *triage) signature(res *fuzzExecResult) string {
+	var sig string
+
+	output := res.stderr.Bytes()
+
+	if res.hang {
+		sig = "hang"
+	} else if res.connectFailure != nil {
+		sig = "connect failure"
+	} else if res.connect {
+		sig = "response"
+		output = res.stdout.Bytes()
+	} else if res.signal != 0 {
+		sig = "signal " + res.signal.String()
+	} else {
+		sig = fmt.Sprintf("exit %d", res.exitCode)
+	}
+
+	if t.frames == nil {
+		return sig
+	}
+
+	var frames []string
+
+	for _, m := range t.frames.FindAllSubmatch(output, t.maxFrames) {
+		frame := m[0]
+		if len(m) > 1 {
+			frame = m[1]
+		}
+
+		// addresses differ between runs of the same failure
+		frames = append(frames, triageNormalize.ReplaceAllString(strings.TrimSpace(string(frame)), "0x?"))
+	}
+
+	if len(frames) == 0 {
+		return sig
+	}
+
+	return sig + "\n" + strings.Join(frames, "\n")
+}
+
+// add puts the failing execution into its bucket and returns the bucket and if it is new. The generation is saved as representative if the bucket is new.
+func (t *triage) add(res *fuzzExecResult) (*triageBucket, bool, error) {
+	sig := t.signature(res)
+
+	b, ok := t.buckets[sig]
+	if ok {
+		b.Count++
+
+		log.Infof("Failure belongs to bucket %s which has now %d failures", b.ID, b.Count)
+
+		return b, false, nil
+	}
+
+	id := fmt.Sprintf("%x", md5.Sum([]byte(sig)))[:12]
+
+	b = &triageBucket{
+		ID:        id,
+		Signature: sig,
+		Count:     1,
+		FirstStep: res.generation.stepID,
+		File:      "bucket-" + id + t.extension,
+	}
+	t.buckets[sig] = b
+
+	if err := ioutil.WriteFile(filepath.Join(t.folder, b.File), []byte(res.generation.rendered.Data), 0644); err != nil {
+		return nil, false, fmt.Errorf("Cannot write representative of bucket %s: %v", id, err)
+	}
+
+	log.Infof("Failure opened new bucket %s with signature %q", b.ID, sig)
+
+	return b, true, nil
+}
+
+// report writes the summary of all buckets ordered by their failure count to the folder and returns the buckets
+func (t *triage) report() ([]*triageBucket, error) {
+	buckets := make([]*triageBucket, 0, len(t.buckets))
+	for _, b := range t.buckets {
+		buckets = append(buckets, b)
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+
+		return buckets[i].FirstStep < buckets[j].FirstStep
+	})
+
+	data, err := json.MarshalIndent(struct {
+		Buckets []*triageBucket `json:"buckets"`
+	}{
+		Buckets: buckets,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(t.folder, triageReportFile), data, 0644); err != nil {
+		return nil, fmt.Errorf("Cannot write triage report: %v", err)
+	}
+
+	return buckets, nil
+}
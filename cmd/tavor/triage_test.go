@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tavorFuzzStrategy "github.com/zimmski/tavor/fuzz/strategy"
+)
+
+func TestTriageSignature(t *testing.T) {
+	tri := newTriage("", "", regexp.MustCompile(`#\d+ (.+)`), 2)
+
+	res := &fuzzExecResult{
+		exitCode: 3,
+	}
+	res.stderr.WriteString("#0 0xdeadbeef in parse\n#1 0x1234 in main\n#2 0x1 in start\n")
+
+	assert.Equal(t, "exit 3\n0x? in parse\n0x? in main", tri.signature(res))
+
+	res.signal = syscall.SIGSEGV
+	assert.Equal(t, "signal segmentation fault\n0x? in parse\n0x? in main", tri.signature(res))
+
+	res.hang = true
+	assert.Equal(t, "hang\n0x? in parse\n0x? in main", tri.signature(res))
+
+	tri = newTriage("", "", nil, 2)
+	assert.Equal(t, "hang", tri.signature(res))
+
+	// connect targets are bucketed by their response
+	tri = newTriage("", "", regexp.MustCompile(`error: (\w+)`), 2)
+
+	res = &fuzzExecResult{
+		connect: true,
+	}
+	res.stdout.WriteString("error: parse\n")
+	assert.Equal(t, "response\nparse", tri.signature(res))
+
+	res.connectFailure = errors.New("connection refused")
+	assert.Equal(t, "connect failure", tri.signature(res))
+}
+
+func TestTriage(t *testing.T) {
+	folder, err := ioutil.TempDir("", "tavor-triage-test")
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	tri := newTriage(folder, ".txt", nil, 5)
+
+	for i, exitCode := range []int{1, 2, 1, 1} {
+		res := &fuzzExecResult{
+			generation: fuzzGeneration{
+				stepID:   i + 1,
+				rendered: &tavorFuzzStrategy.Generation{Data: string(rune('a' + i))},
+			},
+			exitCode: exitCode,
+		}
+
+		b, isNew, err := tri.add(res)
+		assert.Nil(t, err)
+		assert.Equal(t, i < 2, isNew)
+		assert.Equal(t, "exit "+string(rune('0'+exitCode)), b.Signature)
+	}
+
+	buckets, err := tri.report()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(buckets))
+
+	assert.Equal(t, "exit 1", buckets[0].Signature)
+	assert.Equal(t, 3, buckets[0].Count)
+	assert.Equal(t, 1, buckets[0].FirstStep)
+	assert.Equal(t, "exit 2", buckets[1].Signature)
+	assert.Equal(t, 1, buckets[1].Count)
+	assert.True(t, strings.HasSuffix(buckets[0].File, ".txt"))
+
+	// only the first generation of a bucket is kept
+	data, err := ioutil.ReadFile(folder + "/" + buckets[0].File)
+	assert.Nil(t, err)
+	assert.Equal(t, "a", string(data))
+
+	data, err = ioutil.ReadFile(folder + "/" + buckets[1].File)
+	assert.Nil(t, err)
+	assert.Equal(t, "b", string(data))
+}
+
+func TestMainFuzzTriage(t *testing.T) {
+	f, err := ioutil.TempFile("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	_, err = f.WriteString("START = 1 | 2 | 3 | 4 | 5 | 6\n")
+	assert.Nil(t, err)
+
+	err = f.Close()
+	assert.Nil(t, err)
+
+	folder, err := ioutil.TempDir("", "tavor-main-test")
+	assert.Nil(t, err)
+
+	defer func() {
+		assert.Nil(t, os.Remove(f.Name()))
+		assert.Nil(t, os.RemoveAll(folder))
+	}()
+
+	script := folder + "/crash.sh"
+	assert.Nil(t, ioutil.WriteFile(script, []byte(`x=$(cat)
+case $x in
+1|2) echo "#0 0x$x$x crash in parse" >&2; echo "#1 0x99 main" >&2; exit 3;;
+3) echo "#0 0x11 crash in eval" >&2; exit 3;;
+4) kill -SEGV $$;;
+esac
+`), 0644))
+
+	results := folder + "/results"
+	assert.Nil(t, os.Mkdir(results, 0755))
+
+	exitCode, _ := execMain(t, []string{"--format-file", f.Name(), "fuzz", "--strategy", "AllPermutations", "--exec", "sh " + script, "--exec-exact-exit-code", "0", "--result-folder", results, "--triage", "--triage-frames", `#\d+ (.+)`})
+	assert.Equal(t, exitCodeOk, exitCode)
+
+	files, err := ioutil.ReadDir(results)
+	assert.Nil(t, err)
+
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	sort.Strings(names)
+
+	assert.Equal(t, 4, len(names))
+	assert.Contains(t, names, triageReportFile)
+
+	data, err := ioutil.ReadFile(results + "/" + triageReportFile)
+	assert.Nil(t, err)
+
+	var report struct {
+		Buckets []triageBucket `json:"buckets"`
+	}
+	assert.Nil(t, json.Unmarshal(data, &report))
+
+	var summary []string
+	for _, b := range report.Buckets {
+		summary = append(summary, strings.Replace(b.Signature, "\n", "|", -1)+"="+string(rune('0'+b.Count)))
+
+		data, err := ioutil.ReadFile(results + "/" + b.File)
+		assert.Nil(t, err)
+		assert.Equal(t, string(rune('0'+b.FirstStep)), string(data))
+	}
+
+	assert.Equal(t, []string{
+		"exit 3|0x? crash in parse|0x? main=2",
+		"exit 3|0x? crash in eval=1",
+		"signal segmentation fault=1",
+	}, summary)
+
+	exitCode, _ = execMain(t, []string{"--format-file", f.Name(), "fuzz", "--exec", "sh " + script, "--triage"})
+	assert.Equal(t, exitCodeError, exitCode)
+}
@@ -0,0 +1,181 @@
+// Package encoding provides character encodings which transcode the UTF-8 text of token graphs
+package encoding
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// ErrorType the encoding error type
+type ErrorType int
+
+const (
+	// ErrInvalidCharacter the data holds a byte sequence which is not valid for the encoding
+	ErrInvalidCharacter ErrorType = iota
+	// ErrUnrepresentableCharacter the character cannot be represented by the encoding
+	ErrUnrepresentableCharacter
+)
+
+// Error holds an encoding error
+type Error struct {
+	Message string
+	Type    ErrorType
+	Offset  int
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// ErrorMode defines how invalid and unrepresentable characters are handled
+type ErrorMode string
+
+const (
+	// ErrorModeReport reports the first invalid or unrepresentable character as error. The data is still transcoded like with ErrorModeReplace.
+	ErrorModeReport ErrorMode = "report"
+	// ErrorModeReplace replaces invalid and unrepresentable characters with the replacement character of the encoding
+	ErrorModeReplace ErrorMode = "replace"
+	// ErrorModeRaw copies invalid and unrepresentable characters unchanged. This allows to inject byte sequences which are not valid for an encoding deliberately.
+	ErrorModeRaw ErrorMode = "raw"
+)
+
+// Encoding defines a character encoding
+type Encoding interface {
+	// EncodeRune appends the encoded character to the data. The returned bool is false if the character cannot be represented by the encoding.
+	EncodeRune(data []byte, r rune) ([]byte, bool)
+	// DecodeRune decodes the first character of the data and returns it with its size in bytes. The returned bool is false if the data does not start with a valid character, the size is then the count of the invalid bytes.
+	DecodeRune(data []byte) (rune, int, bool)
+	// Replacement returns the character which replaces invalid and unrepresentable characters
+	Replacement() rune
+	// Invalid returns a byte sequence which is not valid for the encoding or nil if every byte sequence is valid
+	Invalid() []byte
+}
+
+var encodingLookup = make(map[string]Encoding)
+
+// New returns a character encoding given its registered name.
+// The error return argument is not nil, if the name does not exist in the registered encoding list.
+func New(name string) (Encoding, error) {
+	enc, ok := encodingLookup[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown encoding %q", name)
+	}
+
+	return enc, nil
+}
+
+// List returns a list of all registered encoding names.
+func List() []string {
+	keyEncodingLookup := make([]string, 0, len(encodingLookup))
+
+	for key := range encodingLookup {
+		keyEncodingLookup = append(keyEncodingLookup, key)
+	}
+
+	sort.Strings(keyEncodingLookup)
+
+	return keyEncodingLookup
+}
+
+// Register registers a character encoding with the given name.
+func Register(name string, enc Encoding) {
+	if enc == nil {
+		panic("register encoding is nil")
+	}
+
+	if _, ok := encodingLookup[name]; ok {
+		panic("encoding " + name + " already registered")
+	}
+
+	encodingLookup[name] = enc
+}
+
+// Encode transcodes UTF-8 text into the given encoding.
+// Invalid UTF-8 sequences and characters which cannot be represented by the encoding are handled according to the error mode. The error return argument is only set with ErrorModeReport.
+func Encode(enc Encoding, data []byte, mode ErrorMode) ([]byte, error) {
+	var err error
+	out := make([]byte, 0, len(data))
+
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+
+		if r == utf8.RuneError && size <= 1 {
+			if mode == ErrorModeReport && err == nil {
+				err = &Error{
+					Message: fmt.Sprintf("invalid UTF-8 byte 0x%02X at offset %d", data[i], i),
+					Type:    ErrInvalidCharacter,
+					Offset:  i,
+				}
+			}
+		} else {
+			var ok bool
+			if out, ok = enc.EncodeRune(out, r); ok {
+				i += size
+
+				continue
+			}
+
+			if mode == ErrorModeReport && err == nil {
+				err = &Error{
+					Message: fmt.Sprintf("character %q at offset %d cannot be represented", r, i),
+					Type:    ErrUnrepresentableCharacter,
+					Offset:  i,
+				}
+			}
+		}
+
+		if size == 0 {
+			size = 1
+		}
+
+		if mode == ErrorModeRaw {
+			out = append(out, data[i:i+size]...)
+		} else {
+			out, _ = enc.EncodeRune(out, enc.Replacement())
+		}
+
+		i += size
+	}
+
+	return out, err
+}
+
+// Decode transcodes data of the given encoding into UTF-8 text.
+// Invalid byte sequences are handled according to the error mode. The offsets return argument holds for every byte of the text the offset of its character in the data and additionally the length of the data. With ErrorModeReport the decoding stops at the first invalid byte sequence which is reported as error.
+func Decode(enc Encoding, data []byte, mode ErrorMode) ([]byte, []int, error) {
+	out := make([]byte, 0, len(data))
+	offsets := make([]int, 0, len(data)+1)
+
+	var buf [utf8.UTFMax]byte
+
+	for i := 0; i < len(data); {
+		r, size, ok := enc.DecodeRune(data[i:])
+
+		var c []byte
+
+		switch {
+		case ok:
+			c = buf[:utf8.EncodeRune(buf[:], r)]
+		case mode == ErrorModeReport:
+			return out, append(offsets, i), &Error{
+				Message: fmt.Sprintf("invalid byte sequence %q at offset %d", data[i:i+size], i),
+				Type:    ErrInvalidCharacter,
+				Offset:  i,
+			}
+		case mode == ErrorModeRaw:
+			c = data[i : i+size]
+		default:
+			c = buf[:utf8.EncodeRune(buf[:], utf8.RuneError)]
+		}
+
+		for range c {
+			offsets = append(offsets, i)
+		}
+		out = append(out, c...)
+
+		i += size
+	}
+
+	return out, append(offsets, len(data)), nil
+}
@@ -0,0 +1,105 @@
+package encoding
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+)
+
+func TestEncodingRegistry(t *testing.T) {
+	Equal(t, []string{"latin1", "shiftjis", "utf16be", "utf16le", "utf8"}, List())
+
+	_, err := New("ebcdic")
+	NotNil(t, err)
+
+	Panics(t, func() {
+		Register("utf8", utf8Encoding{})
+	})
+}
+
+func TestEncodings(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		text string
+		data string
+	}{
+		{"utf8", "a€😃", "a€😃"},
+		{"utf16le", "a€😃", "\x61\x00\xac\x20\x3d\xd8\x03\xde"},
+		{"utf16be", "a€😃", "\x00\x61\x20\xac\xd8\x3d\xde\x03"},
+		{"latin1", "Grüße", "\x47\x72\xfc\xdf\x65"},
+		{"shiftjis", "日本語テスト、ｱｲ", "\x93\xfa\x96\x7b\x8c\xea\x83\x65\x83\x58\x83\x67\x81\x41\xb1\xb2"},
+	} {
+		enc, err := New(tc.name)
+		Nil(t, err)
+
+		data, err := Encode(enc, []byte(tc.text), ErrorModeReport)
+		Nil(t, err, tc.name)
+		Equal(t, tc.data, string(data), tc.name)
+
+		text, offsets, err := Decode(enc, data, ErrorModeReport)
+		Nil(t, err, tc.name)
+		Equal(t, tc.text, string(text), tc.name)
+		Equal(t, len(text)+1, len(offsets), tc.name)
+		Equal(t, len(data), offsets[len(offsets)-1], tc.name)
+	}
+}
+
+func TestEncodingErrors(t *testing.T) {
+	latin1, _ := New("latin1")
+
+	// unrepresentable characters
+	data, err := Encode(latin1, []byte("a€b"), ErrorModeReport)
+	Equal(t, ErrUnrepresentableCharacter, err.(*Error).Type)
+	Equal(t, 1, err.(*Error).Offset)
+	Equal(t, "a?b", string(data))
+
+	data, err = Encode(latin1, []byte("a€b"), ErrorModeReplace)
+	Nil(t, err)
+	Equal(t, "a?b", string(data))
+
+	data, err = Encode(latin1, []byte("a€b"), ErrorModeRaw)
+	Nil(t, err)
+	Equal(t, "a€b", string(data))
+
+	// invalid UTF-8 can be injected on purpose
+	utf16le, _ := New("utf16le")
+
+	data, err = Encode(utf16le, []byte("a\xffb"), ErrorModeReport)
+	Equal(t, ErrInvalidCharacter, err.(*Error).Type)
+	Equal(t, "a\x00\xfd\xffb\x00", string(data))
+
+	data, err = Encode(utf16le, []byte("a\xffb"), ErrorModeRaw)
+	Nil(t, err)
+	Equal(t, "a\x00\xffb\x00", string(data))
+
+	// invalid byte sequences
+	text, offsets, err := Decode(utf16le, []byte("a\x00\x00\xd8b\x00"), ErrorModeReport)
+	Equal(t, ErrInvalidCharacter, err.(*Error).Type)
+	Equal(t, 2, err.(*Error).Offset)
+	Equal(t, "a", string(text))
+	Equal(t, []int{0, 2}, offsets)
+
+	text, offsets, err = Decode(utf16le, []byte("a\x00\x00\xd8b\x00"), ErrorModeReplace)
+	Nil(t, err)
+	Equal(t, "a�b", string(text))
+	Equal(t, []int{0, 2, 2, 2, 4, 6}, offsets)
+
+	text, _, err = Decode(utf16le, []byte("a\x00\x00\xd8b\x00"), ErrorModeRaw)
+	Nil(t, err)
+	Equal(t, "a\x00\xd8b", string(text))
+
+	// odd count of bytes
+	_, _, err = Decode(utf16le, []byte("a\x00b"), ErrorModeReport)
+	Equal(t, 2, err.(*Error).Offset)
+}
+
+func TestEncodingInvalid(t *testing.T) {
+	for _, name := range List() {
+		enc, _ := New(name)
+
+		if invalid := enc.Invalid(); invalid != nil {
+			_, _, err := Decode(enc, invalid, ErrorModeReport)
+			NotNil(t, err, name)
+		}
+	}
+}
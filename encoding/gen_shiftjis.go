@@ -0,0 +1,130 @@
+//go:build ignore
+// +build ignore
+
+// gen_shiftjis generates the JIS X 0208 table of the Shift-JIS encoding out of the JIS X 0208 mapping of the Unicode Consortium.
+// Usage: go run gen_shiftjis.go [-input <mapping file>] [-output <Go file>]
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	rows  = 94
+	cells = 94
+)
+
+// mappingURL is the mapping of JIS X 0208 characters to Unicode which is used if no input file is given
+const mappingURL = "https://www.unicode.org/Public/MAPPINGS/OBSOLETE/EASTASIA/JIS/JIS0208.TXT"
+
+func main() {
+	input := flag.String("input", "", "Mapping file of JIS X 0208 to Unicode, the mapping is downloaded if not set")
+	output := flag.String("output", "shiftjis_table.go", "Go file of the generated table")
+
+	flag.Parse()
+
+	if err := generate(*input, *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		os.Exit(1)
+	}
+}
+
+func generate(input string, output string) error {
+	var mapping io.Reader
+
+	if input == "" {
+		resp, err := http.Get(mappingURL)
+		if err != nil {
+			return fmt.Errorf("cannot download mapping: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("cannot download mapping: %s", resp.Status)
+		}
+
+		mapping = resp.Body
+	} else {
+		f, err := os.Open(input)
+		if err != nil {
+			return fmt.Errorf("cannot open mapping: %v", err)
+		}
+		defer f.Close()
+
+		mapping = f
+	}
+
+	table, err := parseMapping(mapping)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("// Code generated by gen_shiftjis.go. DO NOT EDIT.\n\n")
+	buf.WriteString("package encoding\n\n")
+	buf.WriteString("// jis0208 maps the index row*94+cell of a JIS X 0208 character to its Unicode code point. Unassigned characters are 0.\n")
+	fmt.Fprintf(&buf, "var jis0208 = [%d]uint16{\n", len(table))
+
+	for i := 0; i < len(table); i += 12 {
+		var values []string
+		for j := i; j < i+12 && j < len(table); j++ {
+			values = append(values, fmt.Sprintf("0x%04X,", table[j]))
+		}
+
+		buf.WriteString("\t" + strings.Join(values, " ") + "\n")
+	}
+
+	buf.WriteString("}\n")
+
+	return ioutil.WriteFile(output, buf.Bytes(), 0644)
+}
+
+// parseMapping parses the lines of the mapping which consist of the Shift-JIS code, the JIS X 0208 code and the Unicode code point in hex followed by a comment
+func parseMapping(mapping io.Reader) ([]uint16, error) {
+	table := make([]uint16, rows*cells)
+
+	scanner := bufio.NewScanner(mapping)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, '#'); i != -1 {
+			text = text[:i]
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		} else if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d of mapping has %d instead of 3 fields", line, len(fields))
+		}
+
+		jis, err := strconv.ParseUint(fields[1], 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("line %d of mapping has an invalid JIS X 0208 code: %v", line, err)
+		}
+		r, err := strconv.ParseUint(fields[2], 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("line %d of mapping has an invalid code point: %v", line, err)
+		}
+
+		// rows and cells of JIS X 0208 codes begin at 0x21
+		row, cell := int(jis>>8)-0x21, int(jis&0xFF)-0x21
+		if row < 0 || row >= rows || cell < 0 || cell >= cells {
+			return nil, fmt.Errorf("line %d of mapping has the JIS X 0208 code %s which is out of range", line, fields[1])
+		}
+
+		table[row*cells+cell] = uint16(r)
+	}
+
+	return table, scanner.Err()
+}
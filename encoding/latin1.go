@@ -0,0 +1,29 @@
+package encoding
+
+func init() {
+	Register("latin1", latin1Encoding{})
+}
+
+// latin1Encoding implements the ISO 8859-1 encoding which maps every byte to the Unicode character with the same code point
+type latin1Encoding struct{}
+
+func (latin1Encoding) EncodeRune(data []byte, r rune) ([]byte, bool) {
+	if r > 0xFF {
+		return data, false
+	}
+
+	return append(data, byte(r)), true
+}
+
+func (latin1Encoding) DecodeRune(data []byte) (rune, int, bool) {
+	return rune(data[0]), 1, true
+}
+
+func (latin1Encoding) Replacement() rune {
+	return '?'
+}
+
+func (latin1Encoding) Invalid() []byte {
+	// every byte is a valid character
+	return nil
+}
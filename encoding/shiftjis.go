@@ -0,0 +1,118 @@
+package encoding
+
+//go:generate go run gen_shiftjis.go -output shiftjis_table.go
+
+func init() {
+	Register("shiftjis", newShiftJISEncoding())
+}
+
+// shiftJISEncoding implements the Shift-JIS encoding with ASCII, the half-width katakana and the characters of JIS X 0208
+type shiftJISEncoding struct {
+	lookup map[rune]uint16
+}
+
+func newShiftJISEncoding() *shiftJISEncoding {
+	e := &shiftJISEncoding{
+		lookup: make(map[rune]uint16),
+	}
+
+	for i, r := range jis0208 {
+		if r != 0 {
+			if _, ok := e.lookup[rune(r)]; !ok {
+				e.lookup[rune(r)] = uint16(i)
+			}
+		}
+	}
+
+	return e
+}
+
+func (e *shiftJISEncoding) EncodeRune(data []byte, r rune) ([]byte, bool) {
+	switch {
+	case r < 0x80:
+		return append(data, byte(r)), true
+	case r >= 0xFF61 && r <= 0xFF9F:
+		return append(data, byte(r-0xFF61+0xA1)), true
+	}
+
+	i, ok := e.lookup[r]
+	if !ok {
+		return data, false
+	}
+
+	row, cell := int(i)/94, int(i)%94
+
+	var lead, trail int
+
+	if row < 62 {
+		lead = 0x81 + row/2
+	} else {
+		lead = 0xE0 + (row-62)/2
+	}
+
+	if row%2 == 0 {
+		trail = 0x40 + cell
+		if trail >= 0x7F {
+			trail++
+		}
+	} else {
+		trail = 0x9F + cell
+	}
+
+	return append(data, byte(lead), byte(trail)), true
+}
+
+func (e *shiftJISEncoding) DecodeRune(data []byte) (rune, int, bool) {
+	lead := int(data[0])
+
+	switch {
+	case lead < 0x80:
+		return rune(lead), 1, true
+	case lead >= 0xA1 && lead <= 0xDF:
+		return rune(lead - 0xA1 + 0xFF61), 1, true
+	case !(lead >= 0x81 && lead <= 0x9F) && !(lead >= 0xE0 && lead <= 0xEF):
+		return 0xFFFD, 1, false
+	}
+
+	if len(data) < 2 {
+		return 0xFFFD, 1, false
+	}
+
+	trail := int(data[1])
+
+	var row, cell int
+
+	if lead <= 0x9F {
+		row = (lead - 0x81) * 2
+	} else {
+		row = 62 + (lead-0xE0)*2
+	}
+
+	switch {
+	case trail >= 0x40 && trail <= 0x7E:
+		cell = trail - 0x40
+	case trail >= 0x80 && trail <= 0x9E:
+		cell = trail - 0x41
+	case trail >= 0x9F && trail <= 0xFC:
+		row++
+		cell = trail - 0x9F
+	default:
+		return 0xFFFD, 1, false
+	}
+
+	r := jis0208[row*94+cell]
+	if r == 0 {
+		return 0xFFFD, 2, false
+	}
+
+	return rune(r), 2, true
+}
+
+func (e *shiftJISEncoding) Replacement() rune {
+	return '?'
+}
+
+func (e *shiftJISEncoding) Invalid() []byte {
+	// a lead byte with a trail byte which is out of range
+	return []byte{0x81, 0x7F}
+}
@@ -0,0 +1,44 @@
+package encoding
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+)
+
+func TestShiftJISEncoding(t *testing.T) {
+	enc, _ := New("shiftjis")
+
+	// every character of the table has to survive a round trip
+	for i, r := range jis0208 {
+		if r == 0 {
+			continue
+		}
+
+		data, ok := enc.EncodeRune(nil, rune(r))
+		True(t, ok, i)
+		Equal(t, 2, len(data), i)
+
+		d, size, ok := enc.DecodeRune(data)
+		True(t, ok, i)
+		Equal(t, 2, size)
+		Equal(t, rune(r), d)
+	}
+
+	// half-width katakana
+	data, ok := enc.EncodeRune(nil, 'ｦ')
+	True(t, ok)
+	Equal(t, []byte{0xA6}, data)
+
+	// unassigned and invalid characters
+	_, ok = enc.EncodeRune(nil, '€')
+	False(t, ok)
+
+	_, size, ok := enc.DecodeRune([]byte{0x80})
+	False(t, ok)
+	Equal(t, 1, size)
+
+	_, size, ok = enc.DecodeRune([]byte{0x85, 0x40})
+	False(t, ok)
+	Equal(t, 2, size)
+}
@@ -0,0 +1,95 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+func init() {
+	Register("utf8", utf8Encoding{})
+	Register("utf16le", utf16Encoding{order: binary.LittleEndian})
+	Register("utf16be", utf16Encoding{order: binary.BigEndian})
+}
+
+// utf8Encoding implements the UTF-8 encoding which only checks the validity of the data
+type utf8Encoding struct{}
+
+func (utf8Encoding) EncodeRune(data []byte, r rune) ([]byte, bool) {
+	var buf [utf8.UTFMax]byte
+
+	return append(data, buf[:utf8.EncodeRune(buf[:], r)]...), true
+}
+
+func (utf8Encoding) DecodeRune(data []byte) (rune, int, bool) {
+	r, size := utf8.DecodeRune(data)
+
+	return r, size, r != utf8.RuneError || size > 1
+}
+
+func (utf8Encoding) Replacement() rune {
+	return utf8.RuneError
+}
+
+func (utf8Encoding) Invalid() []byte {
+	return []byte{0xFF}
+}
+
+// utf16Encoding implements the UTF-16 encoding with the given byte order
+type utf16Encoding struct {
+	order binary.ByteOrder
+}
+
+func (e utf16Encoding) EncodeRune(data []byte, r rune) ([]byte, bool) {
+	var buf [2]byte
+
+	if r >= 0x10000 {
+		r1, r2 := utf16.EncodeRune(r)
+
+		e.order.PutUint16(buf[:], uint16(r1))
+		data = append(data, buf[:]...)
+
+		r = r2
+	} else if utf16.IsSurrogate(r) {
+		return data, false
+	}
+
+	e.order.PutUint16(buf[:], uint16(r))
+
+	return append(data, buf[:]...), true
+}
+
+func (e utf16Encoding) DecodeRune(data []byte) (rune, int, bool) {
+	if len(data) < 2 {
+		return utf8.RuneError, len(data), false
+	}
+
+	r1 := rune(e.order.Uint16(data))
+	if !utf16.IsSurrogate(r1) {
+		return r1, 2, true
+	}
+
+	if len(data) < 4 {
+		return utf8.RuneError, 2, false
+	}
+
+	r := utf16.DecodeRune(r1, rune(e.order.Uint16(data[2:])))
+	if r == utf8.RuneError {
+		return utf8.RuneError, 2, false
+	}
+
+	return r, 4, true
+}
+
+func (utf16Encoding) Replacement() rune {
+	return utf8.RuneError
+}
+
+func (e utf16Encoding) Invalid() []byte {
+	// a high surrogate without a low surrogate
+	var buf [2]byte
+
+	e.order.PutUint16(buf[:], 0xD800)
+
+	return buf[:]
+}
@@ -0,0 +1,24 @@
+package filter
+
+import (
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/expressions"
+)
+
+func init() {
+	Register("ComputedValueCorruption", NewComputedValueCorruption)
+}
+
+// NewComputedValueCorruption implements a fuzzing filter which deliberately corrupts computed values.
+// This filter searches the token graph for tokens which compute their value from other tokens, like lengths and checksums, and replaces them with tokens which can additionally hold a wrong value. The wrong value of an integer is the correct value incremented by one, the wrong value of a digest is the correct digest with the bits of its first byte flipped. This filter can be used to test if a program detects and handles length fields and checksums which do not match their data.
+func NewComputedValueCorruption(tok token.Token) (token.Token, error) {
+	t, ok := tok.(*expressions.ComputedValue)
+	if !ok || t.Corruptible() {
+		return nil, nil
+	}
+
+	c := t.Clone().(*expressions.ComputedValue)
+	c.SetCorruptible(true)
+
+	return c, nil
+}
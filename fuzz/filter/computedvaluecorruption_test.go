@@ -0,0 +1,39 @@
+package filter
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/token/expressions"
+	"github.com/zimmski/tavor/token/lists"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func TestComputedValueCorruptionFilter(t *testing.T) {
+	payload := primitives.NewConstantString("hello")
+
+	l, err := expressions.NewComputedValue("len", "", payload)
+	Nil(t, err)
+
+	root := lists.NewConcatenation(l, payload)
+
+	rootNew, err := ApplyFilters([]Filter{NewComputedValueCorruption}, root)
+	Nil(t, err)
+	Equal(t, "5hello", rootNew.String())
+	Equal(t, 2, rootNew.PermutationsAll())
+
+	c, _ := rootNew.(*lists.Concatenation).Get(0)
+	Nil(t, c.Permutation(1))
+	Equal(t, "6hello", rootNew.String())
+
+	// already corruptible tokens are not replaced
+	replacement, err := NewComputedValueCorruption(c)
+	Nil(t, err)
+	Nil(t, replacement)
+
+	// other tokens are not replaced
+	replacement, err = NewComputedValueCorruption(payload)
+	Nil(t, err)
+	Nil(t, replacement)
+}
@@ -0,0 +1,27 @@
+package filter
+
+import (
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func init() {
+	Register("EncodingInjection", NewEncodingInjection)
+}
+
+// NewEncodingInjection implements a fuzzing filter which deliberately injects invalid byte sequences into encoded text.
+// This filter searches the token graph for tokens which transcode their text into a character encoding, and replaces them with tokens which can additionally hold a byte sequence that is not valid for the encoding in front of their text. Encodings for which every byte sequence is valid, like Latin-1, are not affected. This filter can be used to test if a program detects and handles malformed UTF-8, unpaired UTF-16 surrogates and similar invalid data.
+func NewEncodingInjection(tok token.Token) (token.Token, error) {
+	t, ok := tok.(*primitives.Encoded)
+	if !ok || t.Injectable() {
+		return nil, nil
+	}
+
+	c := t.Clone().(*primitives.Encoded)
+	c.SetInjectable(true)
+	if !c.Injectable() {
+		return nil, nil
+	}
+
+	return c, nil
+}
@@ -0,0 +1,45 @@
+package filter
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/encoding"
+	"github.com/zimmski/tavor/token/lists"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func TestEncodingInjectionFilter(t *testing.T) {
+	e, err := primitives.NewEncoded(primitives.NewConstantString("hello"), "utf8", encoding.ErrorModeReport)
+	Nil(t, err)
+
+	root := lists.NewConcatenation(e, primitives.NewConstantString("!"))
+
+	rootNew, err := ApplyFilters([]Filter{NewEncodingInjection}, root)
+	Nil(t, err)
+	Equal(t, "hello!", rootNew.String())
+	Equal(t, 2, rootNew.PermutationsAll())
+
+	c, _ := rootNew.(*lists.Concatenation).Get(0)
+	Nil(t, c.Permutation(1))
+	Equal(t, "\xffhello!", rootNew.String())
+
+	// already injectable tokens are not replaced
+	replacement, err := NewEncodingInjection(c)
+	Nil(t, err)
+	Nil(t, replacement)
+
+	// encodings without invalid byte sequences are not replaced
+	l, err := primitives.NewEncoded(primitives.NewConstantString("hello"), "latin1", encoding.ErrorModeReport)
+	Nil(t, err)
+
+	replacement, err = NewEncodingInjection(l)
+	Nil(t, err)
+	Nil(t, replacement)
+
+	// other tokens are not replaced
+	replacement, err = NewEncodingInjection(primitives.NewConstantString("hello"))
+	Nil(t, err)
+	Nil(t, replacement)
+}
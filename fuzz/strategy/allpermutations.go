@@ -1,6 +1,9 @@
 package strategy
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/zimmski/tavor/log"
 	"github.com/zimmski/tavor/rand"
 	"github.com/zimmski/tavor/token"
@@ -8,6 +11,7 @@ import (
 
 func init() {
 	Register("AllPermutations", NewAllPermutations)
+	RegisterResumable("AllPermutations", NewResumableAllPermutations)
 }
 
 type allPermutationsLevel struct {
@@ -17,15 +21,30 @@ type allPermutationsLevel struct {
 	children []allPermutationsLevel
 }
 
+// allPermutationsState holds the permutation of a token of the permutation tree and the states of its children
+type allPermutationsState struct {
+	Permutation uint                   `json:"permutation"`
+	Children    []allPermutationsState `json:"children,omitempty"`
+}
+
 type allPermutations struct {
 	root token.Token
+	tree []allPermutationsLevel
 }
 
 // NewAllPermutations implements a fuzzing strategy that generates all possible permutations of a token graph.
 // Every iteration of the strategy generates a new permutation. The generation is deterministic. Since this strategy really produces every possible permutation of a token graph, it is advised to only use the strategy on graphs with few states since the state explosion problem manifests itself quite fast.
 func NewAllPermutations(root token.Token, r rand.Rand) (chan struct{}, error) {
+	continueFuzzing, _, err := NewResumableAllPermutations(root, r, nil)
+
+	return continueFuzzing, err
+}
+
+// NewResumableAllPermutations implements the AllPermutations fuzzing strategy which can be resumed.
+// The state of the strategy holds the current permutation of every token of the permutation tree. A resumed strategy sets the permutations of the state and continues with the permutation which follows the state.
+func NewResumableAllPermutations(root token.Token, r rand.Rand, state []byte) (chan struct{}, func() ([]byte, error), error) {
 	if token.LoopExists(root) {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Message: "found endless loop in graph. Cannot proceed.",
 			Type:    ErrEndlessLoopDetected,
 		}
@@ -35,16 +54,30 @@ func NewAllPermutations(root token.Token, r rand.Rand) (chan struct{}, error) {
 		root: root,
 	}
 
+	s.tree = s.getTree(s.root, false)
+
+	if state != nil {
+		var states []allPermutationsState
+		if err := json.Unmarshal(state, &states); err != nil {
+			return nil, nil, &Error{
+				Message: fmt.Sprintf("cannot parse state: %v", err),
+				Type:    ErrInvalidState,
+			}
+		}
+
+		if err := s.restore(s.tree, states); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	continueFuzzing := make(chan struct{})
 
 	go func() {
 		log.Debug("start all permutations routine")
 
-		tree := s.getTree(s.root, false)
-
 		log.Debug("start fuzzing step")
 
-		if contin, _ := s.fuzz(continueFuzzing, tree, false); !contin {
+		if contin, _ := s.fuzz(continueFuzzing, s.tree, false, state != nil); !contin {
 			return
 		}
 
@@ -53,18 +86,75 @@ func NewAllPermutations(root token.Token, r rand.Rand) (chan struct{}, error) {
 		close(continueFuzzing)
 	}()
 
-	return continueFuzzing, nil
+	return continueFuzzing, s.state, nil
 }
 
-func (s *allPermutations) fuzz(continueFuzzing chan struct{}, tree []allPermutationsLevel, justastep bool) (bool, bool) {
+// state returns the permutations of the permutation tree
+func (s *allPermutations) state() ([]byte, error) {
+	var states func(tree []allPermutationsLevel) []allPermutationsState
+	states = func(tree []allPermutationsLevel) []allPermutationsState {
+		l := make([]allPermutationsState, len(tree))
+
+		for i := range tree {
+			l[i].Permutation = tree[i].permutation
+			l[i].Children = states(tree[i].children)
+		}
+
+		return l
+	}
+
+	return json.Marshal(states(s.tree))
+}
+
+// restore sets the permutations of the given states. Children of permuted tokens are permuted after their parents since they depend on the permutation of their parents.
+func (s *allPermutations) restore(tree []allPermutationsLevel, states []allPermutationsState) error {
+	if len(tree) != len(states) {
+		return &Error{
+			Message: fmt.Sprintf("state has %d instead of %d tokens on a level", len(states), len(tree)),
+			Type:    ErrInvalidState,
+		}
+	}
+
+	for i := range tree {
+		if p := states[i].Permutation; p > 0 {
+			if p >= tree[i].token.Permutations() {
+				return &Error{
+					Message: fmt.Sprintf("state has permutation %d of %#v which has only %d permutations", p, tree[i].token, tree[i].token.Permutations()),
+					Type:    ErrInvalidState,
+				}
+			}
+
+			tree[i].permutation = p
+			s.setPermutation(tree[i].token, p)
+			tree[i].children = s.getTree(tree[i].token, true)
+		}
+
+		if err := s.restore(tree[i].children, states[i].Children); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *allPermutations) fuzz(continueFuzzing chan struct{}, tree []allPermutationsLevel, justastep bool, resume bool) (bool, bool) {
 	log.Debugf("fuzzing level %d->%#v", len(tree), tree)
 
 STEP:
 	for {
-		if justastep && len(tree[0].children) > 0 {
+		if resume {
+			// the iteration of a restored state was generated by descending into the first token of every level
+			resume = false
+
+			if len(tree[0].children) > 0 {
+				if contin, _ := s.fuzz(continueFuzzing, tree[0].children, false, true); !contin {
+					return false, false
+				}
+			}
+		} else if justastep && len(tree[0].children) > 0 {
 			log.Debugf("STEP FURTHER INTO")
 
-			if contin, step := s.fuzz(continueFuzzing, tree[0].children, justastep); !contin {
+			if contin, step := s.fuzz(continueFuzzing, tree[0].children, justastep, false); !contin {
 				return false, false
 			} else if step {
 				log.Debugf("CONTINUE after child step")
@@ -82,7 +172,7 @@ STEP:
 			}
 
 			if len(tree[0].children) > 0 {
-				if contin, step := s.fuzz(continueFuzzing, tree[0].children, justastep); !contin {
+				if contin, step := s.fuzz(continueFuzzing, tree[0].children, justastep, false); !contin {
 					return false, false
 				} else if step {
 					log.Debugf("CONTINUE after child step")
@@ -117,7 +207,7 @@ STEP:
 				if len(tree[i].children) > 0 {
 					log.Debugf("CHECK children %#v", tree[i])
 
-					if contin, step := s.fuzz(continueFuzzing, tree[i].children, true); !contin {
+					if contin, step := s.fuzz(continueFuzzing, tree[i].children, true, false); !contin {
 						return false, false
 					} else if step {
 						for j := 0; j < i; j++ {
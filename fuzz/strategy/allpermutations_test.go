@@ -624,6 +624,63 @@ func validateTokenAllPermutations(t *testing.T, tok token.Token, expect []string
 	Equal(t, expect, got)
 }
 
+func TestAllPermutationsStrategyResume(t *testing.T) {
+	format := `
+		START = +1,2(1 | 2) ?("x" (3 | 4)) Inner
+		Inner = "a" | "b" ?("c")
+	`
+
+	generate := func(state []byte, stop int) ([]string, []byte) {
+		o, err := parser.ParseTavor(strings.NewReader(format))
+		Nil(t, err)
+
+		ch, position, err := NewResumableAllPermutations(o, test.NewRandTest(1), state)
+		Nil(t, err)
+
+		var got []string
+		for i := range ch {
+			got = append(got, o.String())
+
+			if len(got) == stop {
+				state, err := position()
+				Nil(t, err)
+
+				close(ch)
+
+				return got, state
+			}
+
+			ch <- i
+		}
+
+		return got, nil
+	}
+
+	all, _ := generate(nil, 0)
+	Equal(t, 54, len(all))
+
+	// resuming after every generation continues with the following generations
+	for i := 1; i < len(all); i++ {
+		first, state := generate(nil, i)
+		Equal(t, all[:i], first)
+
+		rest, _ := generate(state, 0)
+		Equal(t, all[i:], rest, i)
+	}
+
+	// the state has to fit the token graph
+	o := primitives.NewConstantInt(1)
+
+	_, _, err := NewResumableAllPermutations(o, test.NewRandTest(1), []byte(`[{"permutation":1}]`))
+	Equal(t, ErrInvalidState, err.(*Error).Type)
+
+	_, _, err = NewResumableAllPermutations(o, test.NewRandTest(1), []byte(`[{},{}]`))
+	Equal(t, ErrInvalidState, err.(*Error).Type)
+
+	_, _, err = NewResumableAllPermutations(o, test.NewRandTest(1), []byte(`{`))
+	Equal(t, ErrInvalidState, err.(*Error).Type)
+}
+
 func TestAllPermutationsStrategyLoopDetection(t *testing.T) {
 	testStrategyLoopDetection(t, NewAllPermutations)
 }
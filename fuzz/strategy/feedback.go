@@ -0,0 +1,180 @@
+package strategy
+
+import (
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/rand"
+	"github.com/zimmski/tavor/token"
+)
+
+func init() {
+	RegisterFeedback("FeedbackRandom", NewFeedbackRandom)
+}
+
+type feedbackDecision struct {
+	path        string
+	permutation uint
+}
+
+type feedbackScore struct {
+	permutation uint
+	score       int64
+}
+
+type feedback struct {
+	random
+
+	r rand.Rand
+
+	// decisions holds the permutation decisions of the current iteration
+	decisions []feedbackDecision
+	// scores holds for every token path the accumulated scores of its permutations in order of their first reward
+	scores map[string][]feedbackScore
+}
+
+// NewFeedbackRandom implements a feedback fuzzing strategy that generates random permutations of a token graph which are biased towards permutations that resulted in new behaviour.
+// Every iteration permutates at random all reachable tokens in the graph like the random strategy does. Each permutation decision is remembered by the path of the token in the graph. The score of an iteration, for example the amount of newly covered edges of the tested program, is then added to all decisions of the iteration. Following iterations choose with a probability of one half between the rewarded permutations of a token, weighted by their scores, and otherwise uniformly between all permutations of the token. The strategy iterates until the feedback channel is closed. The determinism is dependent on the random generator and the given feedback.
+func NewFeedbackRandom(root token.Token, r rand.Rand) (chan struct{}, chan<- FeedbackScore, error) {
+	if r == nil {
+		return nil, nil, &Error{
+			Message: "random generator is nil",
+			Type:    ErrNilRandomGenerator,
+		}
+	}
+
+	if token.LoopExists(root) {
+		return nil, nil, &Error{
+			Message: "found endless loop in graph. Cannot proceed.",
+			Type:    ErrEndlessLoopDetected,
+		}
+	}
+
+	s := &feedback{
+		random: random{
+			root: root,
+		},
+
+		r: r,
+
+		scores: make(map[string][]feedbackScore),
+	}
+
+	continueFuzzing := make(chan struct{})
+	feedbackFuzzing := make(chan FeedbackScore)
+
+	go func() {
+		log.Debug("start feedback fuzzing routine")
+
+		for {
+			s.decisions = s.decisions[:0]
+
+			permutateGraph(s.root, "", token.NewVariableScope(), s.choose)
+
+			s.fuzzYADDA(s.root, r)
+
+			log.Debug("done with fuzzing step")
+
+			continueFuzzing <- struct{}{}
+
+			score, ok := <-feedbackFuzzing
+			if !ok {
+				break
+			}
+
+			s.learn(score)
+
+			if _, ok := <-continueFuzzing; !ok {
+				log.Debug("fuzzing channel closed from outside")
+
+				return
+			}
+		}
+
+		log.Debug("feedback channel closed from outside, close fuzzing channel")
+
+		close(continueFuzzing)
+	}()
+
+	return continueFuzzing, feedbackFuzzing, nil
+}
+
+func (s *feedback) learn(score FeedbackScore) {
+	if score <= 0 {
+		return
+	}
+
+	log.Debugf("Reward %d decisions with score %d", len(s.decisions), score)
+
+	for _, d := range s.decisions {
+		scores := s.scores[d.path]
+
+		found := false
+		for i := range scores {
+			if scores[i].permutation == d.permutation {
+				scores[i].score += int64(score)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			scores = append(scores, feedbackScore{
+				permutation: d.permutation,
+				score:       int64(score),
+			})
+		}
+
+		s.scores[d.path] = scores
+	}
+}
+
+// choose returns the permutation of the token and remembers it as decision of the current iteration
+func (s *feedback) choose(tok token.Token, path string) (uint, bool) {
+	p := tok.Permutations()
+	if p == 0 {
+		return 0, false
+	}
+
+	rp := s.chooseRewarded(tok, path, p)
+
+	if p > 1 {
+		s.decisions = append(s.decisions, feedbackDecision{
+			path:        path,
+			permutation: rp,
+		})
+	}
+
+	return rp, true
+}
+
+// chooseRewarded returns with a probability of one half one of the rewarded permutations of the path weighted by their scores, and otherwise a random permutation of the token
+func (s *feedback) chooseRewarded(tok token.Token, path string, permutations uint) uint {
+	if scores, ok := s.scores[path]; ok && s.r.Intn(2) == 0 {
+		var sum int64
+		for _, c := range scores {
+			if c.permutation < permutations {
+				sum += c.score
+			}
+		}
+
+		if sum > 0 {
+			n := s.r.Int63n(sum)
+
+			for _, c := range scores {
+				if c.permutation >= permutations {
+					continue
+				}
+
+				if n < c.score {
+					return c.permutation
+				}
+
+				n -= c.score
+			}
+		}
+	}
+
+	rp, _ := randomPermutation(tok, s.r)
+
+	return rp
+}
@@ -0,0 +1,74 @@
+package strategy
+
+import (
+	"math/rand"
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	tavorRand "github.com/zimmski/tavor/rand"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/lists"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func TestFeedbackRandomStrategyNilRandomGenerator(t *testing.T) {
+	ch, feedback, err := NewFeedbackRandom(nil, nil)
+	Nil(t, ch)
+	Nil(t, feedback)
+	Equal(t, ErrNilRandomGenerator, err.(*Error).Type)
+}
+
+func TestFeedbackRandomStrategy(t *testing.T) {
+	var alternatives []token.Token
+	for i := 0; i < 10; i++ {
+		alternatives = append(alternatives, primitives.NewConstantInt(i))
+	}
+
+	o := lists.NewConcatenation(
+		lists.NewOne(alternatives...),
+		primitives.NewRangeInt(0, 100),
+	)
+
+	ch, feedback, err := NewFeedbackRandom(o, rand.New(rand.NewSource(1)))
+	Nil(t, err)
+
+	hits := 0
+
+	for i := 0; i < 400; i++ {
+		_, ok := <-ch
+		True(t, ok)
+
+		var score FeedbackScore
+
+		if o.String()[0] == '7' {
+			score = 1
+
+			if i >= 200 {
+				hits++
+			}
+		}
+
+		feedback <- score
+		ch <- struct{}{}
+	}
+
+	// a uniform distribution would give us around 20 hits
+	True(t, hits > 60, "only %d hits", hits)
+
+	_, ok := <-ch
+	True(t, ok)
+
+	close(feedback)
+
+	_, ok = <-ch
+	False(t, ok)
+}
+
+func TestFeedbackRandomStrategyLoopDetection(t *testing.T) {
+	testStrategyLoopDetection(t, func(root token.Token, r tavorRand.Rand) (chan struct{}, error) {
+		ch, _, err := NewFeedbackRandom(root, r)
+
+		return ch, err
+	})
+}
@@ -0,0 +1,19 @@
+package strategy
+
+import (
+	"github.com/zimmski/tavor/token"
+)
+
+// Generation holds the rendered string of one fuzzing iteration
+type Generation struct {
+	// Data is the generation of the iteration
+	Data string
+}
+
+// NewGeneration renders the current iteration of the given token graph.
+// A fuzzing strategy does not modify the token graph after it signaled a complete iteration until the iteration flow is continued, the generation has to be rendered in between. The rendered generation can then be used concurrently to the next iterations of the strategy. It does not hold a copy of the token graph since clones of tokens referencing other tokens, like variables and token attributes, can still reference the original graph. A token graph of a generation has to be parsed from its data instead.
+func NewGeneration(root token.Token) *Generation {
+	return &Generation{
+		Data: root.String(),
+	}
+}
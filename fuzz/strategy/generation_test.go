@@ -0,0 +1,41 @@
+package strategy
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/test"
+	"github.com/zimmski/tavor/token/lists"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func TestGeneration(t *testing.T) {
+	root := lists.NewOne(
+		primitives.NewConstantInt(1),
+		primitives.NewConstantInt(2),
+		primitives.NewConstantInt(3),
+	)
+
+	strat, err := New("AllPermutations")
+	Nil(t, err)
+
+	ch, err := strat(root, test.NewRandTest(1))
+	Nil(t, err)
+
+	var generations []*Generation
+
+	for i := range ch {
+		generations = append(generations, NewGeneration(root))
+
+		ch <- i
+	}
+
+	Equal(t, 3, len(generations))
+
+	for i, generation := range generations {
+		expected := []string{"1", "2", "3"}[i]
+
+		Equal(t, expected, generation.Data)
+	}
+}
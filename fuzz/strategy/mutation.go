@@ -0,0 +1,262 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/rand"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/lists"
+)
+
+func init() {
+	Register("Mutation", NewMutation)
+}
+
+type mutationType int
+
+const (
+	mutationPermute mutationType = iota
+	mutationSwap
+	mutationDuplicate
+	mutationRemove
+)
+
+type mutation struct {
+	index int
+	typ   mutationType
+}
+
+type mutationStrategy struct {
+	random
+
+	seed string
+}
+
+// NewMutation implements a fuzzing strategy that mutates the current generation of a token graph structurally.
+// The strategy is meant to mutate existing data, which is loaded into the token graph beforehand e.g. by parsing the data with the ParseInternal function of the parser package. Every iteration applies exactly one mutation to one token while the rest of the generation is kept intact. Mutations are re-permutating a single token without its children, swapping the chosen alternative of a One token, as well as duplicating or removing an item of a Repeat token within its repeat range. The newly chosen alternative of a swap is permutated at random. Every possible mutation is applied once in a random order. Before each iteration the original generation is restored by parsing it again, the generation must therefore be parsable. The determinism is dependent on the random generator.
+func NewMutation(root token.Token, r rand.Rand) (chan struct{}, error) {
+	if r == nil {
+		return nil, &Error{
+			Message: "random generator is nil",
+			Type:    ErrNilRandomGenerator,
+		}
+	}
+
+	if token.LoopExists(root) {
+		return nil, &Error{
+			Message: "found endless loop in graph. Cannot proceed.",
+			Type:    ErrEndlessLoopDetected,
+		}
+	}
+
+	s := &mutationStrategy{
+		random: random{
+			root: root,
+		},
+
+		seed: root.String(),
+	}
+
+	if err := s.restore(); err != nil {
+		return nil, &Error{
+			Message: fmt.Sprintf("cannot parse the current generation: %v", err),
+			Type:    ErrUnparsableGeneration,
+		}
+	}
+
+	continueFuzzing := make(chan struct{})
+
+	go func() {
+		log.Debug("start mutation routine")
+
+		mutations := s.mutations(s.tokens())
+
+		// shuffle the mutations so that different seeds of the random generator apply them in different orders
+		for i := len(mutations) - 1; i > 0; i-- {
+			j := r.Intn(i + 1)
+			mutations[i], mutations[j] = mutations[j], mutations[i]
+		}
+
+		for i, m := range mutations {
+			if i > 0 {
+				if err := s.restore(); err != nil {
+					log.Panic(err)
+				}
+			}
+
+			if !s.mutate(s.tokens()[m.index], m.typ, r) {
+				continue
+			}
+
+			token.ResetScope(s.root)
+			_ = token.ResetResetTokens(s.root)
+			token.ResetScope(s.root)
+
+			log.Debug("done with fuzzing step")
+
+			continueFuzzing <- struct{}{}
+
+			if _, ok := <-continueFuzzing; !ok {
+				log.Debug("fuzzing channel closed from outside")
+
+				return
+			}
+		}
+
+		log.Debug("finished fuzzing")
+
+		close(continueFuzzing)
+	}()
+
+	return continueFuzzing, nil
+}
+
+// restore parses the original generation into the token graph
+func (s *mutationStrategy) restore() (err error) {
+	// not all tokens implement parsing yet
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	p := &token.InternalParser{
+		Data:    []byte(s.seed),
+		DataLen: len(s.seed),
+	}
+
+	nex, errs := s.root.Parse(p, 0)
+	if len(errs) > 0 {
+		return errs[0]
+	} else if nex != p.DataLen {
+		return fmt.Errorf("expected EOF but %d characters are left", p.DataLen-nex)
+	}
+
+	// the fuzz strategy package cannot use the parser package, hence the same checks as ParseInternal are done here
+	return token.VerifyTokens(s.root)
+}
+
+// tokens returns all reachable tokens of the current generation in a deterministic order
+func (s *mutationStrategy) tokens() []token.Token {
+	var toks []token.Token
+
+	var walk func(tok token.Token)
+	walk = func(tok token.Token) {
+		toks = append(toks, tok)
+
+		if t, ok := tok.(token.Follow); ok && !t.Follow() {
+			return
+		}
+
+		switch t := tok.(type) {
+		case token.ForwardToken:
+			if v := t.Get(); v != nil {
+				walk(v)
+			}
+		case token.ListToken:
+			for i := 0; i < t.Len(); i++ {
+				c, _ := t.Get(i)
+				walk(c)
+			}
+		}
+	}
+
+	walk(s.root)
+
+	return toks
+}
+
+func (s *mutationStrategy) mutations(toks []token.Token) []mutation {
+	var mutations []mutation
+
+	for i, tok := range toks {
+		switch t := tok.(type) {
+		case *lists.One:
+			if t.Permutations() > 1 {
+				mutations = append(mutations, mutation{i, mutationSwap})
+			}
+		case *lists.Repeat:
+			l := int64(t.Len())
+
+			if l > 0 && l < t.To() {
+				mutations = append(mutations, mutation{i, mutationDuplicate})
+			}
+			if l > t.From() {
+				mutations = append(mutations, mutation{i, mutationRemove})
+			}
+		default:
+			if tok.Permutations() > 1 {
+				mutations = append(mutations, mutation{i, mutationPermute})
+			}
+		}
+	}
+
+	return mutations
+}
+
+// mutate applies the given mutation type to the token and returns false if the mutation is not applicable to the current generation
+func (s *mutationStrategy) mutate(tok token.Token, typ mutationType, r rand.Rand) bool {
+	log.Debugf("Mutate (%p)%#v with mutation %d", tok, tok, typ)
+
+	switch typ {
+	case mutationSwap:
+		t := tok.(*lists.One)
+
+		current, _ := t.Get(0)
+
+		var others []uint
+		for i := 0; i < t.InternalLen(); i++ {
+			if c, _ := t.InternalGet(i); c != current {
+				others = append(others, uint(i))
+			}
+		}
+		if len(others) == 0 {
+			return false
+		}
+
+		if err := t.Permutation(others[r.Intn(len(others))]); err != nil {
+			log.Panic(err)
+		}
+
+		c, _ := t.Get(0)
+		s.fuzz(c, r, token.NewVariableScope())
+	case mutationDuplicate:
+		t := tok.(*lists.Repeat)
+
+		i := r.Intn(t.Len())
+
+		if err := t.Duplicate(i); err != nil {
+			return false
+		}
+	case mutationRemove:
+		t := tok.(*lists.Repeat)
+
+		if err := t.Remove(r.Intn(t.Len())); err != nil {
+			return false
+		}
+	case mutationPermute:
+		original := tok.String()
+
+		// counts which do not fit into an int64, e.g. saturated counts, are clamped
+		p := int64(math.MaxInt64)
+		if tok.Permutations() < math.MaxInt64 {
+			p = int64(tok.Permutations())
+		}
+		offset := r.Int63n(p)
+
+		// look for a different permutation beginning at a random one, but give up after a few tries
+		for i := int64(0); i < p && i < 16; i++ {
+			if err := tok.Permutation(uint((offset + i) % p)); err != nil {
+				log.Panic(err)
+			}
+
+			if tok.String() != original {
+				break
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,129 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/parser"
+	"github.com/zimmski/tavor/test"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/constraints"
+	"github.com/zimmski/tavor/token/expressions"
+	"github.com/zimmski/tavor/token/lists"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func TestMutationStrategyNilRandomGenerator(t *testing.T) {
+	ch, err := NewMutation(nil, nil)
+	Nil(t, ch)
+	Equal(t, ErrNilRandomGenerator, err.(*Error).Type)
+}
+
+func TestMutationStrategy(t *testing.T) {
+	root := lists.NewConcatenation(
+		lists.NewRepeat(
+			lists.NewOne(
+				primitives.NewConstantString("a"),
+				primitives.NewConstantString("b"),
+			),
+			1,
+			3,
+		),
+		constraints.NewOptional(
+			primitives.NewConstantString("x"),
+		),
+	)
+
+	errs := parser.ParseInternal(root, strings.NewReader("abx"))
+	Nil(t, errs)
+
+	ch, err := NewMutation(root, test.NewRandTest(1))
+	Nil(t, err)
+
+	var got []string
+
+	for i := range ch {
+		got = append(got, root.String())
+
+		ch <- i
+	}
+
+	// swap of both alternatives, duplicate and remove of a repeat item and deactivating the optional
+	Equal(t, []string{"aax", "ab", "aabx", "bbx", "ax"}, got)
+
+	for _, g := range got {
+		NotEqual(t, "abx", g)
+	}
+
+	// the permutations of 22 once tokens do not fit into an int64
+	var toks []token.Token
+	for i := 0; i < 22; i++ {
+		toks = append(toks, primitives.NewConstantString(string(rune('a'+i))))
+	}
+	once := lists.NewOnce(toks...)
+
+	errs = parser.ParseInternal(once, strings.NewReader("abcdefghijklmnopqrstuv"))
+	Nil(t, errs)
+
+	ch, err = NewMutation(once, test.NewRandTest(1))
+	Nil(t, err)
+
+	for i := range ch {
+		NotEqual(t, "abcdefghijklmnopqrstuv", once.String())
+
+		ch <- i
+	}
+
+	// nothing to mutate
+	root = lists.NewConcatenation(
+		primitives.NewConstantString("a"),
+		primitives.NewConstantString("b"),
+	)
+	errs = parser.ParseInternal(root, strings.NewReader("ab"))
+	Nil(t, errs)
+
+	ch, err = NewMutation(root, test.NewRandTest(1))
+	Nil(t, err)
+
+	_, ok := <-ch
+	False(t, ok)
+}
+
+func TestMutationStrategyErrors(t *testing.T) {
+	var tok *token.Token
+	r := test.NewRandTest(1)
+
+	p := primitives.NewEmptyPointer(tok)
+	o := lists.NewConcatenation(
+		p,
+		primitives.NewConstantInt(1),
+	)
+	Nil(t, p.Set(o))
+
+	ch, err := NewMutation(o, r)
+	Nil(t, ch)
+	Equal(t, ErrEndlessLoopDetected, err.(*Error).Type)
+
+	// pointers cannot be parsed
+	o = lists.NewConcatenation(
+		primitives.NewPointer(primitives.NewConstantInt(2)),
+		primitives.NewConstantInt(1),
+	)
+
+	ch, err = NewMutation(o, r)
+	Nil(t, ch)
+	Equal(t, ErrUnparsableGeneration, err.(*Error).Type)
+
+	// corrupted computed values are detected after parsing
+	payload := primitives.NewConstantString("hello")
+	length, err := expressions.NewComputedValue("len", "", payload)
+	Nil(t, err)
+	length.SetCorruptible(true)
+	Nil(t, length.Permutation(1))
+
+	ch, err = NewMutation(lists.NewConcatenation(length, payload), r)
+	Nil(t, ch)
+	Equal(t, ErrUnparsableGeneration, err.(*Error).Type)
+}
@@ -1,6 +1,9 @@
 package strategy
 
 import (
+	"math"
+	"strconv"
+
 	"github.com/zimmski/tavor/log"
 	"github.com/zimmski/tavor/rand"
 	"github.com/zimmski/tavor/token"
@@ -63,17 +66,21 @@ func NewRandom(root token.Token, r rand.Rand) (chan struct{}, error) {
 }
 
 func (s *random) fuzz(tok token.Token, r rand.Rand, variableScope *token.VariableScope) {
+	permutateGraph(tok, "", variableScope, func(tok token.Token, path string) (uint, bool) {
+		return randomPermutation(tok, r)
+	})
+}
+
+// permutateGraph permutates the token and all its reachable children with the permutations returned by the choose function. The path of a token identifies the token in the graph. Children of different permutations of a token have distinct paths.
+func permutateGraph(tok token.Token, path string, variableScope *token.VariableScope, choose func(tok token.Token, path string) (uint, bool)) {
 	log.Debugf("Fuzz (%p)%#v with maxPermutations %d", tok, tok, tok.Permutations())
 
 	if t, ok := tok.(token.Scoping); ok && t.Scoping() {
 		variableScope = variableScope.Push()
 	}
 
-	p := int64(tok.Permutations())
-	var rp uint
-	if p > 0 {
-		rp = uint(r.Int63n(p))
-	} else {
+	rp, ok := choose(tok, path)
+	if !ok {
 		log.Errorf("No valid permutation available")
 	}
 
@@ -82,18 +89,20 @@ func (s *random) fuzz(tok token.Token, r rand.Rand, variableScope *token.Variabl
 		log.Panic(err)
 	}
 
+	path += "." + strconv.FormatUint(uint64(rp), 10) + "/"
+
 	if t, ok := tok.(token.Follow); !ok || t.Follow() {
 		switch t := tok.(type) {
 		case token.ForwardToken:
 			if v := t.Get(); v != nil {
-				s.fuzz(v, r, variableScope)
+				permutateGraph(v, path+"0", variableScope, choose)
 			}
 		case token.ListToken:
 			l := t.Len()
 
 			for i := 0; i < l; i++ {
 				c, _ := t.Get(i)
-				s.fuzz(c, r, variableScope)
+				permutateGraph(c, path+strconv.Itoa(i), variableScope, choose)
 			}
 		}
 	}
@@ -103,6 +112,57 @@ func (s *random) fuzz(tok token.Token, r rand.Rand, variableScope *token.Variabl
 	}
 }
 
+// randomPermutation returns a random permutation of the token which honours the weights of weighted tokens. The second return argument is false if the token has no permutation.
+func randomPermutation(tok token.Token, r rand.Rand) (uint, bool) {
+	p := tok.Permutations()
+	if p == 0 {
+		return 0, false
+	}
+
+	if t, ok := tok.(token.DistributedToken); ok && !t.Uniform() {
+		// a random float in [0, 1) with the 53 bits of precision of a float64
+		return t.Quantile(float64(r.Int63n(1<<53)) / (1 << 53)), true
+	}
+
+	if t, ok := tok.(token.WeightedToken); ok {
+		if weights := t.Weights(); uint(len(weights)) == p {
+			var sum float64
+			for _, w := range weights {
+				sum += w
+			}
+
+			if sum > 0 {
+				// a random float in [0, sum) with the 53 bits of precision of a float64
+				x := float64(r.Int63n(1<<53)) / (1 << 53) * sum
+
+				last := uint(0)
+				for i, w := range weights {
+					if w <= 0 {
+						continue
+					}
+
+					if x < w {
+						return uint(i), true
+					}
+
+					x -= w
+					last = uint(i)
+				}
+
+				// rounding errors can leave a rest
+				return last, true
+			}
+		}
+	}
+
+	// counts which do not fit into an int64, e.g. saturated counts, are clamped
+	if p > math.MaxInt64 {
+		p = math.MaxInt64
+	}
+
+	return uint(r.Int63n(int64(p))), true
+}
+
 func (s *random) fuzzYADDA(root token.Token, r rand.Rand) {
 	// TODO FIXME AND FIXME FIXME FIXME this should be done automatically somehow
 	// since this doesn't work in other heuristics...
@@ -117,10 +177,15 @@ func (s *random) fuzzYADDA(root token.Token, r rand.Rand) {
 		case *sequences.SequenceExistingItem:
 			log.Debugf("Fuzz again %p(%#v)", tok, tok)
 
-			p := int64(tok.Permutations())
+			p := tok.Permutations()
+			// counts which do not fit into an int64, e.g. saturated counts, are clamped
+			if p > math.MaxInt64 {
+				p = math.MaxInt64
+			}
+
 			var rp uint
 			if p > 0 {
-				rp = uint(r.Int63n(p))
+				rp = uint(r.Int63n(int64(p)))
 			} else {
 				log.Errorf("No valid permutation available")
 			}
@@ -1,6 +1,9 @@
 package strategy
 
 import (
+	"math"
+	"math/rand"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -176,6 +179,85 @@ func validateTavorRandom(t *testing.T, seed int, format string, expect []string)
 	Equal(t, expect, got)
 }
 
+func TestRandomStrategyWeights(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	o := lists.NewOneWithWeights(
+		[]float64{3, 0, 1},
+		primitives.NewConstantString("a"),
+		primitives.NewConstantString("b"),
+		primitives.NewConstantString("c"),
+	)
+
+	counts := make(map[string]int)
+
+	for i := 0; i < 1000; i++ {
+		ch, err := NewRandom(o, r)
+		Nil(t, err)
+
+		for i := range ch {
+			counts[o.String()]++
+
+			ch <- i
+		}
+	}
+
+	// alternatives without weight are never chosen
+	Equal(t, 0, counts["b"])
+	Equal(t, 1000, counts["a"]+counts["c"])
+	True(t, counts["a"] > 2*counts["c"], counts)
+
+	// an optional with the probability 0 is never activated
+	c := constraints.NewOptionalWithProbability(primitives.NewConstantString("a"), 0)
+
+	for i := 0; i < 100; i++ {
+		ch, err := NewRandom(c, r)
+		Nil(t, err)
+
+		for i := range ch {
+			Equal(t, "", c.String())
+
+			ch <- i
+		}
+	}
+
+	// the repetitions of huge repeats are chosen without weighting every permutation
+	l := lists.NewRepeatWithProbability(primitives.NewConstantString("a"), primitives.NewConstantInt(0), primitives.NewConstantInt(math.MaxInt32), 0.5)
+
+	for i := 0; i < 100; i++ {
+		ch, err := NewRandom(l, r)
+		Nil(t, err)
+
+		for i := range ch {
+			True(t, len(l.String()) < 64, len(l.String()))
+
+			ch <- i
+		}
+	}
+}
+
+func TestRandomStrategyManyPermutations(t *testing.T) {
+	r := test.NewRandTest(1)
+
+	// the permutations of 22 once terms do not fit into an int64
+	var terms []string
+	for i := 0; i < 22; i++ {
+		terms = append(terms, strconv.Quote(string(rune('a'+i))))
+	}
+
+	o, err := parser.ParseTavor(strings.NewReader("START = @(" + strings.Join(terms, " | ") + ")\n"))
+	Nil(t, err)
+
+	ch, err := NewRandom(o, r)
+	Nil(t, err)
+
+	for i := range ch {
+		Equal(t, 22, len(o.String()))
+
+		ch <- i
+	}
+}
+
 func TestRandomStrategyLoopDetection(t *testing.T) {
 	testStrategyLoopDetection(t, NewRandom)
 }
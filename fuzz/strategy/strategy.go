@@ -16,6 +16,10 @@ const (
 	ErrEndlessLoopDetected ErrorType = iota
 	// ErrNilRandomGenerator the random generator is nil
 	ErrNilRandomGenerator
+	// ErrUnparsableGeneration the current generation of the token graph cannot be parsed
+	ErrUnparsableGeneration
+	// ErrInvalidState the state of the fuzzing strategy does not fit the token graph
+	ErrInvalidState
 )
 
 // Error holds a fuzzing strategy error
@@ -29,10 +33,24 @@ func (err *Error) Error() string {
 }
 
 // Strategy defines a fuzzing strategy.
-// The function starts the first iteration of the fuzzing strategy returning a channel which controls the iteration flow. The channel returns a value if the iteration is complete and waits with calculating the next iteration until a value is put in. The channel is automatically closed when there are no more iterations. The error return argument is not nil if an error occurs during the setup of the fuzzing strategy.
+// The function starts the first iteration of the fuzzing strategy returning a channel which controls the iteration flow. The channel returns a value if the iteration is complete and waits with calculating the next iteration until a value is put in. The channel is automatically closed when there are no more iterations. The token graph is not modified by the strategy between returning a value and receiving the next one, which is the time frame to read the generation or to render it via NewGeneration. The error return argument is not nil if an error occurs during the setup of the fuzzing strategy.
 type Strategy func(root token.Token, r rand.Rand) (chan struct{}, error)
 
+// FeedbackScore the fuzzing strategy feedback type which holds the score of an iteration.
+// A positive score means that the iteration produced new behaviour, for example new edge coverage of the tested program, the higher the score the more new behaviour was found.
+type FeedbackScore int
+
+// FeedbackStrategy defines a fuzzing strategy which is guided by feedback
+// The function starts the first iteration of the fuzzing strategy returning a channel which controls the iteration flow and a channel for the feedback of the iteration. The channel returns a value if the iteration is complete and waits with calculating the next iteration until feedback is given and a value is put in. Closing the feedback channel instead of giving feedback ends the fuzzing, the iteration channel is then closed by the strategy. The error return argument is not nil if an error occurs during the setup of the fuzzing strategy.
+type FeedbackStrategy func(root token.Token, r rand.Rand) (chan struct{}, chan<- FeedbackScore, error)
+
+// ResumableStrategy defines a fuzzing strategy which can save its position and continue from a saved position
+// The function starts the fuzzing strategy like a Strategy. If the given state is not nil the strategy continues after the iteration of the state, otherwise it starts with the first iteration. The returned state function returns the position of the strategy after the current iteration. It must only be called while the strategy waits for the continuation of an iteration. The error return argument is not nil if an error occurs during the setup of the fuzzing strategy or if the state does not fit the token graph.
+type ResumableStrategy func(root token.Token, r rand.Rand, state []byte) (chan struct{}, func() ([]byte, error), error)
+
 var strategyLookup = make(map[string]Strategy)
+var feedbackStrategyLookup = make(map[string]FeedbackStrategy)
+var resumableStrategyLookup = make(map[string]ResumableStrategy)
 
 // New returns a new fuzzing strategy instance given the registered name of the strategy.
 // The error return argument is not nil, if the name does not exist in the registered fuzzing strategy list.
@@ -67,6 +85,77 @@ func Register(name string, strat Strategy) {
 	if _, ok := strategyLookup[name]; ok {
 		panic("fuzzing strategy " + name + " already registered")
 	}
+	if _, ok := feedbackStrategyLookup[name]; ok {
+		panic("feedback fuzzing strategy " + name + " already registered")
+	}
 
 	strategyLookup[name] = strat
 }
+
+// NewFeedback returns a new feedback fuzzing strategy instance given the registered name of the strategy.
+// The error return argument is not nil, if the name does not exist in the registered feedback fuzzing strategy list.
+func NewFeedback(name string) (FeedbackStrategy, error) {
+	strat, ok := feedbackStrategyLookup[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown feedback fuzzing strategy %q", name)
+	}
+
+	return strat, nil
+}
+
+// ListFeedback returns a list of all registered feedback fuzzing strategy names.
+func ListFeedback() []string {
+	keyStrategyLookup := make([]string, 0, len(feedbackStrategyLookup))
+
+	for key := range feedbackStrategyLookup {
+		keyStrategyLookup = append(keyStrategyLookup, key)
+	}
+
+	sort.Strings(keyStrategyLookup)
+
+	return keyStrategyLookup
+}
+
+// RegisterFeedback registers a feedback fuzzing strategy instance function with the given name.
+// The name has to be unique over all fuzzing strategies and feedback fuzzing strategies.
+func RegisterFeedback(name string, strat FeedbackStrategy) {
+	if strat == nil {
+		panic("register feedback fuzzing strategy is nil")
+	}
+
+	if _, ok := strategyLookup[name]; ok {
+		panic("fuzzing strategy " + name + " already registered")
+	}
+	if _, ok := feedbackStrategyLookup[name]; ok {
+		panic("feedback fuzzing strategy " + name + " already registered")
+	}
+
+	feedbackStrategyLookup[name] = strat
+}
+
+// NewResumable returns the resumable variant of the registered fuzzing strategy with the given name.
+// The error return argument is not nil, if the name does not exist in the registered resumable fuzzing strategy list.
+func NewResumable(name string) (ResumableStrategy, error) {
+	strat, ok := resumableStrategyLookup[name]
+	if !ok {
+		return nil, fmt.Errorf("fuzzing strategy %q cannot be resumed", name)
+	}
+
+	return strat, nil
+}
+
+// RegisterResumable registers the resumable variant of the registered fuzzing strategy with the given name.
+func RegisterResumable(name string, strat ResumableStrategy) {
+	if strat == nil {
+		panic("register resumable fuzzing strategy is nil")
+	}
+
+	if _, ok := strategyLookup[name]; !ok {
+		panic("fuzzing strategy " + name + " is not registered")
+	}
+	if _, ok := resumableStrategyLookup[name]; ok {
+		panic("resumable fuzzing strategy " + name + " already registered")
+	}
+
+	resumableStrategyLookup[name] = strat
+}
@@ -80,6 +80,57 @@ func TestStrategy(t *testing.T) {
 	True(t, caught)
 }
 
+func newMockFeedbackStrategy(root token.Token, r rand.Rand) (chan struct{}, chan<- FeedbackScore, error) {
+	// do nothing
+
+	return nil, nil, nil
+}
+
+func TestFeedbackStrategy(t *testing.T) {
+	// mock is not registered
+	for _, name := range ListFeedback() {
+		if name == "feedbackmock" {
+			Fail(t, "feedbackmock should not be in the feedback strategy list yet")
+		}
+	}
+
+	strat, err := NewFeedback("feedbackmock")
+	Nil(t, strat)
+	NotNil(t, err)
+
+	// register mock
+	RegisterFeedback("feedbackmock", newMockFeedbackStrategy)
+
+	Contains(t, ListFeedback(), "feedbackmock")
+	NotContains(t, List(), "feedbackmock")
+
+	strat, err = NewFeedback("feedbackmock")
+	NotNil(t, strat)
+	Nil(t, err)
+
+	// names are unique over both strategy types
+	Register("feedbackmockachino", newMockStrategy)
+
+	for _, register := range []func(){
+		func() { RegisterFeedback("feedbackmock", newMockFeedbackStrategy) },
+		func() { RegisterFeedback("feedbackmockachino", newMockFeedbackStrategy) },
+		func() { Register("feedbackmock", newMockStrategy) },
+		func() { RegisterFeedback("feedbackmockup", nil) },
+	} {
+		caught := false
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					caught = true
+				}
+			}()
+
+			register()
+		}()
+		True(t, caught)
+	}
+}
+
 func testStrategyLoopDetection(t *testing.T, newStrategy Strategy) {
 	var tok *token.Token
 	r := test.NewRandTest(1)
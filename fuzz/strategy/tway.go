@@ -0,0 +1,575 @@
+package strategy
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/rand"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/constraints"
+	"github.com/zimmski/tavor/token/lists"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+// DefaultTWayStrength is the strength t of the registered TWay fuzzing strategy
+const DefaultTWayStrength = 2
+
+// tWayMaxRetries is the count of consecutive generations which cover no new t-tuple after which the t-way strategy gives up on the remaining t-tuples
+const tWayMaxRetries = 100
+
+func init() {
+	Register("TWay", NewTWay(DefaultTWayStrength))
+}
+
+// tWayParameter is one choice of the token graph e.g. the alternatives of a one token
+type tWayParameter struct {
+	key    string
+	values int
+	uses   []int
+
+	// ancestors holds the values of the parameters which are needed for this parameter to be present
+	ancestors map[int]int
+}
+
+// tWayTuple is one combination of values for a combination of parameters which has to be covered
+type tWayTuple struct {
+	parameters []int
+	values     []int
+	covered    bool
+}
+
+type tWay struct {
+	root     token.Token
+	strength int
+
+	parameters []*tWayParameter
+	keys       map[string]int
+
+	// k is the count of parameters of the registered t-tuples which is less than the strength as long as not enough parameters are known
+	k int
+
+	tuples    map[string]*tWayTuple
+	uncovered int
+	// byParameter holds the t-tuples of every parameter which is part of the t-tuple or needed for the presence of one of its parameters
+	byParameter map[int][]*tWayTuple
+
+	history    []map[int]int
+	assignment map[int]int
+	order      []int
+}
+
+// NewPairwise implements a fuzzing strategy that generates a covering array of the choices of a token graph with the strength 2.
+// See NewTWay for more details.
+func NewPairwise(root token.Token, r rand.Rand) (chan struct{}, error) {
+	return NewTWay(2)(root, r)
+}
+
+// NewTWay returns a fuzzing strategy that generates a covering array of the choices of a token graph with the given strength t.
+// The choices of the graph are the alternatives of one tokens, the presence of optional tokens and the boundary values of range integer tokens. Every iteration of the strategy generates a new generation which greedily covers as many not yet covered t-tuples of choices as possible. Choices are found while generating, the t-tuples of all found choices which can be present together are registered for covering. Choices which exclude each other, like the content of a deactivated optional token or the contents of different alternatives of a one token, are not combined. The strategy ends when every registered t-tuple is covered, which needs far less generations than all permutations. Generations which do not cover a new t-tuple are not used, the strategy gives up on the remaining t-tuples if too many of them are generated in a row and reports every uncovered t-tuple as warning. All other tokens are permutated at random. The generation is deterministic for the same random generator.
+func NewTWay(strength int) Strategy {
+	if strength < 1 {
+		panic("strength of t-way strategy must be positive")
+	}
+
+	return func(root token.Token, r rand.Rand) (chan struct{}, error) {
+		if r == nil {
+			return nil, &Error{
+				Message: "random generator is nil",
+				Type:    ErrNilRandomGenerator,
+			}
+		}
+
+		if token.LoopExists(root) {
+			return nil, &Error{
+				Message: "found endless loop in graph. Cannot proceed.",
+				Type:    ErrEndlessLoopDetected,
+			}
+		}
+
+		s := &tWay{
+			root:     root,
+			strength: strength,
+
+			keys: make(map[string]int),
+
+			tuples:      make(map[string]*tWayTuple),
+			byParameter: make(map[int][]*tWayTuple),
+		}
+
+		continueFuzzing := make(chan struct{})
+
+		go func() {
+			log.Debug("start t-way routine")
+
+			retries := 0
+
+			for {
+				covered := s.generate(r)
+
+				if covered == 0 && len(s.history) > 0 {
+					retries++
+
+					if retries == tWayMaxRetries {
+						s.reportUncovered()
+
+						break
+					}
+
+					continue
+				}
+
+				retries = 0
+
+				s.history = append(s.history, s.assignment)
+
+				log.Debugf("done with fuzzing step which covered %d t-tuples", covered)
+
+				// done with this fuzzing step
+				continueFuzzing <- struct{}{}
+
+				// wait until we are allowed to continue
+				if _, ok := <-continueFuzzing; !ok {
+					log.Debug("fuzzing channel closed from outside")
+
+					return
+				}
+
+				if s.uncovered == 0 {
+					log.Debug("every t-tuple is covered")
+
+					break
+				}
+			}
+
+			log.Debug("finished fuzzing")
+
+			close(continueFuzzing)
+		}()
+
+		return continueFuzzing, nil
+	}
+}
+
+// generate permutates the token graph and returns how many t-tuples are newly covered by it
+func (s *tWay) generate(r rand.Rand) int {
+	found := len(s.parameters)
+
+	s.assignment = make(map[int]int)
+	s.order = nil
+
+	s.fuzz(s.root, "", nil, r)
+
+	token.ResetScope(s.root)
+	_ = token.ResetResetTokens(s.root)
+	token.ResetScope(s.root)
+
+	for a, v := range s.assignment {
+		s.parameters[a].uses[v]++
+	}
+
+	s.register(found)
+
+	return s.cover()
+}
+
+func (s *tWay) fuzz(tok token.Token, path string, ancestors map[int]int, r rand.Rand) {
+	var values []uint
+
+	switch t := tok.(type) {
+	case *lists.One:
+		for i := uint(0); i < t.Permutations(); i++ {
+			values = append(values, i)
+		}
+	case *constraints.Optional:
+		values = []uint{0, 1}
+	case *primitives.RangeInt:
+		values = rangeIntBoundaries(t)
+	}
+
+	var permutation uint
+
+	if len(values) > 1 {
+		key := path + " " + fmt.Sprintf("%T", tok)
+
+		a, ok := s.keys[key]
+		if !ok {
+			a = len(s.parameters)
+			s.keys[key] = a
+			s.parameters = append(s.parameters, &tWayParameter{
+				key:    key,
+				values: len(values),
+				uses:   make([]int, len(values)),
+
+				ancestors: ancestors,
+			})
+
+			log.Debugf("found parameter %d %q with %d values", a, key, len(values))
+		}
+
+		v := s.choose(a)
+		s.assignment[a] = v
+		s.order = append(s.order, a)
+
+		permutation = values[v]
+		path += "=" + strconv.Itoa(v)
+
+		children := make(map[int]int, len(ancestors)+1)
+		for b, w := range ancestors {
+			children[b] = w
+		}
+		children[a] = v
+		ancestors = children
+	} else if p := int64(tok.Permutations()); p > 0 {
+		permutation = uint(r.Int63n(p))
+	} else {
+		log.Errorf("No valid permutation available")
+	}
+
+	if err := tok.Permutation(permutation); err != nil {
+		log.Panic(err)
+	}
+
+	if t, ok := tok.(token.Follow); !ok || t.Follow() {
+		switch t := tok.(type) {
+		case token.ForwardToken:
+			if v := t.Get(); v != nil {
+				s.fuzz(v, path+"/0", ancestors, r)
+			}
+		case token.ListToken:
+			l := t.Len()
+
+			for i := 0; i < l; i++ {
+				c, _ := t.Get(i)
+				s.fuzz(c, path+"/"+strconv.Itoa(i), ancestors, r)
+			}
+		}
+	}
+}
+
+// choose returns the value of the parameter which covers the most t-tuples with the already chosen values of the current generation. Ties are broken by the count of t-tuples which can still be covered by the following parameters, including the parameters which are only present with the value, and then by the least used value.
+func (s *tWay) choose(a int) int {
+	p := s.parameters[a]
+
+	best, bestCovers, bestPossible := 0, -1, -1
+
+	for v := 0; v < p.values; v++ {
+		covers, possible := 0, 0
+
+	TUPLES:
+		for _, t := range s.byParameter[a] {
+			if t.covered {
+				continue
+			}
+
+			complete := true
+
+			for i, b := range t.parameters {
+				if b == a {
+					if t.values[i] != v {
+						continue TUPLES
+					}
+				} else if w, ok := s.assignment[b]; !ok {
+					complete = false
+				} else if w != t.values[i] {
+					continue TUPLES
+				}
+
+				// the parameter of the t-tuple is not present if one of its ancestors has another value
+				for c, w := range s.parameters[b].ancestors {
+					if c == a {
+						if w != v {
+							continue TUPLES
+						}
+					} else if x, ok := s.assignment[c]; ok && x != w {
+						continue TUPLES
+					}
+				}
+			}
+
+			if complete {
+				covers++
+			} else {
+				possible++
+			}
+		}
+
+		if covers > bestCovers ||
+			(covers == bestCovers && possible > bestPossible) ||
+			(covers == bestCovers && possible == bestPossible && p.uses[v] < p.uses[best]) {
+			best, bestCovers, bestPossible = v, covers, possible
+		}
+	}
+
+	return best
+}
+
+// register registers the t-tuples of all parameter combinations which include a parameter that was found by the current generation. The parameters are found in the given order, found is the first new parameter.
+func (s *tWay) register(found int) {
+	k := s.strength
+	if len(s.parameters) < k {
+		k = len(s.parameters)
+	}
+
+	if k != s.k {
+		// the combinations of fewer parameters are replaced by the combinations of all parameters with the new count
+		s.k = k
+		s.tuples = make(map[string]*tWayTuple)
+		s.byParameter = make(map[int][]*tWayTuple)
+		s.uncovered = 0
+
+		found = 0
+	}
+
+	for a := found; a < len(s.parameters); a++ {
+		if a < k-1 {
+			continue
+		}
+
+		previous := make([]int, a)
+		for b := range previous {
+			previous[b] = b
+		}
+
+		combinations(previous, k-1, func(combination []int) {
+			s.registerCombination(append(combination, a))
+		})
+	}
+}
+
+// registerCombination adds all possible t-tuples of the parameter combination and marks the ones as covered which were present in previous generations
+func (s *tWay) registerCombination(parameters []int) {
+	values := make([]int, len(parameters))
+
+	for {
+		if s.possible(parameters, values) {
+			t := &tWayTuple{
+				parameters: parameters,
+				values:     append([]int(nil), values...),
+			}
+
+			s.tuples[tWayKey(parameters, t.values)] = t
+			for a := range s.relevant(parameters) {
+				s.byParameter[a] = append(s.byParameter[a], t)
+			}
+
+			s.uncovered++
+		}
+
+		// count up the values like digits of a number
+		i := len(values) - 1
+		for ; i >= 0; i-- {
+			values[i]++
+
+			if values[i] < s.parameters[parameters[i]].values {
+				break
+			}
+
+			values[i] = 0
+		}
+
+		if i < 0 {
+			break
+		}
+	}
+
+	// the current generation is not part of the history since it is counted as newly covered
+	for _, assignment := range s.history {
+		values := make([]int, len(parameters))
+
+		present := true
+		for i, a := range parameters {
+			v, ok := assignment[a]
+			if !ok {
+				present = false
+
+				break
+			}
+
+			values[i] = v
+		}
+
+		if t := s.tuples[tWayKey(parameters, values)]; present && t != nil && !t.covered {
+			t.covered = true
+			s.uncovered--
+		}
+	}
+}
+
+// relevant returns the given parameters and all their ancestors
+func (s *tWay) relevant(parameters []int) map[int]struct{} {
+	relevant := make(map[int]struct{})
+
+	for _, a := range parameters {
+		relevant[a] = struct{}{}
+
+		for b := range s.parameters[a].ancestors {
+			relevant[b] = struct{}{}
+		}
+	}
+
+	return relevant
+}
+
+// cover returns how many t-tuples are newly covered by the current generation
+func (s *tWay) cover() int {
+	if s.k == 0 || len(s.order) < s.k {
+		return 0
+	}
+
+	covered := 0
+
+	combinations(s.order, s.k, func(parameters []int) {
+		values := make([]int, len(parameters))
+		for i, a := range parameters {
+			values[i] = s.assignment[a]
+		}
+
+		if t := s.tuples[tWayKey(parameters, values)]; t != nil && !t.covered {
+			t.covered = true
+			s.uncovered--
+
+			covered++
+		}
+	})
+
+	return covered
+}
+
+// reportUncovered logs every t-tuple which is not covered in the order of their parameters
+func (s *tWay) reportUncovered() {
+	var uncovered []string
+
+	for _, t := range s.tuples {
+		if t.covered {
+			continue
+		}
+
+		var tuple bytes.Buffer
+		for i, a := range t.parameters {
+			if i > 0 {
+				tuple.WriteString(", ")
+			}
+
+			fmt.Fprintf(&tuple, "%s=%d", s.parameters[a].key, t.values[i])
+		}
+
+		uncovered = append(uncovered, tuple.String())
+	}
+
+	sort.Strings(uncovered)
+
+	log.Warnf("giving up on %d t-tuples which were not covered after %d generations", len(uncovered), len(s.history))
+	for _, tuple := range uncovered {
+		log.Warnf("not covered: %s", tuple)
+	}
+}
+
+// possible returns false if a value of the t-tuple excludes the presence of another parameter of the t-tuple e.g. a deactivated optional with a choice of its child, or if two parameters of the t-tuple need different values of the same parameter e.g. choices of different alternatives of a one token
+func (s *tWay) possible(parameters []int, values []int) bool {
+	for i, a := range parameters {
+		for j, b := range parameters {
+			if w, ok := s.parameters[a].ancestors[b]; ok && w != values[j] {
+				return false
+			}
+
+			if j > i {
+				for c, w := range s.parameters[a].ancestors {
+					if x, ok := s.parameters[b].ancestors[c]; ok && x != w {
+						return false
+					}
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// combinations calls the function for every combination of k parameters. The parameters of a combination are in ascending order.
+func combinations(parameters []int, k int, f func(combination []int)) {
+	sorted := append([]int(nil), parameters...)
+	sort.Ints(sorted)
+
+	indices := make([]int, k)
+	for i := range indices {
+		indices[i] = i
+	}
+
+	for {
+		combination := make([]int, k)
+		for i, j := range indices {
+			combination[i] = sorted[j]
+		}
+
+		f(combination)
+
+		// find the rightmost index which can still be moved
+		i := k - 1
+		for i >= 0 && indices[i] == len(sorted)-k+i {
+			i--
+		}
+
+		if i < 0 {
+			return
+		}
+
+		indices[i]++
+		for j := i + 1; j < k; j++ {
+			indices[j] = indices[j-1] + 1
+		}
+	}
+}
+
+func tWayKey(parameters []int, values []int) string {
+	var buffer bytes.Buffer
+
+	for i, a := range parameters {
+		buffer.WriteString(strconv.Itoa(a))
+
+		if values != nil {
+			buffer.WriteByte('=')
+			buffer.WriteString(strconv.Itoa(values[i]))
+		}
+
+		buffer.WriteByte(',')
+	}
+
+	return buffer.String()
+}
+
+// rangeIntBoundaries returns the permutations of the boundary values of the range which are the lower and upper boundaries, the middle value and -1, 0 and 1 if the range crosses zero
+func rangeIntBoundaries(tok *primitives.RangeInt) []uint {
+	l := tok.Permutations()
+	if l == 0 {
+		return nil
+	}
+
+	var boundaries []uint
+	seen := make(map[uint]struct{})
+
+	add := func(p uint) {
+		if _, ok := seen[p]; !ok && p < l {
+			seen[p] = struct{}{}
+			boundaries = append(boundaries, p)
+		}
+	}
+
+	add(0)
+
+	if tok.From() < 0 && tok.To() > 0 {
+		for _, i := range []int{-1, 0, 1} {
+			if d := i - tok.From(); d%tok.Step() == 0 {
+				add(uint(d / tok.Step()))
+			}
+		}
+	} else {
+		add(l / 2)
+	}
+
+	add(l - 1)
+
+	return boundaries
+}
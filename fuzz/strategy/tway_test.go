@@ -0,0 +1,236 @@
+package strategy
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/test"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/constraints"
+	"github.com/zimmski/tavor/token/lists"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func tWayGenerations(t *testing.T, strat Strategy, root token.Token) []string {
+	r := test.NewRandTest(1)
+
+	ch, err := strat(root, r)
+	Nil(t, err)
+
+	var got []string
+
+	for i := range ch {
+		got = append(got, root.String())
+
+		ch <- i
+	}
+
+	return got
+}
+
+func TestTWayCombinations(t *testing.T) {
+	var got [][]int
+
+	combinations([]int{3, 1, 2, 0}, 2, func(c []int) {
+		got = append(got, c)
+	})
+
+	Equal(t, [][]int{{0, 1}, {0, 2}, {0, 3}, {1, 2}, {1, 3}, {2, 3}}, got)
+
+	got = nil
+
+	combinations([]int{0, 1}, 2, func(c []int) {
+		got = append(got, c)
+	})
+
+	Equal(t, [][]int{{0, 1}}, got)
+}
+
+func TestTWayRangeIntBoundaries(t *testing.T) {
+	Equal(t, []uint{0}, rangeIntBoundaries(primitives.NewRangeInt(5, 5)))
+	Equal(t, []uint{0, 1}, rangeIntBoundaries(primitives.NewRangeInt(1, 2)))
+	Equal(t, []uint{0, 50, 99}, rangeIntBoundaries(primitives.NewRangeInt(1, 100)))
+	Equal(t, []uint{0, 4, 5, 6, 15}, rangeIntBoundaries(primitives.NewRangeInt(-5, 10)))
+	Equal(t, []uint{0, 1, 2}, rangeIntBoundaries(primitives.NewRangeIntWithStep(-2, 2, 2)))
+}
+
+func TestTWayStrategy(t *testing.T) {
+	digits := func() token.Token {
+		return lists.NewOne(
+			primitives.NewConstantInt(1),
+			primitives.NewConstantInt(2),
+			primitives.NewConstantInt(3),
+		)
+	}
+
+	{
+		// a single choice has to be covered completely
+		got := tWayGenerations(t, NewPairwise, digits())
+
+		Equal(t, []string{"1", "2", "3"}, got)
+	}
+	{
+		// no choices at all lead to exactly one generation
+		got := tWayGenerations(t, NewPairwise, lists.NewConcatenation(
+			primitives.NewConstantInt(1),
+			primitives.NewConstantInt(2),
+		))
+
+		Equal(t, []string{"12"}, got)
+	}
+	{
+		// every pair of four choices with three alternatives each
+		root := lists.NewConcatenation(digits(), digits(), digits(), digits())
+
+		got := tWayGenerations(t, NewPairwise, root)
+
+		pairs := make(map[[4]byte]struct{})
+		for _, g := range got {
+			for i := 0; i < 4; i++ {
+				for j := i + 1; j < 4; j++ {
+					pairs[[4]byte{byte(i), byte(j), g[i], g[j]}] = struct{}{}
+				}
+			}
+		}
+
+		Equal(t, 6*9, len(pairs))
+		True(t, len(got) < 81)
+		True(t, len(got) <= 15, len(got))
+
+		// all triples need more generations
+		got3 := tWayGenerations(t, NewTWay(3), root)
+
+		triples := make(map[[6]byte]struct{})
+		for _, g := range got3 {
+			for i := 0; i < 4; i++ {
+				for j := i + 1; j < 4; j++ {
+					for k := j + 1; k < 4; k++ {
+						triples[[6]byte{byte(i), byte(j), byte(k), g[i], g[j], g[k]}] = struct{}{}
+					}
+				}
+			}
+		}
+
+		Equal(t, 4*27, len(triples))
+		True(t, len(got3) > len(got))
+		True(t, len(got3) < 81)
+	}
+	{
+		// choices of optionals are only combined if the optional is present
+		a := constraints.NewOptional(lists.NewConcatenation(
+			primitives.NewConstantString("a"),
+			lists.NewOne(primitives.NewConstantString("x"), primitives.NewConstantString("y")),
+		))
+		b := constraints.NewOptional(primitives.NewConstantString("b"))
+		c := lists.NewOne(primitives.NewConstantString("1"), primitives.NewConstantString("2"))
+		root := lists.NewConcatenation(a, b, c)
+
+		got := tWayGenerations(t, NewPairwise, root)
+
+		// every generation is <a with x or y><b><1 or 2> where a and b are optional
+		type choices struct {
+			a, b, c string
+		}
+
+		var generations []choices
+		for _, g := range got {
+			var ch choices
+
+			if len(g) > 0 && g[0] == 'a' {
+				ch.a = g[:2]
+				g = g[2:]
+			}
+			if len(g) > 0 && g[0] == 'b' {
+				ch.b = "b"
+				g = g[1:]
+			}
+			ch.c = g
+
+			generations = append(generations, ch)
+		}
+
+		covered := func(f func(ch choices) bool) bool {
+			for _, ch := range generations {
+				if f(ch) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		for _, a := range []string{"", "ax", "ay"} {
+			for _, b := range []string{"", "b"} {
+				True(t, covered(func(ch choices) bool { return ch.a == a && ch.b == b }), a, b, got)
+			}
+			for _, c := range []string{"1", "2"} {
+				True(t, covered(func(ch choices) bool { return ch.a == a && ch.c == c }), a, c, got)
+			}
+		}
+		for _, b := range []string{"", "b"} {
+			for _, c := range []string{"1", "2"} {
+				True(t, covered(func(ch choices) bool { return ch.b == b && ch.c == c }), b, c, got)
+			}
+		}
+
+		True(t, len(got) < 12, got)
+	}
+	{
+		// choices of optionals which are not present together at first are combined too
+		a := constraints.NewOptional(lists.NewOne(primitives.NewConstantString("x"), primitives.NewConstantString("y")))
+		b := constraints.NewOptional(lists.NewOne(primitives.NewConstantString("1"), primitives.NewConstantString("2")))
+		root := lists.NewConcatenation(a, b)
+
+		got := tWayGenerations(t, NewPairwise, root)
+
+		for _, x := range []string{"x", "y"} {
+			for _, y := range []string{"1", "2"} {
+				Contains(t, got, x+y)
+			}
+		}
+	}
+	{
+		// choices of different alternatives are never present together
+		root := lists.NewConcatenation(
+			lists.NewOne(
+				lists.NewConcatenation(primitives.NewConstantString("a"), lists.NewOne(primitives.NewConstantString("x"), primitives.NewConstantString("y"))),
+				lists.NewConcatenation(primitives.NewConstantString("b"), lists.NewOne(primitives.NewConstantString("1"), primitives.NewConstantString("2"))),
+			),
+			lists.NewOne(primitives.NewConstantString("p"), primitives.NewConstantString("q")),
+		)
+
+		got := tWayGenerations(t, NewPairwise, root)
+
+		for _, x := range []string{"ax", "ay", "b1", "b2"} {
+			for _, y := range []string{"p", "q"} {
+				Contains(t, got, x+y)
+			}
+		}
+		Equal(t, 8, len(got))
+	}
+}
+
+func TestTWayStrategyStrength(t *testing.T) {
+	root := lists.NewConcatenation(
+		lists.NewOne(primitives.NewConstantInt(1), primitives.NewConstantInt(2)),
+		lists.NewOne(primitives.NewConstantInt(1), primitives.NewConstantInt(2)),
+		lists.NewOne(primitives.NewConstantInt(1), primitives.NewConstantInt(2)),
+	)
+
+	for strength, generations := range map[int]int{1: 2, 3: 8} {
+		got := tWayGenerations(t, NewTWay(strength), root)
+
+		Equal(t, generations, len(got), got)
+	}
+
+	// the registered strategy covers pairs
+	strat, err := New("TWay")
+	Nil(t, err)
+
+	Equal(t, tWayGenerations(t, NewPairwise, root), tWayGenerations(t, strat, root))
+}
+
+func TestTWayStrategyLoopDetection(t *testing.T) {
+	testStrategyLoopDetection(t, NewPairwise)
+}
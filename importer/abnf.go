@@ -0,0 +1,455 @@
+package importer
+
+import (
+	"io"
+	"strings"
+)
+
+func init() {
+	Register("abnf", ParseABNF)
+}
+
+// abnfCoreRules holds the core rules of RFC 5234 Appendix B.1 which can be used by every ABNF grammar without defining them
+const abnfCoreRules = `
+ALPHA  = %x41-5A / %x61-7A
+BIT    = "0" / "1"
+CHAR   = %x01-7F
+CR     = %x0D
+CRLF   = CR LF
+CTL    = %x00-1F / %x7F
+DIGIT  = %x30-39
+DQUOTE = %x22
+HEXDIG = DIGIT / "A" / "B" / "C" / "D" / "E" / "F"
+HTAB   = %x09
+LF     = %x0A
+LWSP   = *(WSP / CRLF WSP)
+OCTET  = %x00-FF
+SP     = %x20
+VCHAR  = %x21-7E
+WSP    = SP / HTAB
+`
+
+// ParseABNF parses an ABNF grammar as defined by RFC 5234 and RFC 7405.
+// Rule names are case-insensitive and core rules like DIGIT are added if they are referenced but not defined by the grammar.
+func ParseABNF(src io.Reader) (*Grammar, error) {
+	g, err := parseABNF(src)
+	if err != nil {
+		return nil, err
+	}
+
+	core, err := parseABNF(strings.NewReader(abnfCoreRules))
+	if err != nil {
+		panic(err)
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, r := range g.Rules {
+			walk(r.Expression, func(e Expression) {
+				if ref, ok := e.(*Reference); ok && g.rule(ref.Name) == nil {
+					if c := core.rule(ref.Name); c != nil {
+						g.Rules = append(g.Rules, c)
+						changed = true
+					}
+				}
+			})
+		}
+	}
+
+	return g, nil
+}
+
+type abnfParser struct {
+	*source
+
+	grammar *Grammar
+}
+
+func parseABNF(src io.Reader) (*Grammar, error) {
+	s, err := newSource(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &abnfParser{
+		source: s,
+		grammar: &Grammar{
+			caseInsensitive: true,
+		},
+	}
+
+	if err := p.parseRules(); err != nil {
+		return nil, err
+	}
+
+	return p.grammar, nil
+}
+
+// skipSpace skips white spaces, comments and new lines. Rules end if the next character is at the beginning of a line.
+func (p *abnfParser) skipSpace() {
+	for !p.eof() {
+		switch p.peek(0) {
+		case ' ', '\t', '\r', '\n':
+			p.next()
+		case ';':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *abnfParser) ruleEnd() bool {
+	return p.eof() || p.column == 1
+}
+
+func (p *abnfParser) parseRules() error {
+	for {
+		p.skipSpace()
+
+		if p.eof() {
+			return nil
+		}
+
+		position := p.position()
+
+		if !isLetter(p.peek(0)) {
+			return p.errorf("expected rule name but got %s", p.describe())
+		}
+
+		name := p.parseRuleName()
+
+		p.skipSpace()
+
+		if p.ruleEnd() || p.peek(0) != '=' {
+			return p.errorf("expected \"=\" but got %s", p.describe())
+		}
+		p.next()
+
+		incremental := false
+		if p.peek(0) == '/' {
+			p.next()
+
+			incremental = true
+		}
+
+		p.skipSpace()
+
+		e, err := p.parseAlternation()
+		if err != nil {
+			return err
+		}
+
+		if !p.ruleEnd() {
+			return p.errorf("expected end of rule but got %s", p.describe())
+		}
+
+		if r := p.grammar.rule(name); r != nil {
+			if !incremental {
+				return p.errorAt(position, "rule %q is already defined", name)
+			}
+
+			r.Expression = &Alternation{
+				Items: []Expression{r.Expression, e},
+			}
+		} else {
+			p.grammar.Rules = append(p.grammar.Rules, &Rule{
+				Name:       name,
+				Expression: e,
+				Position:   position,
+			})
+		}
+	}
+}
+
+func (p *abnfParser) parseRuleName() string {
+	var name []rune
+
+	for c := p.peek(0); isLetter(c) || isDigit(c) || c == '-'; c = p.peek(0) {
+		name = append(name, p.next())
+	}
+
+	return string(name)
+}
+
+func (p *abnfParser) parseAlternation() (Expression, error) {
+	var items []Expression
+
+	for {
+		e, err := p.parseConcatenation()
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, e)
+
+		if p.ruleEnd() || p.peek(0) != '/' {
+			break
+		}
+		p.next()
+
+		p.skipSpace()
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+
+	return &Alternation{Items: items}, nil
+}
+
+func (p *abnfParser) parseConcatenation() (Expression, error) {
+	var items []Expression
+
+	for {
+		if p.ruleEnd() {
+			break
+		} else if c := p.peek(0); c == '/' || c == ')' || c == ']' {
+			break
+		}
+
+		e, err := p.parseRepetition()
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, e)
+
+		p.skipSpace()
+	}
+
+	switch len(items) {
+	case 0:
+		return nil, p.errorf("expected element but got %s", p.describe())
+	case 1:
+		return items[0], nil
+	}
+
+	return &Concatenation{Items: items}, nil
+}
+
+func (p *abnfParser) parseRepetition() (Expression, error) {
+	min, hasMin := p.parseNumber(10)
+	max := min
+
+	if p.peek(0) == '*' {
+		p.next()
+
+		if !hasMin {
+			min = 0
+		}
+
+		var hasMax bool
+		if max, hasMax = p.parseNumber(10); !hasMax {
+			max = -1
+		} else if max < min {
+			return nil, p.errorf("maximum repetition %d is lower than the minimum %d", max, min)
+		}
+	} else if !hasMin {
+		return p.parseElement()
+	}
+
+	e, err := p.parseElement()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repetition{
+		Item: e,
+		Min:  min,
+		Max:  max,
+	}, nil
+}
+
+func (p *abnfParser) parseElement() (Expression, error) {
+	position := p.position()
+
+	switch c := p.peek(0); {
+	case isLetter(c):
+		return &Reference{
+			Name:     p.parseRuleName(),
+			Position: position,
+		}, nil
+	case c == '(' || c == '[':
+		p.next()
+		p.skipSpace()
+
+		e, err := p.parseAlternation()
+		if err != nil {
+			return nil, err
+		}
+
+		end := ')'
+		if c == '[' {
+			end = ']'
+		}
+
+		if p.ruleEnd() || p.peek(0) != end {
+			return nil, p.errorf("expected %q but got %s", end, p.describe())
+		}
+		p.next()
+
+		if c == '[' {
+			return &Repetition{
+				Item: e,
+				Min:  0,
+				Max:  1,
+			}, nil
+		}
+
+		return e, nil
+	case c == '"':
+		return p.parseString(true)
+	case c == '%':
+		p.next()
+
+		switch p.peek(0) {
+		case 's', 'S':
+			p.next()
+
+			return p.parseString(false)
+		case 'i', 'I':
+			p.next()
+
+			return p.parseString(true)
+		case 'x', 'X':
+			p.next()
+
+			return p.parseValue(16)
+		case 'd', 'D':
+			p.next()
+
+			return p.parseValue(10)
+		case 'b', 'B':
+			p.next()
+
+			return p.parseValue(2)
+		}
+
+		return nil, p.errorf("expected value type but got %s", p.describe())
+	case c == '<':
+		p.next()
+
+		var prose []rune
+		for !p.eof() && p.peek(0) != '>' && p.peek(0) != '\n' {
+			prose = append(prose, p.next())
+		}
+
+		if p.peek(0) != '>' {
+			return nil, p.errorAt(position, "prose value is not terminated")
+		}
+		p.next()
+
+		p.grammar.warn(position, "prose value <%s> cannot be represented and is omitted", string(prose))
+
+		return &Epsilon{}, nil
+	}
+
+	return nil, p.errorf("expected element but got %s", p.describe())
+}
+
+func (p *abnfParser) parseString(caseInsensitive bool) (Expression, error) {
+	position := p.position()
+
+	if p.peek(0) != '"' {
+		return nil, p.errorf("expected '\"' but got %s", p.describe())
+	}
+	p.next()
+
+	var value []rune
+	for !p.eof() && p.peek(0) != '"' && p.peek(0) != '\n' {
+		value = append(value, p.next())
+	}
+
+	if p.peek(0) != '"' {
+		return nil, p.errorAt(position, "string is not terminated")
+	}
+	p.next()
+
+	return &Literal{
+		Value:           string(value),
+		CaseInsensitive: caseInsensitive,
+	}, nil
+}
+
+// parseValue parses a numeric value which is either a single character, a concatenation of characters like %x41.42 or a range of characters like %x41-5A
+func (p *abnfParser) parseValue(base int) (Expression, error) {
+	from, ok := p.parseNumber(base)
+	if !ok {
+		return nil, p.errorf("expected value but got %s", p.describe())
+	}
+
+	switch p.peek(0) {
+	case '-':
+		p.next()
+
+		to, ok := p.parseNumber(base)
+		if !ok {
+			return nil, p.errorf("expected value but got %s", p.describe())
+		} else if to < from {
+			return nil, p.errorf("value range end %d is lower than its start %d", to, from)
+		}
+
+		return &CharacterClass{
+			Ranges: []CharacterRange{
+				{
+					From: rune(from),
+					To:   rune(to),
+				},
+			},
+		}, nil
+	case '.':
+		value := []rune{rune(from)}
+
+		for p.peek(0) == '.' {
+			p.next()
+
+			c, ok := p.parseNumber(base)
+			if !ok {
+				return nil, p.errorf("expected value but got %s", p.describe())
+			}
+
+			value = append(value, rune(c))
+		}
+
+		return &Literal{
+			Value: string(value),
+		}, nil
+	}
+
+	return &Literal{
+		Value: string(rune(from)),
+	}, nil
+}
+
+// parseNumber parses a number of the given base and returns if there was a number
+func (p *abnfParser) parseNumber(base int) (int, bool) {
+	n := 0
+	ok := false
+
+	for {
+		c := p.peek(0)
+
+		var d int
+		switch {
+		case c >= '0' && c <= '9':
+			d = int(c - '0')
+		case c >= 'a' && c <= 'f':
+			d = int(c-'a') + 10
+		case c >= 'A' && c <= 'F':
+			d = int(c-'A') + 10
+		default:
+			return n, ok
+		}
+
+		if d >= base {
+			return n, ok
+		}
+
+		p.next()
+
+		n = n*base + d
+		ok = true
+	}
+}
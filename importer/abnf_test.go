@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+)
+
+func TestABNF(t *testing.T) {
+	out, warnings := importGrammar(t, "abnf", `; a date with optional parameters
+date  = year "-" month [ "-" day ]
+        *( ";" param )
+year  = 4DIGIT
+month = %x30 %x31-39 / %x31 %x30-32
+day   = 2*3DIGIT
+Param = %s"Id" / 1*alpha / <something else>
+param =/ %x41.42 / %d67 / %b1000100
+unused = "x"
+`, "")
+	Equal(t, `START = date
+
+date = year "-" month ?("-" day) *(";" Param)
+
+year = +4(DIGIT)
+
+month = "0" [1-9] | "1" [0-2]
+
+day = +2,3(DIGIT)
+
+Param = ?("Id" | +(ALPHA)) | "AB" | "C" | "D"
+
+DIGIT = [0-9]
+
+ALPHA = [A-Z] | [a-z]
+`, out)
+	Equal(t, []string{
+		`L:7, C:28 - prose value <something else> cannot be represented and is omitted`,
+		`L:9, C:1 - rule "unused" is not reachable from the start rule "date" and is omitted`,
+	}, warnings)
+
+	// case-insensitive strings
+	out, _ = importGrammar(t, "abnf", "method = \"get\" / %i\"p-t\" / 2*\"x\"\n", "")
+	Equal(t, "START = method\n\nmethod = [Gg] [Ee] [Tt] | [Pp] \"-\" [Tt] | +2,([Xx])\n", out)
+}
+
+func TestABNFErrors(t *testing.T) {
+	for _, src := range []string{
+		"1rule = \"a\"\n",
+		"rule \"a\"\n",
+		"rule = \n",
+		"rule = \"a\n",
+		"rule = ( \"a\"\n",
+		"rule = 3*2\"a\"\n",
+		"rule = %x5A-41\n",
+		"rule = %q41\n",
+		"rule = <prose\n",
+		"rule = \"a\"\nrule = \"b\"\n",
+	} {
+		_, err := ParseABNF(strings.NewReader(src))
+		NotNil(t, err, src)
+	}
+}
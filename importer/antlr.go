@@ -0,0 +1,940 @@
+package importer
+
+import (
+	"io"
+	"strconv"
+	"text/scanner"
+)
+
+func init() {
+	Register("antlr", ParseANTLR)
+}
+
+type antlrTokenType int
+
+const (
+	antlrEOF antlrTokenType = iota
+	antlrIdent
+	antlrString
+	antlrCharset
+	antlrAction
+	antlrPunctuation
+)
+
+type antlrToken struct {
+	typ antlrTokenType
+	// text holds the decoded value of strings, the content of character sets and actions and the text of all other tokens
+	text     string
+	position scanner.Position
+}
+
+func (t *antlrToken) String() string {
+	switch t.typ {
+	case antlrEOF:
+		return "EOF"
+	case antlrString:
+		return strconv.Quote("'" + t.text + "'")
+	case antlrCharset:
+		return strconv.Quote("[" + t.text + "]")
+	case antlrAction:
+		return strconv.Quote("{" + t.text + "}")
+	}
+
+	return strconv.Quote(t.text)
+}
+
+type antlrParser struct {
+	*source
+
+	grammar *Grammar
+
+	tokens []*antlrToken
+	cur    int
+}
+
+// ParseANTLR parses an ANTLR 4 grammar.
+// Parser and lexer rules are imported alike. Actions, semantic predicates and lexer commands are ignored.
+func ParseANTLR(src io.Reader) (*Grammar, error) {
+	s, err := newSource(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &antlrParser{
+		source:  s,
+		grammar: &Grammar{},
+	}
+
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+
+	return p.grammar, nil
+}
+
+// lexer
+
+func (p *antlrParser) lex() error {
+	for {
+		if err := p.skipSpace(); err != nil {
+			return err
+		}
+
+		t := &antlrToken{
+			position: p.position(),
+		}
+
+		if p.eof() {
+			t.typ = antlrEOF
+			p.tokens = append(p.tokens, t)
+
+			return nil
+		}
+
+		switch c := p.peek(0); {
+		case isLetter(c) || c == '_':
+			var ident []rune
+			for c := p.peek(0); isLetter(c) || isDigit(c) || c == '_'; c = p.peek(0) {
+				ident = append(ident, p.next())
+			}
+
+			t.typ = antlrIdent
+			t.text = string(ident)
+		case c == '\'':
+			p.next()
+
+			var value []rune
+			for {
+				if p.eof() || p.peek(0) == '\n' {
+					return p.errorAt(t.position, "string is not terminated")
+				} else if p.peek(0) == '\'' {
+					p.next()
+
+					break
+				}
+
+				c, err := p.lexCharacter()
+				if err != nil {
+					return err
+				}
+
+				value = append(value, c)
+			}
+
+			t.typ = antlrString
+			t.text = string(value)
+		case c == '[':
+			p.next()
+
+			var content []rune
+			for {
+				if p.eof() || p.peek(0) == '\n' {
+					return p.errorAt(t.position, "character set is not terminated")
+				} else if p.peek(0) == ']' {
+					p.next()
+
+					break
+				} else if p.peek(0) == '\\' {
+					content = append(content, p.next())
+				}
+
+				content = append(content, p.next())
+			}
+
+			t.typ = antlrCharset
+			t.text = string(content)
+		case c == '{':
+			content, err := p.lexAction()
+			if err != nil {
+				return err
+			}
+
+			t.typ = antlrAction
+			t.text = content
+		default:
+			t.typ = antlrPunctuation
+
+			for _, punctuation := range []string{"..", "->", "+=", "::"} {
+				if p.peekString(punctuation) {
+					t.text = punctuation
+
+					break
+				}
+			}
+
+			if t.text == "" {
+				t.text = string(c)
+			}
+
+			for range t.text {
+				p.next()
+			}
+		}
+
+		p.tokens = append(p.tokens, t)
+	}
+}
+
+func (p *antlrParser) skipSpace() error {
+	for !p.eof() {
+		switch {
+		case p.peek(0) == ' ' || p.peek(0) == '\t' || p.peek(0) == '\r' || p.peek(0) == '\n':
+			p.next()
+		case p.peekString("//"):
+			p.skipLine()
+		case p.peekString("/*"):
+			if err := p.skipBlockComment("*/"); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// lexCharacter reads one character of a string and decodes its escape sequence
+func (p *antlrParser) lexCharacter() (rune, error) {
+	if p.peek(0) != '\\' {
+		return p.next(), nil
+	}
+
+	position := p.position()
+	p.next()
+
+	if p.eof() {
+		return 0, p.errorAt(position, "escape sequence is not terminated")
+	}
+
+	switch c := p.next(); c {
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'u':
+		var hex []rune
+
+		if p.peek(0) == '{' {
+			p.next()
+
+			for !p.eof() && p.peek(0) != '}' {
+				hex = append(hex, p.next())
+			}
+
+			if p.eof() {
+				return 0, p.errorAt(position, "escape sequence is not terminated")
+			}
+			p.next()
+		} else {
+			for i := 0; i < 4 && !p.eof(); i++ {
+				hex = append(hex, p.next())
+			}
+		}
+
+		n, err := strconv.ParseUint(string(hex), 16, 32)
+		if err != nil {
+			return 0, p.errorAt(position, "invalid unicode escape sequence %q", string(hex))
+		}
+
+		return rune(n), nil
+	default:
+		return c, nil
+	}
+}
+
+// lexAction reads an action including its nested braces and returns its content
+func (p *antlrParser) lexAction() (string, error) {
+	position := p.position()
+	p.next()
+
+	var content []rune
+	depth := 1
+
+	for {
+		if p.eof() {
+			return "", p.errorAt(position, "action is not terminated")
+		}
+
+		c := p.next()
+
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+
+			if depth == 0 {
+				return string(content), nil
+			}
+		case '"', '\'':
+			// braces of strings do not count
+			content = append(content, c)
+
+			for !p.eof() && p.peek(0) != c && p.peek(0) != '\n' {
+				if p.peek(0) == '\\' {
+					content = append(content, p.next())
+
+					if p.eof() {
+						break
+					}
+				}
+
+				content = append(content, p.next())
+			}
+
+			if p.eof() {
+				continue
+			}
+
+			c = p.next()
+		}
+
+		content = append(content, c)
+	}
+}
+
+// parser
+
+func (p *antlrParser) tok() *antlrToken {
+	return p.tokens[p.cur]
+}
+
+func (p *antlrParser) peekToken(offset int) *antlrToken {
+	if i := p.cur + offset; i < len(p.tokens) {
+		return p.tokens[i]
+	}
+
+	return p.tokens[len(p.tokens)-1]
+}
+
+func (p *antlrParser) advance() *antlrToken {
+	t := p.tokens[p.cur]
+
+	if t.typ != antlrEOF {
+		p.cur++
+	}
+
+	return t
+}
+
+func (p *antlrParser) isIdent(offset int, text string) bool {
+	t := p.peekToken(offset)
+
+	return t.typ == antlrIdent && t.text == text
+}
+
+func (p *antlrParser) isPunctuation(offset int, text string) bool {
+	t := p.peekToken(offset)
+
+	return t.typ == antlrPunctuation && t.text == text
+}
+
+func (p *antlrParser) expect(typ antlrTokenType, text string, description string) (*antlrToken, error) {
+	t := p.tok()
+
+	if t.typ != typ || (text != "" && t.text != text) {
+		return nil, p.errorAt(t.position, "expected %s but got %s", description, t)
+	}
+
+	return p.advance(), nil
+}
+
+func (p *antlrParser) expectPunctuation(text string) error {
+	_, err := p.expect(antlrPunctuation, text, strconv.Quote(text))
+
+	return err
+}
+
+func (p *antlrParser) parse() error {
+	if p.isIdent(0, "lexer") || p.isIdent(0, "parser") {
+		p.advance()
+	}
+
+	if p.isIdent(0, "grammar") {
+		p.advance()
+
+		if _, err := p.expect(antlrIdent, "", "grammar name"); err != nil {
+			return err
+		}
+
+		if err := p.expectPunctuation(";"); err != nil {
+			return err
+		}
+	}
+
+	for p.tok().typ != antlrEOF {
+		t := p.tok()
+
+		switch {
+		case (p.isIdent(0, "options") || p.isIdent(0, "tokens") || p.isIdent(0, "channels")) && p.peekToken(1).typ == antlrAction:
+			p.advance()
+			p.advance()
+		case p.isIdent(0, "import") && p.peekToken(1).typ == antlrIdent:
+			p.grammar.warn(t.position, "imported grammars are not supported")
+
+			for p.tok().typ != antlrEOF && !p.isPunctuation(0, ";") {
+				p.advance()
+			}
+
+			if err := p.expectPunctuation(";"); err != nil {
+				return err
+			}
+		case p.isIdent(0, "mode") && p.peekToken(1).typ == antlrIdent && p.isPunctuation(2, ";"):
+			p.grammar.warn(t.position, "lexer mode %q is not supported, its rules are imported as normal rules", p.peekToken(1).text)
+
+			p.advance()
+			p.advance()
+			p.advance()
+		case p.isPunctuation(0, "@"):
+			if err := p.skipNamedAction(); err != nil {
+				return err
+			}
+		default:
+			if err := p.parseRule(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// skipNamedAction skips actions like @header {...} and @lexer::members {...}
+func (p *antlrParser) skipNamedAction() error {
+	p.advance()
+
+	if _, err := p.expect(antlrIdent, "", "action name"); err != nil {
+		return err
+	}
+
+	if p.isPunctuation(0, "::") {
+		p.advance()
+
+		if _, err := p.expect(antlrIdent, "", "action name"); err != nil {
+			return err
+		}
+	}
+
+	_, err := p.expect(antlrAction, "", "action")
+
+	return err
+}
+
+func (p *antlrParser) parseRule() error {
+	if p.isIdent(0, "fragment") {
+		p.advance()
+	}
+
+	name, err := p.expect(antlrIdent, "", "rule name")
+	if err != nil {
+		return err
+	}
+
+	if p.tok().typ == antlrCharset {
+		p.grammar.warn(p.advance().position, "arguments of rule %q are ignored", name.text)
+	}
+
+RULEPREQUEL:
+	for {
+		switch {
+		case p.isIdent(0, "returns") || p.isIdent(0, "locals"):
+			t := p.advance()
+
+			if _, err := p.expect(antlrCharset, "", "declaration"); err != nil {
+				return err
+			}
+
+			p.grammar.warn(t.position, "%s of rule %q are ignored", t.text, name.text)
+		case p.isIdent(0, "throws"):
+			p.advance()
+
+			for {
+				if _, err := p.expect(antlrIdent, "", "exception name"); err != nil {
+					return err
+				}
+
+				if !p.isPunctuation(0, ",") {
+					break
+				}
+				p.advance()
+			}
+		case p.isIdent(0, "options") && p.peekToken(1).typ == antlrAction:
+			p.advance()
+			p.advance()
+		case p.isPunctuation(0, "@"):
+			if err := p.skipNamedAction(); err != nil {
+				return err
+			}
+		default:
+			break RULEPREQUEL
+		}
+	}
+
+	if err := p.expectPunctuation(":"); err != nil {
+		return err
+	}
+
+	e, err := p.parseAlternatives(name.text)
+	if err != nil {
+		return err
+	}
+
+	if err := p.expectPunctuation(";"); err != nil {
+		return err
+	}
+
+	for p.isIdent(0, "catch") {
+		p.advance()
+
+		if _, err := p.expect(antlrCharset, "", "exception"); err != nil {
+			return err
+		}
+		if _, err := p.expect(antlrAction, "", "action"); err != nil {
+			return err
+		}
+	}
+
+	if p.isIdent(0, "finally") {
+		p.advance()
+
+		if _, err := p.expect(antlrAction, "", "action"); err != nil {
+			return err
+		}
+	}
+
+	if p.grammar.rule(name.text) != nil {
+		return p.errorAt(name.position, "rule %q is already defined", name.text)
+	}
+
+	p.grammar.Rules = append(p.grammar.Rules, &Rule{
+		Name:       name.text,
+		Expression: e,
+		Position:   name.position,
+	})
+
+	return nil
+}
+
+func (p *antlrParser) parseAlternatives(rule string) (Expression, error) {
+	var items []Expression
+
+	for {
+		e, err := p.parseAlternative(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, e)
+
+		if !p.isPunctuation(0, "|") {
+			break
+		}
+		p.advance()
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+
+	return &Alternation{Items: items}, nil
+}
+
+func (p *antlrParser) parseAlternative(rule string) (Expression, error) {
+	var items []Expression
+
+	for {
+		if t := p.tok(); t.typ == antlrEOF || p.isPunctuation(0, "|") || p.isPunctuation(0, ")") || p.isPunctuation(0, ";") || p.isPunctuation(0, "->") || p.isPunctuation(0, "#") {
+			break
+		}
+
+		e, err := p.parseElement(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, e)
+	}
+
+	if p.isPunctuation(0, "#") {
+		p.advance()
+
+		if _, err := p.expect(antlrIdent, "", "alternative label"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isPunctuation(0, "->") {
+		p.advance()
+
+		if err := p.parseCommands(rule); err != nil {
+			return nil, err
+		}
+	}
+
+	switch len(items) {
+	case 0:
+		return &Epsilon{}, nil
+	case 1:
+		return items[0], nil
+	}
+
+	return &Concatenation{Items: items}, nil
+}
+
+func (p *antlrParser) parseCommands(rule string) error {
+	for {
+		command, err := p.expect(antlrIdent, "", "lexer command")
+		if err != nil {
+			return err
+		}
+
+		if p.isPunctuation(0, "(") {
+			p.advance()
+
+			if _, err := p.expect(antlrIdent, "", "lexer command argument"); err != nil {
+				return err
+			}
+
+			if err := p.expectPunctuation(")"); err != nil {
+				return err
+			}
+		}
+
+		switch command.text {
+		case "skip", "channel":
+			p.grammar.warn(command.position, "rule %q is skipped by ANTLR but is not inserted between other tokens", rule)
+		default:
+			p.grammar.warn(command.position, "lexer command %q of rule %q is ignored", command.text, rule)
+		}
+
+		if !p.isPunctuation(0, ",") {
+			return nil
+		}
+		p.advance()
+	}
+}
+
+// skipElementOptions skips options like <assoc=right>
+func (p *antlrParser) skipElementOptions() error {
+	if !p.isPunctuation(0, "<") {
+		return nil
+	}
+
+	for p.tok().typ != antlrEOF && !p.isPunctuation(0, ">") {
+		p.advance()
+	}
+
+	return p.expectPunctuation(">")
+}
+
+func (p *antlrParser) parseElement(rule string) (Expression, error) {
+	if err := p.skipElementOptions(); err != nil {
+		return nil, err
+	}
+
+	if t := p.tok(); t.typ == antlrAction {
+		p.advance()
+
+		if p.isPunctuation(0, "?") {
+			p.advance()
+
+			p.grammar.warn(t.position, "semantic predicate {%s}? of rule %q cannot be represented and is omitted", t.text, rule)
+		} else {
+			p.grammar.warn(t.position, "action of rule %q is ignored", rule)
+		}
+
+		return &Epsilon{}, nil
+	} else if t.typ == antlrIdent && (p.isPunctuation(1, "=") || p.isPunctuation(1, "+=")) {
+		// labels are irrelevant for the structure of the rule
+		p.advance()
+		p.advance()
+	}
+
+	e, err := p.parseAtom(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.skipElementOptions(); err != nil {
+		return nil, err
+	}
+
+	var min, max int
+
+	switch {
+	case p.isPunctuation(0, "?"):
+		min, max = 0, 1
+	case p.isPunctuation(0, "*"):
+		min, max = 0, -1
+	case p.isPunctuation(0, "+"):
+		min, max = 1, -1
+	default:
+		return e, nil
+	}
+	p.advance()
+
+	// non-greedy operators generate the same strings
+	if p.isPunctuation(0, "?") {
+		p.advance()
+	}
+
+	return &Repetition{
+		Item: e,
+		Min:  min,
+		Max:  max,
+	}, nil
+}
+
+func (p *antlrParser) parseAtom(rule string) (Expression, error) {
+	t := p.tok()
+
+	switch t.typ {
+	case antlrIdent:
+		p.advance()
+
+		if t.text == "EOF" {
+			return &Epsilon{}, nil
+		}
+
+		return &Reference{
+			Name:     t.text,
+			Position: t.position,
+		}, nil
+	case antlrString:
+		p.advance()
+
+		if !p.isPunctuation(0, "..") {
+			return &Literal{
+				Value: t.text,
+			}, nil
+		}
+		p.advance()
+
+		to, err := p.expect(antlrString, "", "string")
+		if err != nil {
+			return nil, err
+		}
+
+		from, end := []rune(t.text), []rune(to.text)
+		if len(from) != 1 || len(end) != 1 {
+			return nil, p.errorAt(t.position, "range bounds have to be single characters")
+		} else if end[0] < from[0] {
+			return nil, p.errorAt(t.position, "range end %q is lower than its start %q", end[0], from[0])
+		}
+
+		return &CharacterClass{
+			Ranges: []CharacterRange{
+				{
+					From: from[0],
+					To:   end[0],
+				},
+			},
+		}, nil
+	case antlrCharset:
+		p.advance()
+
+		return p.parseCharset(t, rule)
+	case antlrPunctuation:
+		switch t.text {
+		case ".":
+			p.advance()
+
+			return &CharacterClass{
+				Negated: true,
+			}, nil
+		case "~":
+			p.advance()
+
+			e, err := p.parseAtom(rule)
+			if err != nil {
+				return nil, err
+			}
+
+			c := negate(e)
+			if c == nil {
+				p.grammar.warn(t.position, "negation in rule %q can only be represented for character sets and is omitted", rule)
+
+				return &Epsilon{}, nil
+			}
+
+			return c, nil
+		case "(":
+			p.advance()
+
+			e, err := p.parseAlternatives(rule)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expectPunctuation(")"); err != nil {
+				return nil, err
+			}
+
+			return e, nil
+		}
+	}
+
+	return nil, p.errorAt(t.position, "expected element but got %s", t)
+}
+
+// parseCharset decodes the content of a character set like [a-z\n]
+func (p *antlrParser) parseCharset(t *antlrToken, rule string) (Expression, error) {
+	content := []rune(t.text)
+
+	var ranges []CharacterRange
+
+	character := func(i int) (rune, int, error) {
+		if content[i] != '\\' {
+			return content[i], i + 1, nil
+		}
+
+		i++
+		if i >= len(content) {
+			return 0, i, p.errorAt(t.position, "escape sequence is not terminated")
+		}
+
+		switch content[i] {
+		case 'n':
+			return '\n', i + 1, nil
+		case 'r':
+			return '\r', i + 1, nil
+		case 't':
+			return '\t', i + 1, nil
+		case 'b':
+			return '\b', i + 1, nil
+		case 'f':
+			return '\f', i + 1, nil
+		case 'u':
+			i++
+
+			var hex []rune
+			if i < len(content) && content[i] == '{' {
+				for i++; i < len(content) && content[i] != '}'; i++ {
+					hex = append(hex, content[i])
+				}
+				i++
+			} else {
+				for j := 0; j < 4 && i < len(content); j++ {
+					hex = append(hex, content[i])
+					i++
+				}
+			}
+
+			n, err := strconv.ParseUint(string(hex), 16, 32)
+			if err != nil {
+				return 0, i, p.errorAt(t.position, "invalid unicode escape sequence %q", string(hex))
+			}
+
+			return rune(n), i, nil
+		}
+
+		return content[i], i + 1, nil
+	}
+
+	for i := 0; i < len(content); {
+		if content[i] == '\\' && i+1 < len(content) && (content[i+1] == 'p' || content[i+1] == 'P') {
+			j := i + 2
+			if j < len(content) && content[j] == '{' {
+				for j < len(content) && content[j] != '}' {
+					j++
+				}
+
+				if j < len(content) {
+					j++
+				}
+			}
+
+			p.grammar.warn(t.position, "unicode property %s of rule %q is not supported and is omitted", string(content[i:j]), rule)
+
+			i = j
+
+			continue
+		}
+
+		from, next, err := character(i)
+		if err != nil {
+			return nil, err
+		}
+		i = next
+
+		to := from
+
+		if i+1 < len(content) && content[i] == '-' {
+			to, next, err = character(i + 1)
+			if err != nil {
+				return nil, err
+			} else if to < from {
+				return nil, p.errorAt(t.position, "range end %q is lower than its start %q", to, from)
+			}
+			i = next
+		}
+
+		ranges = append(ranges, CharacterRange{
+			From: from,
+			To:   to,
+		})
+	}
+
+	if len(ranges) == 0 {
+		return &Epsilon{}, nil
+	}
+
+	return &CharacterClass{
+		Ranges: ranges,
+	}, nil
+}
+
+// negate returns the negated character class of a set of characters or nil if the expression is not a set of characters
+func negate(e Expression) *CharacterClass {
+	switch t := e.(type) {
+	case *CharacterClass:
+		return &CharacterClass{
+			Ranges:  t.Ranges,
+			Negated: !t.Negated,
+		}
+	case *Literal:
+		value := []rune(t.Value)
+		if len(value) != 1 {
+			return nil
+		}
+
+		return &CharacterClass{
+			Ranges: []CharacterRange{
+				{
+					From: value[0],
+					To:   value[0],
+				},
+			},
+			Negated: true,
+		}
+	case *Alternation:
+		var ranges []CharacterRange
+
+		for _, i := range t.Items {
+			c := negate(i)
+			if c == nil || !c.Negated {
+				return nil
+			}
+
+			ranges = append(ranges, c.Ranges...)
+		}
+
+		return &CharacterClass{
+			Ranges:  ranges,
+			Negated: true,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+)
+
+func TestANTLR(t *testing.T) {
+	out, warnings := importGrammar(t, "antlr", `grammar Expr;
+
+options { language = Java; }
+@header { package expr; }
+
+prog : stat+ EOF ;
+stat : e=expr NEWLINE # print
+     | ID '=' expr NEWLINE {System.out.println("}");}
+     | NEWLINE
+     ;
+expr : <assoc=right> expr ('*'|'/') expr
+     | INT
+     | ID
+     | '(' expr ')'
+     ;
+
+/* lexer rules */
+fragment LETTER : [a-zA-Z_é] ;
+ID : LETTER (LETTER | '0'..'9')*? ;
+INT : [0-9]+ ;
+STR : '"' ~["\\\r\n]* '"' ;
+NEWLINE : '\r'? '\n' ;
+WS : [ \t]+ -> skip ;
+`, "")
+	Equal(t, `START = prog
+
+prog = +(stat)
+
+stat = expr NEWLINE | ID "=" expr NEWLINE | NEWLINE
+
+expr = expr ("*" | "/") expr | INT | ID | "(" expr ")"
+
+LETTER = [a-zA-Z\x5F\xE9]
+
+ID = LETTER *(LETTER | [0-9])
+
+INT = +([0-9])
+
+NEWLINE = ?("\r") "\n"
+`, out)
+	Equal(t, []string{
+		`L:8, C:28 - action of rule "stat" is ignored`,
+		`L:21, C:1 - rule "STR" is not reachable from the start rule "prog" and is omitted`,
+		`L:23, C:1 - rule "WS" is not reachable from the start rule "prog" and is omitted`,
+		`L:23, C:16 - rule "WS" is skipped by ANTLR but is not inserted between other tokens`,
+	}, warnings)
+
+	// negations and wildcards
+	out, warnings = importGrammar(t, "antlr", `lexer grammar Strings;
+STR : '"' (~["\\] | ~('\n' | '\r') | .)* {isValid()}? '"' -> pushMode(INSIDE) ;
+mode INSIDE;
+`, "")
+	Equal(t, `START = STR
+
+STR = "\"" *([^\x22\x5C] | [^\x0A\x0D] | [\d\D]) "\""
+`, out)
+	Equal(t, []string{
+		`L:2, C:42 - semantic predicate {isValid()}? of rule "STR" cannot be represented and is omitted`,
+		`L:2, C:62 - lexer command "pushMode" of rule "STR" is ignored`,
+		`L:3, C:1 - lexer mode "INSIDE" is not supported, its rules are imported as normal rules`,
+	}, warnings)
+}
+
+func TestANTLRErrors(t *testing.T) {
+	for _, src := range []string{
+		"grammar ;",
+		"rule 'a' ;",
+		"rule : 'a ;",
+		"rule : [a-z ;",
+		"rule : ( 'a' ;",
+		"rule : 'ab'..'z' ;",
+		"rule : {action ;",
+		"rule : 'a' ; rule : 'b' ;",
+		"/* comment",
+	} {
+		_, err := ParseANTLR(strings.NewReader(src))
+		NotNil(t, err, src)
+	}
+}
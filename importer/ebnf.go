@@ -0,0 +1,435 @@
+package importer
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register("ebnf", ParseEBNF)
+}
+
+type ebnfParser struct {
+	*source
+
+	grammar *Grammar
+}
+
+// ParseEBNF parses a grammar in the EBNF notation of the W3C which is for example used by the XML specification.
+// Production numbers and well-formedness or validity constraints are ignored.
+func ParseEBNF(src io.Reader) (*Grammar, error) {
+	s, err := newSource(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ebnfParser{
+		source:  s,
+		grammar: &Grammar{},
+	}
+
+	if err := p.parseRules(); err != nil {
+		return nil, err
+	}
+
+	return p.grammar, nil
+}
+
+// skipSpace skips white spaces, comments and constraints like [WFC: Element Type Match]
+func (p *ebnfParser) skipSpace() error {
+	for !p.eof() {
+		switch {
+		case p.peek(0) == ' ' || p.peek(0) == '\t' || p.peek(0) == '\r' || p.peek(0) == '\n':
+			p.next()
+		case p.peekString("/*"):
+			if err := p.skipBlockComment("*/"); err != nil {
+				return err
+			}
+		case p.isConstraint():
+			if err := p.skipBlockComment("]"); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (p *ebnfParser) isConstraint() bool {
+	if p.peek(0) != '[' {
+		return false
+	}
+
+	i := 1
+	for p.peek(i) == ' ' {
+		i++
+	}
+
+	var kind []rune
+	for isLetter(p.peek(i)) {
+		kind = append(kind, p.peek(i))
+		i++
+	}
+
+	k := strings.ToLower(string(kind))
+
+	return (k == "wfc" || k == "vc") && p.peek(i) == ':'
+}
+
+// isProductionNumber returns if the next characters are a production number like [12]
+func (p *ebnfParser) isProductionNumber() bool {
+	if p.peek(0) != '[' || !isDigit(p.peek(1)) {
+		return false
+	}
+
+	i := 1
+	for isDigit(p.peek(i)) {
+		i++
+	}
+
+	return p.peek(i) == ']'
+}
+
+// isRuleStart returns if the next characters start a new rule
+func (p *ebnfParser) isRuleStart() bool {
+	if p.isProductionNumber() {
+		return true
+	} else if !isLetter(p.peek(0)) {
+		return false
+	}
+
+	i := 0
+	for isEBNFNameCharacter(p.peek(i)) {
+		i++
+	}
+
+	for p.peek(i) == ' ' || p.peek(i) == '\t' || p.peek(i) == '\r' || p.peek(i) == '\n' {
+		i++
+	}
+
+	return p.peek(i) == ':' && p.peek(i+1) == ':' && p.peek(i+2) == '='
+}
+
+func isEBNFNameCharacter(c rune) bool {
+	return isLetter(c) || isDigit(c) || c == '_'
+}
+
+func (p *ebnfParser) parseName() string {
+	var name []rune
+
+	for isEBNFNameCharacter(p.peek(0)) {
+		name = append(name, p.next())
+	}
+
+	return string(name)
+}
+
+func (p *ebnfParser) parseRules() error {
+	for {
+		if err := p.skipSpace(); err != nil {
+			return err
+		}
+
+		if p.eof() {
+			return nil
+		}
+
+		if p.isProductionNumber() {
+			for p.next() != ']' {
+			}
+
+			if err := p.skipSpace(); err != nil {
+				return err
+			}
+		}
+
+		position := p.position()
+
+		if !isLetter(p.peek(0)) {
+			return p.errorf("expected rule name but got %s", p.describe())
+		}
+
+		name := p.parseName()
+
+		if err := p.skipSpace(); err != nil {
+			return err
+		}
+
+		if !p.peekString("::=") {
+			return p.errorf("expected \"::=\" but got %s", p.describe())
+		}
+		for range "::=" {
+			p.next()
+		}
+
+		e, err := p.parseAlternation()
+		if err != nil {
+			return err
+		}
+
+		if !p.eof() && !p.isRuleStart() {
+			return p.errorf("expected end of rule but got %s", p.describe())
+		}
+
+		if p.grammar.rule(name) != nil {
+			return p.errorAt(position, "rule %q is already defined", name)
+		}
+
+		p.grammar.Rules = append(p.grammar.Rules, &Rule{
+			Name:       name,
+			Expression: e,
+			Position:   position,
+		})
+	}
+}
+
+func (p *ebnfParser) parseAlternation() (Expression, error) {
+	var items []Expression
+
+	for {
+		e, err := p.parseSequence()
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, e)
+
+		if p.peek(0) != '|' {
+			break
+		}
+		p.next()
+	}
+
+	if len(items) == 1 {
+		return items[0], nil
+	}
+
+	return &Alternation{Items: items}, nil
+}
+
+func (p *ebnfParser) parseSequence() (Expression, error) {
+	var items []Expression
+
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+
+		if c := p.peek(0); p.eof() || c == '|' || c == ')' || p.isRuleStart() {
+			break
+		}
+
+		e, err := p.parseDifference()
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, e)
+	}
+
+	switch len(items) {
+	case 0:
+		return nil, p.errorf("expected expression but got %s", p.describe())
+	case 1:
+		return items[0], nil
+	}
+
+	return &Concatenation{Items: items}, nil
+}
+
+// parseDifference parses an expression like A - B. Only A can be represented in the Tavor format.
+func (p *ebnfParser) parseDifference() (Expression, error) {
+	e, err := p.parseItem()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+
+		if p.peek(0) != '-' {
+			return e, nil
+		}
+
+		position := p.position()
+		p.next()
+
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+
+		start := p.cur
+
+		if _, err := p.parseItem(); err != nil {
+			return nil, err
+		}
+
+		p.grammar.warn(position, "exception - %s cannot be represented and is ignored", strings.TrimSpace(string(p.data[start:p.cur])))
+	}
+}
+
+func (p *ebnfParser) parseItem() (Expression, error) {
+	e, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var min, max int
+
+		switch p.peek(0) {
+		case '?':
+			min, max = 0, 1
+		case '*':
+			min, max = 0, -1
+		case '+':
+			min, max = 1, -1
+		default:
+			return e, nil
+		}
+		p.next()
+
+		e = &Repetition{
+			Item: e,
+			Min:  min,
+			Max:  max,
+		}
+	}
+}
+
+func (p *ebnfParser) parsePrimary() (Expression, error) {
+	position := p.position()
+
+	switch c := p.peek(0); {
+	case isLetter(c):
+		return &Reference{
+			Name:     p.parseName(),
+			Position: position,
+		}, nil
+	case c == '#':
+		r, err := p.parseCharacterCode()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Literal{
+			Value: string(r),
+		}, nil
+	case c == '"' || c == '\'':
+		p.next()
+
+		var value []rune
+		for !p.eof() && p.peek(0) != c && p.peek(0) != '\n' {
+			value = append(value, p.next())
+		}
+
+		if p.peek(0) != c {
+			return nil, p.errorAt(position, "string is not terminated")
+		}
+		p.next()
+
+		return &Literal{
+			Value: string(value),
+		}, nil
+	case c == '[':
+		return p.parseCharacterClass()
+	case c == '(':
+		p.next()
+
+		e, err := p.parseAlternation()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek(0) != ')' {
+			return nil, p.errorf("expected \")\" but got %s", p.describe())
+		}
+		p.next()
+
+		return e, nil
+	}
+
+	return nil, p.errorf("expected expression but got %s", p.describe())
+}
+
+// parseCharacterCode parses a character code like #x20
+func (p *ebnfParser) parseCharacterCode() (rune, error) {
+	if !p.peekString("#x") {
+		return 0, p.errorf("expected character code but got %s", p.describe())
+	}
+	p.next()
+	p.next()
+
+	var hex []rune
+	for c := p.peek(0); isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'); c = p.peek(0) {
+		hex = append(hex, p.next())
+	}
+
+	n, err := strconv.ParseUint(string(hex), 16, 32)
+	if err != nil {
+		return 0, p.errorf("expected hexadecimal number but got %s", p.describe())
+	}
+
+	return rune(n), nil
+}
+
+// parseCharacterClass parses a character class like [a-zA-Z] or [^#x20-#x7F]
+func (p *ebnfParser) parseCharacterClass() (Expression, error) {
+	position := p.position()
+	p.next()
+
+	c := &CharacterClass{}
+
+	if p.peek(0) == '^' {
+		p.next()
+
+		c.Negated = true
+	}
+
+	character := func() (rune, error) {
+		if p.peekString("#x") {
+			return p.parseCharacterCode()
+		}
+
+		return p.next(), nil
+	}
+
+	for {
+		if p.eof() || p.peek(0) == '\n' {
+			return nil, p.errorAt(position, "character class is not terminated")
+		} else if p.peek(0) == ']' && len(c.Ranges) > 0 {
+			p.next()
+
+			break
+		}
+
+		from, err := character()
+		if err != nil {
+			return nil, err
+		}
+
+		to := from
+
+		if p.peek(0) == '-' && p.peek(1) != ']' {
+			p.next()
+
+			if to, err = character(); err != nil {
+				return nil, err
+			} else if to < from {
+				return nil, p.errorAt(position, "range end %q is lower than its start %q", to, from)
+			}
+		}
+
+		c.Ranges = append(c.Ranges, CharacterRange{
+			From: from,
+			To:   to,
+		})
+	}
+
+	return c, nil
+}
@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+)
+
+func TestEBNF(t *testing.T) {
+	out, warnings := importGrammar(t, "ebnf", `/* a subset of XML */
+[1] document ::= prolog element Misc*
+[2] Char     ::= #x9 | #xA | #xD | [#x20-#xD7FF] | [#x10000-#x10FFFF]
+[3] S        ::= (#x20 | #x9 | #xD | #xA)+
+    prolog   ::= '<?xml' S? "?>"
+    element  ::= '<' Name '>' CharData? '</' Name '>'
+                 [WFC: Element Type Match]
+    Name     ::= [a-zA-Z_:] [a-zA-Z0-9.-]*
+    CharData ::= [^<&]* - ([^<&]* ']]>' [^<&]*)
+    Misc     ::= Comment | S
+`, "")
+	Equal(t, `START = document
+
+document = prolog element *(Misc)
+
+S = +(" " | "\t" | "\r" | "\n")
+
+prolog = "<?xml" ?(S) "?>"
+
+element = "<" Name ">" ?(CharData) "</" Name ">"
+
+Name = [a-zA-Z\x5F\x3A] *([a-zA-Z0-9\x2E\x2D])
+
+CharData = *([^\x3C\x26])
+
+Misc = ?(S)
+`, out)
+	Equal(t, []string{
+		`L:3, C:5 - rule "Char" is not reachable from the start rule "document" and is omitted`,
+		`L:9, C:25 - exception - ([^<&]* ']]>' [^<&]*) cannot be represented and is ignored`,
+		`L:10, C:18 - rule "Comment" is not defined and is omitted`,
+	}, warnings)
+
+	// explicit start rule
+	out, _ = importGrammar(t, "ebnf", "a ::= 'a' b\nb ::= [#x20AC#x41-#x5A]\n", "b")
+	Equal(t, "START = b\n\nb = [\\x{20AC}A-Z]\n", out)
+}
+
+func TestEBNFErrors(t *testing.T) {
+	for _, src := range []string{
+		"a := 'a'",
+		"1 ::= 'a'",
+		"a ::= ",
+		"a ::= 'a",
+		"a ::= ('a'",
+		"a ::= [a-z",
+		"a ::= [z-a]",
+		"a ::= #xZ",
+		"a ::= 'a' )",
+		"a ::= 'a' a ::= 'b'",
+	} {
+		_, err := ParseEBNF(strings.NewReader(src))
+		NotNil(t, err, src)
+	}
+}
@@ -0,0 +1,172 @@
+// Package importer translates grammars of other notations like ABNF, ANTLR and W3C EBNF into the Tavor format
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/scanner"
+)
+
+// Importer parses a grammar of a specific notation
+// The error return argument is not nil, if the grammar is not syntactically correct.
+type Importer func(src io.Reader) (*Grammar, error)
+
+var importerLookup = make(map[string]Importer)
+
+// New returns an importer given its registered name.
+// The error return argument is not nil, if the name does not exist in the registered importer list.
+func New(name string) (Importer, error) {
+	imp, ok := importerLookup[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown importer %q", name)
+	}
+
+	return imp, nil
+}
+
+// List returns a list of all registered importer names.
+func List() []string {
+	keyImporterLookup := make([]string, 0, len(importerLookup))
+
+	for key := range importerLookup {
+		keyImporterLookup = append(keyImporterLookup, key)
+	}
+
+	sort.Strings(keyImporterLookup)
+
+	return keyImporterLookup
+}
+
+// Register registers an importer with the given name.
+func Register(name string, imp Importer) {
+	if imp == nil {
+		panic("register importer is nil")
+	}
+
+	if _, ok := importerLookup[name]; ok {
+		panic("importer " + name + " already registered")
+	}
+
+	importerLookup[name] = imp
+}
+
+// Grammar holds the rules of an imported grammar
+type Grammar struct {
+	// Rules holds the rules in the order of their definition. The first rule is the default start rule.
+	Rules []*Rule
+	// Warnings holds the constructs of the grammar which cannot be represented in the Tavor format
+	Warnings []*Warning
+
+	// caseInsensitive defines if rule names are case-insensitive like in ABNF
+	caseInsensitive bool
+}
+
+// Rule holds a named rule of a grammar
+type Rule struct {
+	Name       string
+	Expression Expression
+	Position   scanner.Position
+}
+
+// Warning holds a construct of a grammar which cannot be represented in the Tavor format
+type Warning struct {
+	Message  string
+	Position scanner.Position
+}
+
+func (w *Warning) String() string {
+	return fmt.Sprintf("L:%d, C:%d - %s", w.Position.Line, w.Position.Column, w.Message)
+}
+
+func (g *Grammar) warn(position scanner.Position, format string, args ...interface{}) {
+	g.Warnings = append(g.Warnings, &Warning{
+		Message:  fmt.Sprintf(format, args...),
+		Position: position,
+	})
+}
+
+// rule returns the rule with the given name or nil if there is no such rule
+func (g *Grammar) rule(name string) *Rule {
+	for _, r := range g.Rules {
+		if r.Name == name || (g.caseInsensitive && strings.EqualFold(r.Name, name)) {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// Expression defines a part of a grammar rule
+type Expression interface {
+	expression()
+}
+
+// Alternation holds expressions of which exactly one is chosen
+type Alternation struct {
+	Items []Expression
+}
+
+// Concatenation holds a sequence of expressions
+type Concatenation struct {
+	Items []Expression
+}
+
+// Repetition repeats its expression at least Min and at most Max times. A negative Max means that the repetition is unbounded.
+type Repetition struct {
+	Item     Expression
+	Min, Max int
+}
+
+// Literal holds a string
+type Literal struct {
+	Value           string
+	CaseInsensitive bool
+}
+
+// CharacterClass holds exactly one character of its ranges. A negated class holds one character which is not in its ranges.
+type CharacterClass struct {
+	Ranges  []CharacterRange
+	Negated bool
+}
+
+// CharacterRange holds all characters from From to To
+type CharacterRange struct {
+	From, To rune
+}
+
+// Reference references a rule by its name
+type Reference struct {
+	Name     string
+	Position scanner.Position
+}
+
+// Epsilon matches the empty string and is used for constructs which cannot be represented
+type Epsilon struct{}
+
+func (*Alternation) expression()    {}
+func (*Concatenation) expression()  {}
+func (*Repetition) expression()     {}
+func (*Literal) expression()        {}
+func (*CharacterClass) expression() {}
+func (*Reference) expression()      {}
+func (*Epsilon) expression()        {}
+
+// walk calls the given function for the expression and all its children
+func walk(e Expression, f func(e Expression)) {
+	f(e)
+
+	switch t := e.(type) {
+	case *Alternation:
+		for _, i := range t.Items {
+			walk(i, f)
+		}
+	case *Concatenation:
+		for _, i := range t.Items {
+			walk(i, f)
+		}
+	case *Repetition:
+		walk(t.Item, f)
+	}
+}
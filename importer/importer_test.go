@@ -0,0 +1,46 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/parser"
+)
+
+// importGrammar imports the grammar with the given importer and checks that the result is a valid format file
+func importGrammar(t *testing.T, name string, src string, start string) (string, []string) {
+	imp, err := New(name)
+	Nil(t, err)
+
+	g, err := imp(strings.NewReader(src))
+	Nil(t, err)
+
+	out, err := g.Tavor(start)
+	Nil(t, err)
+
+	_, err = parser.ParseTavor(strings.NewReader(out))
+	Nil(t, err, out)
+
+	var warnings []string
+	for _, w := range g.Warnings {
+		warnings = append(warnings, w.String())
+	}
+
+	return out, warnings
+}
+
+func TestImporterList(t *testing.T) {
+	Equal(t, []string{"abnf", "antlr", "ebnf"}, List())
+
+	_, err := New("yacc")
+	NotNil(t, err)
+
+	Panics(t, func() {
+		Register("abnf", ParseABNF)
+	})
+	Panics(t, func() {
+		Register("yacc", nil)
+	})
+}
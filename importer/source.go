@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"text/scanner"
+
+	"github.com/zimmski/tavor/token"
+)
+
+// source reads the characters of a grammar and keeps track of their position
+type source struct {
+	data []rune
+	cur  int
+
+	line, column int
+}
+
+func newSource(src io.Reader) (*source, error) {
+	data, err := ioutil.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &source{
+		data:   []rune(string(data)),
+		line:   1,
+		column: 1,
+	}, nil
+}
+
+func (s *source) eof() bool {
+	return s.cur >= len(s.data)
+}
+
+// peek returns the character with the given offset to the current character or -1 if there is no such character
+func (s *source) peek(offset int) rune {
+	if i := s.cur + offset; i < len(s.data) {
+		return s.data[i]
+	}
+
+	return -1
+}
+
+// peekString returns if the next characters are the given string
+func (s *source) peekString(str string) bool {
+	i := 0
+
+	for _, c := range str {
+		if s.peek(i) != c {
+			return false
+		}
+
+		i++
+	}
+
+	return true
+}
+
+func (s *source) next() rune {
+	c := s.data[s.cur]
+	s.cur++
+
+	if c == '\n' {
+		s.line++
+		s.column = 1
+	} else {
+		s.column++
+	}
+
+	return c
+}
+
+// skipLine skips all characters until the end of the current line
+func (s *source) skipLine() {
+	for !s.eof() && s.peek(0) != '\n' {
+		s.next()
+	}
+}
+
+// skipBlockComment skips all characters until and including the given end of a block comment
+func (s *source) skipBlockComment(end string) error {
+	position := s.position()
+
+	for !s.eof() {
+		if s.peekString(end) {
+			for range end {
+				s.next()
+			}
+
+			return nil
+		}
+
+		s.next()
+	}
+
+	return s.errorAt(position, "comment is not terminated")
+}
+
+func (s *source) position() scanner.Position {
+	return scanner.Position{
+		Line:   s.line,
+		Column: s.column,
+	}
+}
+
+func (s *source) errorf(format string, args ...interface{}) error {
+	return s.errorAt(s.position(), format, args...)
+}
+
+func (s *source) errorAt(position scanner.Position, format string, args ...interface{}) error {
+	return &token.ParserError{
+		Message:  fmt.Sprintf(format, args...),
+		Type:     token.ParseErrorUnexpectedData,
+		Position: position,
+	}
+}
+
+// describe returns a description of the current character for error messages
+func (s *source) describe() string {
+	if s.eof() {
+		return "EOF"
+	}
+
+	return fmt.Sprintf("%q", s.peek(0))
+}
+
+func isLetter(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,380 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Tavor translates the grammar into the Tavor format with the given start rule, or with the first rule of the grammar if start is empty.
+// Only rules which are reachable from the start rule are translated. References to undefined rules and rules without any representable content are removed. Every removal is added to the warnings of the grammar.
+func (g *Grammar) Tavor(start string) (string, error) {
+	if len(g.Rules) == 0 {
+		return "", errors.New("grammar has no rules")
+	}
+
+	startRule := g.Rules[0]
+	if start != "" {
+		startRule = g.rule(start)
+		if startRule == nil {
+			return "", fmt.Errorf("start rule %q is not defined", start)
+		}
+	}
+
+	w := &tavorWriter{
+		grammar:   g,
+		empty:     make(map[*Rule]bool),
+		undefined: make(map[string]bool),
+		names:     make(map[*Rule]string),
+	}
+
+	// rules are empty if they only consist of empty expressions or references to empty rules
+	for changed := true; changed; {
+		changed = false
+
+		for _, r := range g.Rules {
+			if !w.empty[r] {
+				if _, ok := w.simplify(r.Expression, false).(*Epsilon); ok {
+					w.empty[r] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	expressions := make(map[*Rule]Expression, len(g.Rules))
+	for _, r := range g.Rules {
+		if !w.empty[r] {
+			expressions[r] = w.simplify(r.Expression, true)
+		}
+	}
+
+	if w.empty[startRule] {
+		return "", fmt.Errorf("start rule %q does not hold anything which can be represented", startRule.Name)
+	}
+
+	reachable := map[*Rule]bool{
+		startRule: true,
+	}
+	queue := []*Rule{startRule}
+
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+
+		walk(expressions[r], func(e Expression) {
+			if ref, ok := e.(*Reference); ok {
+				if rr := g.rule(ref.Name); rr != nil && !reachable[rr] {
+					reachable[rr] = true
+					queue = append(queue, rr)
+				}
+			}
+		})
+	}
+
+	used := map[string]bool{
+		"START": true,
+	}
+
+	var rules []*Rule
+
+	for _, r := range g.Rules {
+		if !reachable[r] {
+			if !w.empty[r] {
+				g.warn(r.Position, "rule %q is not reachable from the start rule %q and is omitted", r.Name, startRule.Name)
+			}
+
+			continue
+		}
+
+		name := tavorName(r.Name)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s_%d", tavorName(r.Name), i)
+		}
+		used[name] = true
+
+		w.names[r] = name
+		rules = append(rules, r)
+	}
+
+	sort.SliceStable(g.Warnings, func(i, j int) bool {
+		a, b := g.Warnings[i].Position, g.Warnings[j].Position
+
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "START = %s\n", w.names[startRule])
+
+	for _, r := range rules {
+		fmt.Fprintf(&buf, "\n%s = %s\n", w.names[r], w.render(expressions[r], false))
+	}
+
+	return buf.String(), nil
+}
+
+type tavorWriter struct {
+	grammar *Grammar
+
+	empty     map[*Rule]bool
+	undefined map[string]bool
+	names     map[*Rule]string
+}
+
+// simplify removes empty expressions and flattens nested lists. Repetitions of optional expressions are transformed to optional repetitions since the Tavor format forbids repeating optional expressions.
+func (w *tavorWriter) simplify(e Expression, report bool) Expression {
+	switch t := e.(type) {
+	case *Alternation:
+		var items []Expression
+		optional := false
+
+		for _, i := range t.Items {
+			switch s := w.simplify(i, report).(type) {
+			case *Epsilon:
+				optional = true
+			case *Alternation:
+				items = append(items, s.Items...)
+			default:
+				items = append(items, s)
+			}
+		}
+
+		var r Expression
+
+		switch len(items) {
+		case 0:
+			return &Epsilon{}
+		case 1:
+			r = items[0]
+		default:
+			r = &Alternation{Items: items}
+		}
+
+		if optional {
+			return w.simplify(&Repetition{Item: r, Min: 0, Max: 1}, report)
+		}
+
+		return r
+	case *Concatenation:
+		var items []Expression
+
+		for _, i := range t.Items {
+			switch s := w.simplify(i, report).(type) {
+			case *Epsilon:
+			case *Concatenation:
+				items = append(items, s.Items...)
+			default:
+				items = append(items, s)
+			}
+		}
+
+		switch len(items) {
+		case 0:
+			return &Epsilon{}
+		case 1:
+			return items[0]
+		}
+
+		return &Concatenation{Items: items}
+	case *Repetition:
+		item := w.simplify(t.Item, report)
+
+		if _, ok := item.(*Epsilon); ok || t.Max == 0 {
+			return &Epsilon{}
+		}
+
+		min, max := t.Min, t.Max
+
+		if r, ok := item.(*Repetition); ok && r.Min == 0 {
+			if min == 0 && max == 1 {
+				// an optional of a repetition which can be empty is the repetition itself
+				return r
+			} else if r.Max == 1 {
+				item = r.Item
+				min = 0
+			}
+		}
+
+		if min == 1 && max == 1 {
+			return item
+		}
+
+		return &Repetition{Item: item, Min: min, Max: max}
+	case *Literal:
+		if t.Value == "" {
+			return &Epsilon{}
+		}
+	case *CharacterClass:
+		if !t.Negated && len(t.Ranges) == 0 {
+			return &Epsilon{}
+		}
+	case *Reference:
+		r := w.grammar.rule(t.Name)
+		if r == nil {
+			if report && !w.undefined[strings.ToLower(t.Name)] {
+				w.undefined[strings.ToLower(t.Name)] = true
+
+				w.grammar.warn(t.Position, "rule %q is not defined and is omitted", t.Name)
+			}
+
+			return &Epsilon{}
+		} else if w.empty[r] {
+			return &Epsilon{}
+		}
+	}
+
+	return e
+}
+
+// render returns the expression in the Tavor format. Alternations are grouped if they are part of a concatenation.
+func (w *tavorWriter) render(e Expression, grouped bool) string {
+	switch t := e.(type) {
+	case *Alternation:
+		items := make([]string, len(t.Items))
+		for i, item := range t.Items {
+			items[i] = w.render(item, false)
+		}
+
+		if grouped {
+			return "(" + strings.Join(items, " | ") + ")"
+		}
+
+		return strings.Join(items, " | ")
+	case *Concatenation:
+		items := make([]string, len(t.Items))
+		for i, item := range t.Items {
+			items[i] = w.render(item, true)
+		}
+
+		return strings.Join(items, " ")
+	case *Repetition:
+		body := "(" + w.render(t.Item, false) + ")"
+
+		switch {
+		case t.Min == 0 && t.Max == 1:
+			return "?" + body
+		case t.Min == 0 && t.Max < 0:
+			return "*" + body
+		case t.Min == 1 && t.Max < 0:
+			return "+" + body
+		case t.Max < 0:
+			return fmt.Sprintf("+%d,%s", t.Min, body)
+		case t.Min == t.Max:
+			return fmt.Sprintf("+%d%s", t.Min, body)
+		}
+
+		return fmt.Sprintf("+%d,%d%s", t.Min, t.Max, body)
+	case *Literal:
+		if !t.CaseInsensitive {
+			return strconv.Quote(t.Value)
+		}
+
+		return renderCaseInsensitive(t.Value)
+	case *CharacterClass:
+		if !t.Negated && len(t.Ranges) == 1 && t.Ranges[0].From == t.Ranges[0].To {
+			return strconv.Quote(string(t.Ranges[0].From))
+		} else if t.Negated && len(t.Ranges) == 0 {
+			// every character of the character universe
+			return `[\d\D]`
+		}
+
+		var buf bytes.Buffer
+
+		buf.WriteByte('[')
+		if t.Negated {
+			buf.WriteByte('^')
+		}
+
+		for _, r := range t.Ranges {
+			buf.WriteString(classCharacter(r.From))
+
+			if r.To != r.From {
+				buf.WriteByte('-')
+				buf.WriteString(classCharacter(r.To))
+			}
+		}
+
+		buf.WriteByte(']')
+
+		return buf.String()
+	case *Reference:
+		return w.names[w.grammar.rule(t.Name)]
+	}
+
+	panic(fmt.Sprintf("cannot render expression %#v", e))
+}
+
+// renderCaseInsensitive returns a concatenation which holds every ASCII letter of the string in lower and upper case
+func renderCaseInsensitive(s string) string {
+	var items []string
+	var text []rune
+
+	for _, c := range s {
+		lower, upper := unicode.ToLower(c), unicode.ToUpper(c)
+
+		if c > unicode.MaxASCII || lower == upper {
+			text = append(text, c)
+
+			continue
+		}
+
+		if len(text) > 0 {
+			items = append(items, strconv.Quote(string(text)))
+			text = text[:0]
+		}
+
+		items = append(items, "["+string(upper)+string(lower)+"]")
+	}
+
+	if len(text) > 0 {
+		items = append(items, strconv.Quote(string(text)))
+	}
+
+	return strings.Join(items, " ")
+}
+
+// classCharacter returns the character for a character class pattern. Every character besides ASCII letters and digits is escaped.
+func classCharacter(c rune) string {
+	if isLetter(c) || isDigit(c) {
+		return string(c)
+	} else if c <= 0xFF {
+		return fmt.Sprintf(`\x%02X`, c)
+	}
+
+	return fmt.Sprintf(`\x{%X}`, c)
+}
+
+// tavorName returns a valid Tavor token name for the given rule name. Invalid characters are replaced with underscores.
+func tavorName(name string) string {
+	var buf bytes.Buffer
+
+	for i, c := range name {
+		switch {
+		case isLetter(c):
+			buf.WriteRune(c)
+		case i == 0:
+			buf.WriteString("R")
+
+			if isDigit(c) {
+				buf.WriteRune(c)
+			} else {
+				buf.WriteByte('_')
+			}
+		case isDigit(c):
+			buf.WriteRune(c)
+		default:
+			buf.WriteByte('_')
+		}
+	}
+
+	if buf.Len() == 0 {
+		return "R"
+	}
+
+	return buf.String()
+}
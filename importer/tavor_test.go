@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"text/scanner"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/parser"
+)
+
+func TestTavor(t *testing.T) {
+	g := &Grammar{
+		Rules: []*Rule{
+			{
+				Name: "start-rule",
+				Expression: &Concatenation{
+					Items: []Expression{
+						&Reference{Name: "START"},
+						&Repetition{
+							Item: &Alternation{
+								Items: []Expression{
+									&Literal{Value: "a\"b"},
+									&Epsilon{},
+								},
+							},
+							Min: 1,
+							Max: -1,
+						},
+						&Repetition{
+							Item: &Reference{Name: "nothing"},
+							Min:  0,
+							Max:  1,
+						},
+						&Reference{Name: "undefined"},
+						&Literal{Value: "get", CaseInsensitive: true},
+						&Reference{Name: "1st"},
+					},
+				},
+			},
+			{
+				Name: "START",
+				Expression: &Alternation{
+					Items: []Expression{
+						&CharacterClass{Ranges: []CharacterRange{{From: '-', To: '-'}}},
+						&CharacterClass{Ranges: []CharacterRange{{From: 'a', To: 'z'}, {From: '.', To: '.'}, {From: 0x20AC, To: 0x20AC}}, Negated: true},
+						&CharacterClass{Negated: true},
+					},
+				},
+			},
+			{
+				Name:       "nothing",
+				Expression: &Reference{Name: "undefined"},
+			},
+			{
+				Name:       "1st",
+				Expression: &Repetition{Item: &Literal{Value: "x"}, Min: 2, Max: 5},
+			},
+			{
+				Name:       "unused",
+				Expression: &Literal{Value: "x"},
+				Position:   scanner.Position{Line: 9, Column: 1},
+			},
+		},
+	}
+
+	out, err := g.Tavor("")
+	Nil(t, err)
+	Equal(t, `START = start_rule
+
+start_rule = START_2 *("a\"b") [Gg] [Ee] [Tt] R1st
+
+START_2 = "-" | [^a-z\x2E\x{20AC}] | [\d\D]
+
+R1st = +2,5("x")
+`, out)
+
+	_, err = parser.ParseTavor(strings.NewReader(out))
+	Nil(t, err)
+
+	Equal(t, []*Warning{
+		{Message: `rule "undefined" is not defined and is omitted`},
+		{Message: `rule "unused" is not reachable from the start rule "start-rule" and is omitted`, Position: scanner.Position{Line: 9, Column: 1}},
+	}, g.Warnings)
+
+	// explicit start rule
+	g.Warnings = nil
+
+	out, err = g.Tavor("1st")
+	Nil(t, err)
+	Equal(t, "START = R1st\n\nR1st = +2,5(\"x\")\n", out)
+
+	_, err = g.Tavor("2nd")
+	NotNil(t, err)
+
+	_, err = g.Tavor("nothing")
+	NotNil(t, err)
+
+	_, err = (&Grammar{}).Tavor("")
+	NotNil(t, err)
+}
+
+func TestTavorName(t *testing.T) {
+	Equal(t, "rule_name", tavorName("rule-name"))
+	Equal(t, "R1st", tavorName("1st"))
+	Equal(t, "R_rule", tavorName("_rule"))
+	Equal(t, "R", tavorName(""))
+}
@@ -9,6 +9,7 @@ import (
 )
 
 var log = logrus.New()
+var logLevelLock sync.RWMutex
 var logIndentation int
 var logIndentationLock sync.RWMutex
 
@@ -19,8 +20,11 @@ func init() {
 
 // setting functions
 
-// Level sets the current log level
+// Level sets the current log level. The level can be set while other goroutines are logging.
 func Level(level logrus.Level) {
+	logLevelLock.Lock()
+	defer logLevelLock.Unlock()
+
 	log.Level = level
 }
 
@@ -78,120 +82,192 @@ func DecreaseIndentation() {
 
 // Debugf logs a message at level Debug on the standard logger.
 func Debugf(format string, args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Debugf(indentation()+format, args...)
 }
 
 // Infof logs a message at level Info on the standard logger.
 func Infof(format string, args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Infof(indentation()+format, args...)
 }
 
 // Printf logs a message at level Info on the standard logger.
 func Printf(format string, args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Printf(indentation()+format, args...)
 }
 
 // Warnf logs a message at level Warn on the standard logger.
 func Warnf(format string, args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Warnf(indentation()+format, args...)
 }
 
 // Warningf logs a message at level Warn on the standard logger.
 func Warningf(format string, args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Warnf(indentation()+format, args...)
 }
 
 // Errorf logs a message at level Error on the standard logger.
 func Errorf(format string, args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Errorf(indentation()+format, args...)
 }
 
 // Fatalf logs a message at level Fatal on the standard logger.
 func Fatalf(format string, args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Fatalf(indentation()+format, args...)
 }
 
 // Panicf logs a message at level Panic on the standard logger.
 func Panicf(format string, args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Panicf(indentation()+format, args...)
 }
 
 // Debug logs a message at level Debug on the standard logger.
 func Debug(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Debug(append([]interface{}{indentation()}, args...)...)
 }
 
 // Info logs a message at level Info on the standard logger.
 func Info(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Info(append([]interface{}{indentation()}, args...)...)
 }
 
 // Print logs a message at level Info on the standard logger.
 func Print(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Info(append([]interface{}{indentation()}, args...)...)
 }
 
 // Warn logs a message at level Warn on the standard logger.
 func Warn(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Warn(append([]interface{}{indentation()}, args...)...)
 }
 
 // Warning logs a message at level Warn on the standard logger.
 func Warning(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Warn(append([]interface{}{indentation()}, args...)...)
 }
 
 // Error logs a message at level Error on the standard logger.
 func Error(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Error(append([]interface{}{indentation()}, args...)...)
 }
 
 // Fatal logs a message at level Fatal on the standard logger.
 func Fatal(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Fatal(append([]interface{}{indentation()}, args...)...)
 }
 
 // Panic logs a message at level Panic on the standard logger.
 func Panic(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Panic(append([]interface{}{indentation()}, args...)...)
 }
 
 // Debugln logs a message at level Debug on the standard logger.
 func Debugln(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Debugln(append([]interface{}{indentation()}, args...)...)
 }
 
 // Infoln logs a message at level Info on the standard logger.
 func Infoln(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Infoln(append([]interface{}{indentation()}, args...)...)
 }
 
 // Println logs a message at level Info on the standard logger.
 func Println(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Println(append([]interface{}{indentation()}, args...)...)
 }
 
 // Warnln logs a message at level Warn on the standard logger.
 func Warnln(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Warnln(append([]interface{}{indentation()}, args...)...)
 }
 
 // Warningln logs a message at level Warn on the standard logger.
 func Warningln(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Warnln(append([]interface{}{indentation()}, args...)...)
 }
 
 // Errorln logs a message at level Error on the standard logger.
 func Errorln(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Errorln(append([]interface{}{indentation()}, args...)...)
 }
 
 // Fatalln logs a message at level Fatal on the standard logger.
 func Fatalln(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Fatalln(append([]interface{}{indentation()}, args...)...)
 }
 
 // Panicln logs a message at level Panic on the standard logger.
 func Panicln(args ...interface{}) {
+	logLevelLock.RLock()
+	defer logLevelLock.RUnlock()
+
 	log.Panicln(append([]interface{}{indentation()}, args...)...)
 }
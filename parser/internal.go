@@ -24,7 +24,7 @@ func ParseInternal(root token.Token, src io.Reader) []error {
 	p := &token.InternalParser{}
 
 	if d, err := ioutil.ReadAll(src); err == nil {
-		p.Data = string(d)
+		p.Data = d
 		p.DataLen = len(p.Data)
 	} else {
 		panic(err)
@@ -54,6 +54,13 @@ func ParseInternal(root token.Token, src io.Reader) []error {
 		}}
 	}
 
+	// tokens like checksums can only be checked after all tokens have been parsed
+	if err := token.VerifyTokens(root); err != nil {
+		log.Debugf("internal parsing failed %v", err)
+
+		return []error{err}
+	}
+
 	log.Debugf("finished internal parsing")
 
 	return nil
@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"encoding/binary"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"testing"
@@ -154,6 +156,24 @@ func TestInternalParse(t *testing.T) {
 	errs = ParseInternal(o, strings.NewReader(""))
 	Nil(t, errs)
 
+	for _, data := range []string{"A", "~", "0"} {
+		checkParse(
+			t,
+			primitives.NewCharacterClass(`^a-z`),
+			data,
+		)
+	}
+
+	errs = ParseInternal(primitives.NewCharacterClass(`^a-z`), strings.NewReader("b"))
+	Equal(t, len(errs), 1)
+	Equal(t, token.ParseErrorUnexpectedData, errs[0].(*token.ParserError).Type)
+
+	checkParse(
+		t,
+		primitives.NewCharacterClass(`\p{Greek}\D`),
+		"λ",
+	)
+
 	// All
 	checkParse(
 		t,
@@ -313,4 +333,40 @@ func TestInternalParse(t *testing.T) {
 		o,
 		"13232323232323333323232224",
 	)
+
+	// binary data
+	o = lists.NewConcatenation(
+		primitives.NewConstantString("\x89PNG"),
+		primitives.NewBinaryInt(2, binary.LittleEndian, 0, 1000),
+		lists.NewRepeat(primitives.NewBinaryInt(0, nil, 0, math.MaxUint32), 0, 5),
+	)
+
+	checkParse(
+		t,
+		o,
+		"\x89PNG\xe8\x03\xac\x02\x00\xff\xff\x03",
+	)
+
+	// conditions on variables
+	o, err := ParseTavor(strings.NewReader(`
+		START = Choose<var> "-" Print
+
+		Choose = 3 | 5 | 12 | 20
+
+		Print = {if var.Value > 10 && not (var.Value == 20)} "big" {else if var.Value >= 5} "mid" {else} "small" {endif}
+	`))
+	Nil(t, err)
+
+	for _, data := range []string{"3-small", "5-mid", "12-big", "20-mid"} {
+		checkParse(
+			t,
+			o,
+			data,
+		)
+	}
+
+	for _, data := range []string{"3-mid", "12-mid", "20-big"} {
+		errs = ParseInternal(o, strings.NewReader(data))
+		NotEqual(t, 0, len(errs), data)
+	}
 }
@@ -0,0 +1,674 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/zimmski/tavor"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/constraints"
+	"github.com/zimmski/tavor/token/lists"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+// jsonSchemaCharacters holds all characters of generated JSON strings which do not need to be escaped
+const jsonSchemaCharacters = `\x20-\x21\x23-\x5B\x5D-\x7E`
+
+// jsonObject holds the members of a JSON object in the order of their definition
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// MarshalJSON encodes the object with the original order of its members
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, key := range o.keys {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// decodeJSON decodes the next JSON value and keeps the order of object members
+func decodeJSON(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		o := &jsonObject{
+			values: make(map[string]interface{}),
+		}
+
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			key := k.(string)
+
+			v, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := o.values[key]; !ok {
+				o.keys = append(o.keys, key)
+			}
+			o.values[key] = v
+		}
+
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return o, nil
+	case json.Delim('['):
+		a := []interface{}{}
+
+		for dec.More() {
+			v, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			a = append(a, v)
+		}
+
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return a, nil
+	}
+
+	return t, nil
+}
+
+type jsonSchemaParser struct {
+	root interface{}
+
+	// resolving holds the references which are currently resolved to detect recursive references
+	resolving map[string]bool
+}
+
+// ParseJSONSchema reads and parses a JSON Schema document and returns the root of a token graph which generates and parses JSON data valid to the schema.
+// Objects are generated with their required properties in every order and optional properties which are enabled and disabled. Integers and numbers are generated as integers of their range, strings consist of printable ASCII characters.
+// Keywords which only restrict values like pattern and format are ignored. References have to be local and must not be recursive.
+func ParseJSONSchema(src io.Reader) (token.Token, error) {
+	dec := json.NewDecoder(src)
+	dec.UseNumber()
+
+	root, err := decodeJSON(dec)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode JSON schema: %v", err)
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("cannot decode JSON schema: expected EOF after the schema")
+	}
+
+	p := &jsonSchemaParser{
+		root:      root,
+		resolving: make(map[string]bool),
+	}
+
+	return p.parseSchema(root, "#")
+}
+
+func (p *jsonSchemaParser) parseSchema(schema interface{}, path string) (token.Token, error) {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			return nil, fmt.Errorf("%s: schema false does not allow any value", path)
+		}
+
+		return p.any(), nil
+	case *jsonObject:
+		return p.parseSchemaObject(s, path)
+	}
+
+	return nil, fmt.Errorf("%s: schema has to be an object or a boolean", path)
+}
+
+func (p *jsonSchemaParser) parseSchemaObject(schema *jsonObject, path string) (token.Token, error) {
+	if ref, ok := schema.values["$ref"]; ok {
+		r, ok := ref.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s/$ref: reference has to be a string", path)
+		}
+
+		return p.parseReference(r, path+"/$ref")
+	}
+
+	if c, ok := schema.values["const"]; ok {
+		return jsonConstant(c)
+	}
+
+	if e, ok := schema.values["enum"]; ok {
+		values, ok := e.([]interface{})
+		if !ok || len(values) == 0 {
+			return nil, fmt.Errorf("%s/enum: enum has to be a non-empty array", path)
+		}
+
+		toks := make([]token.Token, len(values))
+		for i, v := range values {
+			tok, err := jsonConstant(v)
+			if err != nil {
+				return nil, err
+			}
+
+			toks[i] = tok
+		}
+
+		return lists.NewOne(toks...), nil
+	}
+
+	if a, ok := schema.values["allOf"]; ok {
+		schemas, ok := a.([]interface{})
+		if !ok || len(schemas) != 1 {
+			return nil, fmt.Errorf("%s/allOf: only allOf with exactly one schema is supported", path)
+		}
+
+		return p.parseSchema(schemas[0], path+"/allOf/0")
+	}
+
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		if a, ok := schema.values[keyword]; ok {
+			schemas, ok := a.([]interface{})
+			if !ok || len(schemas) == 0 {
+				return nil, fmt.Errorf("%s/%s: %s has to be a non-empty array", path, keyword, keyword)
+			}
+
+			toks := make([]token.Token, len(schemas))
+			for i, s := range schemas {
+				tok, err := p.parseSchema(s, fmt.Sprintf("%s/%s/%d", path, keyword, i))
+				if err != nil {
+					return nil, err
+				}
+
+				toks[i] = tok
+			}
+
+			return lists.NewOne(toks...), nil
+		}
+	}
+
+	switch t := schema.values["type"].(type) {
+	case string:
+		return p.parseType(schema, t, path)
+	case []interface{}:
+		if len(t) == 0 {
+			return nil, fmt.Errorf("%s/type: type has to be a non-empty array", path)
+		}
+
+		toks := make([]token.Token, len(t))
+		for i, typ := range t {
+			name, ok := typ.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s/type/%d: type has to be a string", path, i)
+			}
+
+			tok, err := p.parseType(schema, name, path)
+			if err != nil {
+				return nil, err
+			}
+
+			toks[i] = tok
+		}
+
+		return lists.NewOne(toks...), nil
+	case nil:
+		// the type is defined by the keywords of the schema
+		for _, k := range []struct {
+			typ      string
+			keywords []string
+		}{
+			{"object", []string{"properties", "required"}},
+			{"array", []string{"items", "minItems", "maxItems"}},
+			{"integer", []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf"}},
+			{"string", []string{"minLength", "maxLength"}},
+		} {
+			for _, keyword := range k.keywords {
+				if _, ok := schema.values[keyword]; ok {
+					return p.parseType(schema, k.typ, path)
+				}
+			}
+		}
+
+		return p.any(), nil
+	}
+
+	return nil, fmt.Errorf("%s/type: type has to be a string or an array", path)
+}
+
+// parseReference resolves a local reference like #/definitions/address
+func (p *jsonSchemaParser) parseReference(ref string, path string) (token.Token, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("%s: only local references are supported but got %q", path, ref)
+	}
+	if p.resolving[ref] {
+		return nil, fmt.Errorf("%s: recursive reference %q is not supported", path, ref)
+	}
+
+	schema := p.root
+
+	if pointer := ref[1:]; pointer != "" {
+		if !strings.HasPrefix(pointer, "/") {
+			return nil, fmt.Errorf("%s: invalid reference %q", path, ref)
+		}
+
+		for _, part := range strings.Split(pointer[1:], "/") {
+			part = strings.Replace(strings.Replace(part, "~1", "/", -1), "~0", "~", -1)
+
+			switch s := schema.(type) {
+			case *jsonObject:
+				v, ok := s.values[part]
+				if !ok {
+					return nil, fmt.Errorf("%s: reference %q cannot be resolved", path, ref)
+				}
+
+				schema = v
+			case []interface{}:
+				i, err := strconv.Atoi(part)
+				if err != nil || i < 0 || i >= len(s) {
+					return nil, fmt.Errorf("%s: reference %q cannot be resolved", path, ref)
+				}
+
+				schema = s[i]
+			default:
+				return nil, fmt.Errorf("%s: reference %q cannot be resolved", path, ref)
+			}
+		}
+	}
+
+	p.resolving[ref] = true
+	defer delete(p.resolving, ref)
+
+	return p.parseSchema(schema, ref)
+}
+
+func (p *jsonSchemaParser) parseType(schema *jsonObject, typ string, path string) (token.Token, error) {
+	switch typ {
+	case "null":
+		return primitives.NewConstantString("null"), nil
+	case "boolean":
+		return jsonBoolean(), nil
+	case "integer", "number":
+		return p.parseInteger(schema, path)
+	case "string":
+		min, max, err := jsonRange(schema, "minLength", "maxLength", path)
+		if err != nil {
+			return nil, err
+		}
+
+		return jsonString(min, max), nil
+	case "array":
+		return p.parseArray(schema, path)
+	case "object":
+		return p.parseObject(schema, path)
+	}
+
+	return nil, fmt.Errorf("%s/type: unknown type %q", path, typ)
+}
+
+// parseInteger returns a range of integers. Numbers are generated as integers since they are always valid numbers.
+func (p *jsonSchemaParser) parseInteger(schema *jsonObject, path string) (token.Token, error) {
+	number := func(keyword string) (float64, bool, error) {
+		v, ok := schema.values[keyword]
+		if !ok {
+			return 0, false, nil
+		} else if _, ok := v.(bool); ok && strings.HasPrefix(keyword, "exclusive") {
+			// exclusive bounds of draft 4 are booleans which modify the inclusive bounds
+			return 0, false, nil
+		}
+
+		n, ok := v.(json.Number)
+		if !ok {
+			return 0, false, fmt.Errorf("%s/%s: %s has to be a number", path, keyword, keyword)
+		}
+
+		f, err := n.Float64()
+		if err != nil {
+			return 0, false, fmt.Errorf("%s/%s: %v", path, keyword, err)
+		}
+
+		return f, true, nil
+	}
+
+	var lower, upper []int
+
+	for _, b := range []struct {
+		keyword   string
+		exclusive string
+		lower     bool
+	}{
+		{"minimum", "exclusiveMinimum", true},
+		{"maximum", "exclusiveMaximum", false},
+		{"exclusiveMinimum", "exclusiveMinimum", true},
+		{"exclusiveMaximum", "exclusiveMaximum", false},
+	} {
+		f, ok, err := number(b.keyword)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
+		exclusive := b.keyword == b.exclusive || schema.values[b.exclusive] == true
+
+		if b.lower {
+			i := int(math.Ceil(f))
+			if exclusive && float64(i) == f {
+				i++
+			}
+
+			lower = append(lower, i)
+		} else {
+			i := int(math.Floor(f))
+			if exclusive && float64(i) == f {
+				i--
+			}
+
+			upper = append(upper, i)
+		}
+	}
+
+	from, to := -math.MaxInt32, math.MaxInt32
+
+	for i, v := range lower {
+		if i == 0 || v > from {
+			from = v
+		}
+	}
+	for i, v := range upper {
+		if i == 0 || v < to {
+			to = v
+		}
+	}
+
+	// ranges with only one bound are extended from the bound
+	if len(lower) > 0 && len(upper) == 0 && from > 0 {
+		to = from + math.MaxInt32
+	} else if len(upper) > 0 && len(lower) == 0 && to < 0 {
+		from = to - math.MaxInt32
+	}
+
+	step := 1
+
+	if v, ok := schema.values["multipleOf"]; ok {
+		n, ok := v.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("%s/multipleOf: multipleOf has to be a number", path)
+		}
+
+		i, err := n.Int64()
+		if err != nil || i < 1 {
+			return nil, fmt.Errorf("%s/multipleOf: only positive integers are supported as multipleOf", path)
+		}
+
+		step = int(i)
+
+		// the range has to start with a multiple
+		if m := from % step; m > 0 {
+			from += step - m
+		} else if m < 0 {
+			from -= m
+		}
+	}
+
+	if from > to {
+		return nil, fmt.Errorf("%s: there is no integer in the range of the schema", path)
+	}
+
+	return primitives.NewRangeIntWithStep(from, to, step), nil
+}
+
+// parseArray returns a list of the items of the array separated by commas
+func (p *jsonSchemaParser) parseArray(schema *jsonObject, path string) (token.Token, error) {
+	if tuple, ok := schema.values["items"].([]interface{}); ok {
+		toks := []token.Token{
+			primitives.NewConstantString("["),
+		}
+
+		for i, s := range tuple {
+			if i != 0 {
+				toks = append(toks, primitives.NewConstantString(","))
+			}
+
+			tok, err := p.parseSchema(s, fmt.Sprintf("%s/items/%d", path, i))
+			if err != nil {
+				return nil, err
+			}
+
+			toks = append(toks, tok)
+		}
+
+		toks = append(toks, primitives.NewConstantString("]"))
+
+		return lists.NewConcatenation(toks...), nil
+	}
+
+	item := p.any()
+
+	if s, ok := schema.values["items"]; ok {
+		var err error
+		if item, err = p.parseSchema(s, path+"/items"); err != nil {
+			return nil, err
+		}
+	}
+
+	min, max, err := jsonRange(schema, "minItems", "maxItems", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if max == 0 {
+		return primitives.NewConstantString("[]"), nil
+	}
+
+	var items token.Token = item
+
+	if max > 1 {
+		from := min - 1
+		if from < 0 {
+			from = 0
+		}
+
+		items = lists.NewConcatenation(
+			item,
+			lists.NewRepeat(lists.NewConcatenation(
+				primitives.NewConstantString(","),
+				item.Clone(),
+			), int64(from), int64(max-1)),
+		)
+	}
+
+	if min == 0 {
+		items = constraints.NewOptional(items)
+	}
+
+	return lists.NewConcatenation(
+		primitives.NewConstantString("["),
+		items,
+		primitives.NewConstantString("]"),
+	), nil
+}
+
+// parseObject returns the properties of the object in every order separated by commas. Optional properties which are not present are not separated.
+func (p *jsonSchemaParser) parseObject(schema *jsonObject, path string) (token.Token, error) {
+	var names []string
+	properties := make(map[string]token.Token)
+
+	if v, ok := schema.values["properties"]; ok {
+		props, ok := v.(*jsonObject)
+		if !ok {
+			return nil, fmt.Errorf("%s/properties: properties has to be an object", path)
+		}
+
+		for _, name := range props.keys {
+			tok, err := p.parseSchema(props.values[name], path+"/properties/"+strings.Replace(strings.Replace(name, "~", "~0", -1), "/", "~1", -1))
+			if err != nil {
+				return nil, err
+			}
+
+			names = append(names, name)
+			properties[name] = tok
+		}
+	}
+
+	isRequired := make(map[string]bool)
+
+	if v, ok := schema.values["required"]; ok {
+		r, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s/required: required has to be an array", path)
+		}
+
+		for i, n := range r {
+			name, ok := n.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s/required/%d: required property has to be a string", path, i)
+			}
+
+			if _, ok := properties[name]; !ok {
+				names = append(names, name)
+				properties[name] = p.any()
+			}
+
+			isRequired[name] = true
+		}
+	}
+
+	if len(names) == 0 {
+		return primitives.NewConstantString("{}"), nil
+	}
+
+	members := make([]token.Token, len(names))
+	for i, name := range names {
+		key, _ := json.Marshal(name)
+
+		members[i] = lists.NewConcatenation(primitives.NewConstantString(string(key)+":"), properties[name])
+		if !isRequired[name] {
+			members[i] = constraints.NewOptional(members[i])
+		}
+	}
+
+	return lists.NewConcatenation(
+		primitives.NewConstantString("{"),
+		lists.NewOnceWithSeparator(",", members...),
+		primitives.NewConstantString("}"),
+	), nil
+}
+
+// any returns a token which generates any JSON scalar
+func (p *jsonSchemaParser) any() token.Token {
+	return lists.NewOne(
+		primitives.NewConstantString("null"),
+		jsonBoolean(),
+		primitives.NewRangeInt(-math.MaxInt32, math.MaxInt32),
+		jsonString(0, tavor.MaxRepeat),
+	)
+}
+
+// jsonRange returns the values of the given minimum and maximum keywords. The maximum defaults to the maximum repeat but at least the minimum.
+func jsonRange(schema *jsonObject, minKeyword string, maxKeyword string, path string) (int, int, error) {
+	get := func(keyword string, value int) (int, error) {
+		v, ok := schema.values[keyword]
+		if !ok {
+			return value, nil
+		}
+
+		n, ok := v.(json.Number)
+		if !ok {
+			return 0, fmt.Errorf("%s/%s: %s has to be a non-negative integer", path, keyword, keyword)
+		}
+
+		i, err := n.Int64()
+		if err != nil || i < 0 {
+			return 0, fmt.Errorf("%s/%s: %s has to be a non-negative integer", path, keyword, keyword)
+		}
+
+		return int(i), nil
+	}
+
+	min, err := get(minKeyword, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	max := tavor.MaxRepeat
+	if max < min {
+		max = min
+	}
+
+	if max, err = get(maxKeyword, max); err != nil {
+		return 0, 0, err
+	} else if max < min {
+		return 0, 0, fmt.Errorf("%s/%s: %s is lower than %s", path, maxKeyword, maxKeyword, minKeyword)
+	}
+
+	return min, max, nil
+}
+
+// jsonConstant returns the encoded JSON value
+func jsonConstant(value interface{}) (token.Token, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return primitives.NewConstantString(string(data)), nil
+}
+
+func jsonBoolean() token.Token {
+	return lists.NewOne(
+		primitives.NewConstantString("true"),
+		primitives.NewConstantString("false"),
+	)
+}
+
+// jsonString returns a quoted string with the given length range
+func jsonString(min int, max int) token.Token {
+	var characters token.Token = primitives.NewCharacterClass(jsonSchemaCharacters)
+
+	if min != 1 || max != 1 {
+		characters = lists.NewRepeat(characters, int64(min), int64(max))
+	}
+
+	return lists.NewConcatenation(
+		primitives.NewConstantString(`"`),
+		characters,
+		primitives.NewConstantString(`"`),
+	)
+}
@@ -0,0 +1,217 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/fuzz/strategy"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/lists"
+)
+
+func parseJSONSchemaTest(t *testing.T, schema string) token.Token {
+	tok, err := ParseJSONSchema(strings.NewReader(schema))
+	Nil(t, err, schema)
+
+	return tok
+}
+
+func TestJSONSchemaObject(t *testing.T) {
+	tok := parseJSONSchemaTest(t, `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 1, "maximum": 100},
+			"name": {"type": "string", "maxLength": 3},
+			"tags": {"type": "array", "items": {"enum": ["a", "b"]}, "maxItems": 2}
+		},
+		"required": ["name", "id"]
+	}`)
+	Equal(t, `{"id":1,"name":"","tags":["a"]}`, tok.String())
+
+	// properties are permutated with lists.Once
+	found := false
+	Nil(t, token.Walk(tok, func(tok token.Token) error {
+		if _, ok := tok.(*lists.Once); ok {
+			found = true
+		}
+
+		return nil
+	}))
+	True(t, found)
+
+	for _, data := range []string{
+		`{"id":1,"name":""}`,
+		`{"name":"abc","id":100}`,
+		`{"id":5,"tags":["a","b"],"name":"x"}`,
+		`{"tags":[],"name":"x","id":7}`,
+	} {
+		Nil(t, ParseInternal(tok, strings.NewReader(data)), data)
+	}
+
+	for _, data := range []string{
+		`{"id":1}`,
+		`{"id":0,"name":""}`,
+		`{"id":1,"name":"abcd"}`,
+		`{"id":1,"name":"","tags":["c"]}`,
+		`{"id":1,"name":"","name":""}`,
+	} {
+		NotNil(t, ParseInternal(tok, strings.NewReader(data)), data)
+	}
+
+	// without required properties every property can be the first one
+	tok = parseJSONSchemaTest(t, `{
+		"properties": {
+			"a": {"type": "null"},
+			"b": {"type": "boolean"}
+		}
+	}`)
+	Equal(t, `{"a":null,"b":true}`, tok.String())
+
+	for _, data := range []string{`{}`, `{"a":null}`, `{"b":true}`, `{"b":false,"a":null}`} {
+		Nil(t, ParseInternal(tok, strings.NewReader(data)), data)
+	}
+
+	Equal(t, "{}", parseJSONSchemaTest(t, `{"type": "object"}`).String())
+}
+
+func TestJSONSchemaValues(t *testing.T) {
+	for _, tc := range []struct {
+		schema  string
+		valid   []string
+		invalid []string
+	}{
+		{`{"const": {"b": [1, 2.5], "a": "x"}}`, []string{`{"b":[1,2.5],"a":"x"}`}, []string{`{"a":"x","b":[1,2.5]}`}},
+		{`{"enum": ["red", 1, null]}`, []string{`"red"`, `1`, `null`}, []string{`"blue"`}},
+		{`{"type": ["string", "null"], "minLength": 1}`, []string{`"a"`, `null`}, []string{`""`, `1`}},
+		{`{"oneOf": [{"type": "boolean"}, {"const": 0}]}`, []string{`true`, `false`, `0`}, []string{`1`}},
+		{`{"type": "integer", "minimum": -10, "exclusiveMaximum": 10}`, []string{`-10`, `0`, `9`}, []string{`-11`, `10`}},
+		{`{"type": "number", "minimum": 0.5, "maximum": 2.5}`, []string{`1`, `2`}, []string{`0`, `3`}},
+		{`{"type": "integer", "minimum": 5, "exclusiveMinimum": true, "multipleOf": 3}`, []string{`6`, `9`}, []string{`5`, `7`}},
+		{`{"maximum": -5}`, []string{`-5`, `-100`}, []string{`-4`, `0`}},
+		{`{"type": "integer"}`, []string{`-2147483647`, `0`, `2147483647`}, []string{`-2147483648`, `2147483648`}},
+		{`{"type": "array", "items": {"type": "integer", "maximum": 9}, "minItems": 2, "maxItems": 3}`, []string{`[1,2]`, `[1,2,3]`}, []string{`[]`, `[1]`, `[1,2,3,4]`}},
+		{`{"type": "array", "items": [{"type": "null"}, {"type": "boolean"}]}`, []string{`[null,true]`}, []string{`[null]`, `[true,null]`}},
+		{`{"type": "array", "maxItems": 0}`, []string{`[]`}, []string{`[1]`}},
+		{`{"definitions": {"id": {"type": "string", "maxLength": 1}}, "type": "array", "items": {"$ref": "#/definitions/id"}}`, []string{`["a","b"]`}, []string{`[1]`}},
+		{`true`, []string{`null`, `true`, `1`, `"a"`}, nil},
+	} {
+		tok := parseJSONSchemaTest(t, tc.schema)
+
+		for _, data := range tc.valid {
+			Nil(t, ParseInternal(tok, strings.NewReader(data)), tc.schema+" "+data)
+		}
+		for _, data := range tc.invalid {
+			NotNil(t, ParseInternal(tok, strings.NewReader(data)), tc.schema+" "+data)
+		}
+	}
+}
+
+func TestJSONSchemaGeneration(t *testing.T) {
+	tok := parseJSONSchemaTest(t, `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": -5},
+			"name": {"type": "string"},
+			"address": {
+				"type": "object",
+				"properties": {
+					"street": {"type": "string"},
+					"zip": {"type": "integer", "maximum": 99999}
+				}
+			},
+			"flags": {"type": "array", "items": {"type": "boolean"}}
+		},
+		"required": ["id"]
+	}`)
+
+	for seed := int64(0); seed < 100; seed++ {
+		ch, err := strategy.NewRandom(tok, rand.New(rand.NewSource(seed)))
+		Nil(t, err)
+
+		_, ok := <-ch
+		True(t, ok)
+
+		data := tok.String()
+		True(t, json.Valid([]byte(data)), data)
+		Nil(t, ParseInternal(tok.Clone(), strings.NewReader(data)), data)
+
+		ch <- struct{}{}
+
+		_, ok = <-ch
+		False(t, ok)
+	}
+}
+
+func TestJSONSchemaLargeObject(t *testing.T) {
+	var properties []string
+	for i := 0; i < 30; i++ {
+		properties = append(properties, fmt.Sprintf(`"p%d": {"type": "integer"}`, i))
+	}
+
+	tok := parseJSONSchemaTest(t, `{
+		"type": "object",
+		"properties": {`+strings.Join(properties, ",")+`},
+		"required": ["p0", "p10", "p20"]
+	}`)
+
+	// every property is only once in the token graph
+	count := 0
+	Nil(t, token.WalkInternal(tok, func(tok token.Token) error {
+		count++
+
+		return nil
+	}))
+	True(t, count < 10*len(properties), count)
+
+	Equal(t, token.MaxPermutations, tok.PermutationsAll())
+
+	for seed := int64(0); seed < 10; seed++ {
+		ch, err := strategy.NewRandom(tok, rand.New(rand.NewSource(seed)))
+		Nil(t, err)
+
+		_, ok := <-ch
+		True(t, ok)
+
+		data := tok.String()
+		True(t, json.Valid([]byte(data)), data)
+		Nil(t, ParseInternal(tok.Clone(), strings.NewReader(data)), data)
+
+		ch <- struct{}{}
+
+		_, ok = <-ch
+		False(t, ok)
+	}
+}
+
+func TestJSONSchemaErrors(t *testing.T) {
+	for _, schema := range []string{
+		``,
+		`{`,
+		`{} {}`,
+		`false`,
+		`1`,
+		`{"type": "date"}`,
+		`{"type": []}`,
+		`{"type": 1}`,
+		`{"enum": []}`,
+		`{"allOf": [{}, {}]}`,
+		`{"anyOf": {}}`,
+		`{"$ref": "http://example.com/schema"}`,
+		`{"$ref": "#/definitions/missing"}`,
+		`{"definitions": {"a": {"$ref": "#/definitions/a"}}, "$ref": "#/definitions/a"}`,
+		`{"type": "integer", "minimum": 10, "maximum": 5}`,
+		`{"type": "integer", "multipleOf": 0.5}`,
+		`{"type": "string", "minLength": -1}`,
+		`{"type": "array", "minItems": 3, "maxItems": 2}`,
+		`{"type": "object", "required": "id"}`,
+		`{"type": "object", "properties": {"a": false}}`,
+	} {
+		_, err := ParseJSONSchema(strings.NewReader(schema))
+		NotNil(t, err, schema)
+	}
+}
@@ -2,22 +2,28 @@ package parser
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 	"text/scanner"
 
 	"github.com/zimmski/container/list/linkedlist"
 
 	"github.com/zimmski/tavor"
+	"github.com/zimmski/tavor/encoding"
 	"github.com/zimmski/tavor/log"
 	"github.com/zimmski/tavor/token"
 	"github.com/zimmski/tavor/token/aggregates"
 	"github.com/zimmski/tavor/token/conditions"
 	"github.com/zimmski/tavor/token/constraints"
 	"github.com/zimmski/tavor/token/expressions"
+	"github.com/zimmski/tavor/token/filters"
 	"github.com/zimmski/tavor/token/lists"
 	"github.com/zimmski/tavor/token/primitives"
 	"github.com/zimmski/tavor/token/sequences"
@@ -26,6 +32,9 @@ import (
 
 const zeroRune = 0
 
+// maxTemplateDepth is the maximum depth of instances of parameterised token definitions which are instantiated by other instances
+const maxTemplateDepth = 64
+
 type tokenUsage struct {
 	token          token.Token
 	position       scanner.Position
@@ -41,10 +50,39 @@ type attributeForwardUsage struct {
 	attributePosition scanner.Position
 	operator          string
 	operatorToken     token.Token
+	function          *tokenFunction
 	pointer           *primitives.Pointer
 	variableScope     *token.VariableScope
 }
 
+type tokenFunction struct {
+	name     string
+	encoding string
+}
+
+// tokenTemplate holds a parameterised token definition like List(X) = "(" X *("," X) ")"
+type tokenTemplate struct {
+	name         string
+	parameters   []string
+	position     scanner.Position
+	body         string
+	bodyPosition scanner.Position
+	used         bool
+}
+
+// templateArgument holds the token name of an argument and the position of the argument in the usage of the template
+type templateArgument struct {
+	name     string
+	position scanner.Position
+}
+
+type templateInstance struct {
+	name      string
+	template  *tokenTemplate
+	arguments map[string]templateArgument
+	depth     int
+}
+
 type call struct {
 	from          string
 	variableScope *token.VariableScope
@@ -64,6 +102,21 @@ type tavorParser struct {
 	called map[string][]call
 
 	forwardAttributeUsage []attributeForwardUsage
+
+	templates         map[string]*tokenTemplate
+	templateInstances []*templateInstance
+	instances         map[string]struct{}
+	// bindings maps the parameters of the currently parsed template instance to their arguments
+	bindings map[string]templateArgument
+	// templateDepth is the depth of the currently parsed template instance
+	templateDepth int
+
+	collectDefinitions bool
+	definitions        []Definition
+	choices            map[string][]token.PositionedToken
+
+	// characterUniverse complements the character classes of the format
+	characterUniverse primitives.CharacterUniverse
 }
 
 func (p *tavorParser) expectRune(expect rune, got rune) (rune, error) {
@@ -131,6 +184,19 @@ func (p *tavorParser) parseGlobalScope(variableScope *token.VariableScope) error
 		case '\n':
 			// ignore new lines in the global scope
 		case scanner.Ident:
+			if p.scan.Peek() == '(' {
+				// parameterised token definitions are parsed on usage
+				if _, c, err = scanTemplateHeader(&p.scan); err != nil {
+					return err
+				}
+
+				skipTokenDefinitionBody(&p.scan)
+
+				c = p.scan.Scan()
+
+				continue
+			}
+
 			c, err = p.parseTokenDefinition(variableScope)
 			if err != nil {
 				return err
@@ -160,6 +226,14 @@ func (p *tavorParser) parseGlobalScope(variableScope *token.VariableScope) error
 }
 
 func (p *tavorParser) getToken(definitionName string, name string, variableScope *token.VariableScope) token.Token {
+	position := p.scan.Position
+
+	// usages of arguments are reported at the usage of the template
+	if arg, ok := p.bindings[name]; ok {
+		name = arg.name
+		position = arg.position
+	}
+
 	if tok := variableScope.Get(name); tok != nil {
 		if v, ok := tok.(token.VariableToken); ok {
 			tok = variables.NewVariableValue(v)
@@ -184,11 +258,11 @@ func (p *tavorParser) getToken(definitionName string, name string, variableScope
 
 		p.lookup[name] = tokenUsage{
 			token:    n,
-			position: p.scan.Position,
+			position: position,
 		}
 		p.earlyUse[name] = append(p.earlyUse[name], tokenUsage{
 			token:          b,
-			position:       p.scan.Position,
+			position:       position,
 			variableScope:  variableScope,
 			definitionName: definitionName,
 		})
@@ -196,7 +270,7 @@ func (p *tavorParser) getToken(definitionName string, name string, variableScope
 
 	p.used[name] = append(p.used[name], tokenUsage{
 		token:         nil,
-		position:      p.scan.Position,
+		position:      position,
 		variableScope: variableScope,
 	})
 
@@ -256,7 +330,7 @@ func (p *tavorParser) getToken(definitionName string, name string, variableScope
 
 			p.lookup[name] = tokenUsage{
 				token:    ntok,
-				position: p.scan.Position,
+				position: p.lookup[name].position,
 			}
 
 			if t, ok := tok.(*primitives.Pointer); ok && t.Resolve() == nil {
@@ -308,14 +382,32 @@ OUT:
 		case scanner.Ident:
 			name := p.scan.TokenText()
 
+			if _, ok := p.templates[name]; ok && p.scan.Peek() == '(' {
+				name, err = p.parseTemplateUsage(name)
+				if err != nil {
+					return zeroRune, nil, err
+				}
+			}
+
 			variableScope = variableScope.Push()
 			tok := p.getToken(definitionName, name, variableScope)
 
 			addToken(tok)
 		case scanner.Int:
-			v, _ := strconv.Atoi(p.scan.TokenText())
+			text := p.scan.TokenText()
+
+			if isHexLiteral(text) {
+				tok, err := p.parseByteLiteral(text)
+				if err != nil {
+					return zeroRune, nil, err
+				}
+
+				addToken(tok)
+			} else {
+				v, _ := strconv.Atoi(text)
 
-			addToken(primitives.NewConstantInt(v))
+				addToken(primitives.NewConstantInt(v))
+			}
 		case scanner.String:
 			s := p.scan.TokenText()
 
@@ -369,7 +461,20 @@ OUT:
 			log.Debug("Optional:")
 			log.IncreaseIndentation()
 
-			_, err = p.expectScanRune('(')
+			position := p.scan.Position
+			probability := -1.0
+
+			c = p.scan.Scan()
+			if c == scanner.Float {
+				probability, err = p.parseProbability()
+				if err != nil {
+					return zeroRune, nil, err
+				}
+
+				c = p.scan.Scan()
+			}
+
+			_, err = p.expectRune('(', c)
 			if err != nil {
 				return zeroRune, nil, err
 			}
@@ -387,13 +492,23 @@ OUT:
 				return zeroRune, nil, err
 			}
 
+			var tok token.Token
+
 			switch len(toks) {
 			case 0:
 				// ignore
 			case 1:
-				addToken(constraints.NewOptional(toks[0]))
+				tok = toks[0]
 			default:
-				addToken(constraints.NewOptional(lists.NewConcatenation(toks...)))
+				tok = lists.NewConcatenation(toks...)
+			}
+
+			if tok != nil {
+				if probability >= 0 {
+					addToken(p.addChoice(definitionName, constraints.NewOptionalWithProbability(tok, probability), position))
+				} else {
+					addToken(p.addChoice(definitionName, constraints.NewOptional(tok), position))
+				}
 			}
 
 			log.DecreaseIndentation()
@@ -401,17 +516,37 @@ OUT:
 			log.Debug("Repeat:")
 			log.IncreaseIndentation()
 
+			position := p.scan.Position
 			sym := c
 
 			c = p.scan.Scan()
 			log.Debugf("parseTerm repeat before ( %d:%v -> %v", p.scan.Line, scanner.TokenString(c), p.scan.TokenText())
 
 			var from, to token.Token
+			probability := -1.0
 
 			if sym == '*' {
 				from, to = primitives.NewConstantInt(0), primitives.NewConstantInt(tavor.MaxRepeat)
+
+				if c == scanner.Float {
+					probability, err = p.parseProbability()
+					if err != nil {
+						return zeroRune, nil, err
+					}
+
+					c = p.scan.Scan()
+				}
 			} else {
-				if c == scanner.Int {
+				if c == scanner.Float {
+					probability, err = p.parseProbability()
+					if err != nil {
+						return zeroRune, nil, err
+					}
+
+					c = p.scan.Scan()
+
+					from, to = primitives.NewConstantInt(1), primitives.NewConstantInt(tavor.MaxRepeat)
+				} else if c == scanner.Int {
 					iFrom, _ := strconv.Atoi(p.scan.TokenText())
 					from = primitives.NewConstantInt(iFrom)
 
@@ -457,6 +592,25 @@ OUT:
 						to = primitives.NewConstantInt(tavor.MaxRepeat)
 					}
 				}
+
+				if c == ':' {
+					c = p.scan.Scan()
+
+					if c != scanner.Float {
+						return zeroRune, nil, &token.ParserError{
+							Message:  fmt.Sprintf("expected probability but got %q", p.scan.TokenText()),
+							Type:     token.ParseErrorInvalidArgumentValue,
+							Position: p.scan.Pos(),
+						}
+					}
+
+					probability, err = p.parseProbability()
+					if err != nil {
+						return zeroRune, nil, err
+					}
+
+					c = p.scan.Scan()
+				}
 			}
 
 			_, err = p.expectRune('(', c)
@@ -479,6 +633,14 @@ OUT:
 				return zeroRune, nil, err
 			}
 
+			repeat := func(tok token.Token) token.Token {
+				if probability >= 0 {
+					return p.addChoice(definitionName, lists.NewRepeatWithProbability(tok, from, to, probability), position)
+				}
+
+				return p.addChoice(definitionName, lists.NewRepeatWithTokens(tok, from, to), position)
+			}
+
 			switch len(toks) {
 			case 0:
 				// ignore
@@ -504,9 +666,9 @@ OUT:
 					}
 				}
 
-				addToken(lists.NewRepeatWithTokens(toks[0], from, to))
+				addToken(repeat(toks[0]))
 			default:
-				addToken(lists.NewRepeatWithTokens(lists.NewConcatenation(toks...), from, to))
+				addToken(repeat(lists.NewConcatenation(toks...)))
 			}
 
 			log.DecreaseIndentation()
@@ -605,7 +767,7 @@ OUT:
 				return zeroRune, nil, err
 			}
 
-			addToken(primitives.NewCharacterClass(pattern.String()))
+			addToken(primitives.NewCharacterClassWithUniverse(pattern.String(), p.characterUniverse))
 
 			log.DecreaseIndentation()
 		case '<':
@@ -690,6 +852,66 @@ OUT:
 	return c, tokens, nil
 }
 
+func isHexLiteral(text string) bool {
+	return strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X")
+}
+
+// parseByteLiteral parses a hexadecimal byte literal like 0x89504E47 into a constant string holding the raw bytes or, if directly followed by a dash and a second single byte literal, a byte range like 0x00-0x1F
+func (p *tavorParser) parseByteLiteral(text string) (token.Token, error) {
+	from, err := p.decodeByteLiteral(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.scan.Peek() != '-' {
+		return primitives.NewConstantString(string(from)), nil
+	}
+
+	p.scan.Scan()
+
+	if c := p.scan.Scan(); c != scanner.Int || !isHexLiteral(p.scan.TokenText()) {
+		return nil, &token.ParserError{
+			Message:  fmt.Sprintf("byte range needs a byte literal as its end but got %q", p.scan.TokenText()),
+			Type:     token.ParseErrorInvalidByteLiteral,
+			Position: p.scan.Pos(),
+		}
+	}
+
+	to, err := p.decodeByteLiteral(p.scan.TokenText())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(from) != 1 || len(to) != 1 {
+		return nil, &token.ParserError{
+			Message:  "byte range can only be defined between single bytes",
+			Type:     token.ParseErrorInvalidByteLiteral,
+			Position: p.scan.Pos(),
+		}
+	} else if from[0] > to[0] {
+		return nil, &token.ParserError{
+			Message:  fmt.Sprintf("byte range %q-%q is in the wrong order", text, p.scan.TokenText()),
+			Type:     token.ParseErrorInvalidByteLiteral,
+			Position: p.scan.Pos(),
+		}
+	}
+
+	return primitives.NewBinaryInt(1, nil, uint64(from[0]), uint64(to[0])), nil
+}
+
+func (p *tavorParser) decodeByteLiteral(text string) ([]byte, error) {
+	b, err := hex.DecodeString(text[2:])
+	if err != nil || len(b) == 0 {
+		return nil, &token.ParserError{
+			Message:  fmt.Sprintf("byte literal %q needs an even number of hexadecimal digits", text),
+			Type:     token.ParseErrorInvalidByteLiteral,
+			Position: p.scan.Pos(),
+		}
+	}
+
+	return b, nil
+}
+
 func (p *tavorParser) parseExpression(definitionName string, variableScope *token.VariableScope) (rune, token.Token, error) {
 	log.Debug("Expression")
 	log.IncreaseIndentation()
@@ -713,12 +935,128 @@ func (p *tavorParser) parseExpression(definitionName string, variableScope *toke
 	return c, tok, nil
 }
 
+// parseExpressionTerm parses an expression term with its binary operators
 func (p *tavorParser) parseExpressionTerm(definitionName string, c rune, variableScope *token.VariableScope) (rune, token.Token, error) {
+	c, tok, err := p.parseExpressionOperand(definitionName, c, variableScope)
+	if err != nil || tok == nil {
+		return c, tok, err
+	}
+
+	return p.parseExpressionOperators(definitionName, c, tok, 0, variableScope)
+}
+
+// parseExpressionOperators parses the binary operators following the given left operand which have at least the given precedence
+func (p *tavorParser) parseExpressionOperators(definitionName string, c rune, tok token.Token, precedence int, variableScope *token.VariableScope) (rune, token.Token, error) {
+	for {
+		op, opPrecedence := p.expressionOperator(c)
+		if op == "" || opPrecedence < precedence {
+			return c, tok, nil
+		}
+
+		if len(op) == 2 {
+			p.scan.Scan()
+		}
+
+		c = p.scan.Scan()
+		log.Debugf("parseExpressionOperators operator %s %d:%v -> %v", op, p.scan.Line, scanner.TokenString(c), p.scan.TokenText())
+
+		var t token.Token
+		var err error
+
+		c, t, err = p.parseExpressionOperand(definitionName, c, variableScope)
+		if err != nil {
+			return zeroRune, nil, err
+		} else if t == nil {
+			return zeroRune, nil, &token.ParserError{
+				Message:  "expected another expression term after operator",
+				Type:     token.ParseErrorExpectedExpressionTerm,
+				Position: p.scan.Pos(),
+			}
+		}
+
+		// operators with a higher precedence take the right operand first
+		c, t, err = p.parseExpressionOperators(definitionName, c, t, opPrecedence+1, variableScope)
+		if err != nil {
+			return zeroRune, nil, err
+		}
+
+		switch op {
+		case "+":
+			tok = expressions.NewAddArithmetic(tok, t)
+		case "-":
+			tok = expressions.NewSubArithmetic(tok, t)
+		case "*":
+			tok = expressions.NewMulArithmetic(tok, t)
+		case "/":
+			tok = expressions.NewDivArithmetic(tok, t)
+		case "%":
+			tok = expressions.NewModArithmetic(tok, t)
+		case "&":
+			tok = expressions.NewAndArithmetic(tok, t)
+		case "|":
+			tok = expressions.NewOrArithmetic(tok, t)
+		case "^":
+			tok = expressions.NewXorArithmetic(tok, t)
+		case "<<":
+			tok = expressions.NewShiftLeftArithmetic(tok, t)
+		case ">>":
+			tok = expressions.NewShiftRightArithmetic(tok, t)
+		}
+	}
+}
+
+// expressionOperator returns the binary arithmetic operator starting with the given rune and its precedence. Higher precedences bind stronger. The operator is empty if the rune does not start an arithmetic operator e.g. the comparison operator "<" or the logical operator "&&".
+func (p *tavorParser) expressionOperator(c rune) (string, int) {
+	switch c {
+	case '*', '/', '%':
+		return string(c), 5
+	case '+', '-':
+		return string(c), 4
+	case '<', '>':
+		if p.scan.Peek() == c {
+			return string(c) + string(c), 3
+		}
+	case '&':
+		if p.scan.Peek() != '&' {
+			return string(c), 2
+		}
+	case '^':
+		return string(c), 1
+	case '|':
+		if p.scan.Peek() != '|' {
+			return string(c), 0
+		}
+	}
+
+	return "", -1
+}
+
+func (p *tavorParser) parseExpressionOperand(definitionName string, c rune, variableScope *token.VariableScope) (rune, token.Token, error) {
 	var tok token.Token
 	var err error
 
 	// single term
 	switch c {
+	case '(':
+		c = p.scan.Scan()
+
+		c, tok, err = p.parseExpressionTerm(definitionName, c, variableScope)
+		if err != nil {
+			return zeroRune, nil, err
+		} else if tok == nil {
+			return zeroRune, nil, &token.ParserError{
+				Message:  "expected a expression",
+				Type:     token.ParseErrorExpectedExpressionTerm,
+				Position: p.scan.Pos(),
+			}
+		}
+
+		_, err = p.expectRune(')', c)
+		if err != nil {
+			return zeroRune, nil, err
+		}
+
+		c = p.scan.Scan()
 	case scanner.Ident:
 		attribute := p.scan.TokenText()
 
@@ -740,7 +1078,17 @@ func (p *tavorParser) parseExpressionTerm(definitionName string, c rune, variabl
 				return zeroRune, nil, err
 			}
 		default:
-			if p.scan.Peek() == '.' {
+			if p.scan.Peek() == '(' && isComputeFunction(attribute) {
+				c, tok, err = p.parseExpressionFunction(definitionName, variableScope)
+				if err != nil {
+					return zeroRune, nil, err
+				}
+			} else if p.scan.Peek() == '(' && isTransformFunction(attribute) {
+				c, tok, err = p.parseExpressionTransform(definitionName, variableScope)
+				if err != nil {
+					return zeroRune, nil, err
+				}
+			} else if p.scan.Peek() == '.' {
 				c, tok, err = p.parseTokenAttribute(definitionName, c, variableScope)
 				if err != nil {
 					return zeroRune, nil, err
@@ -760,51 +1108,40 @@ func (p *tavorParser) parseExpressionTerm(definitionName string, c rune, variabl
 		tok = primitives.NewConstantInt(v)
 
 		c = p.scan.Scan()
-	}
+	case scanner.String:
+		s := p.scan.TokenText()
 
-	if tok == nil {
-		return zeroRune, nil, nil
-	}
+		if s[len(s)-1] != '"' {
+			return zeroRune, nil, &token.ParserError{
+				Message:  "string is not terminated",
+				Type:     token.ParseErrorNonTerminatedString,
+				Position: p.scan.Pos(),
+			}
+		}
 
-	log.Debugf("parseExpressionTerm %d:%v -> %v", p.scan.Line, scanner.TokenString(c), p.scan.TokenText())
+		s, _ = strconv.Unquote(s)
 
-	// operators
-	switch c {
-	case '+', '-', '*', '/':
-		sym := c
+		tok = primitives.NewConstantString(s)
 
 		c = p.scan.Scan()
-		log.Debugf("parseExpressionTerm operator %d:%v -> %v", p.scan.Line, scanner.TokenString(c), p.scan.TokenText())
+	}
 
-		var t token.Token
-		c, t, err = p.parseExpressionTerm(definitionName, c, variableScope)
-		if err != nil {
-			return zeroRune, nil, err
-		} else if t == nil {
-			return zeroRune, nil, &token.ParserError{
-				Message:  "expected another expression term after operator",
-				Type:     token.ParseErrorExpectedExpressionTerm,
-				Position: p.scan.Pos(),
-			}
-		}
+	if tok == nil {
+		return zeroRune, nil, nil
+	}
 
-		switch sym {
-		case '+':
-			tok = expressions.NewAddArithmetic(tok, t)
-		case '-':
-			tok = expressions.NewSubArithmetic(tok, t)
-		case '*':
-			tok = expressions.NewMulArithmetic(tok, t)
-		case '/':
-			tok = expressions.NewDivArithmetic(tok, t)
-		}
-	case scanner.Ident:
+	log.Debugf("parseExpressionOperand %d:%v -> %v", p.scan.Line, scanner.TokenString(c), p.scan.TokenText())
+
+	// operators which follow a term
+	if c == scanner.Ident {
 		switch op := p.scan.TokenText(); op {
 		case "path":
 			c, tok, err = p.parseExpressionOperatorPath(tok, definitionName, c, variableScope)
 			if err != nil {
 				return zeroRune, nil, err
 			}
+		case "contains", "startswith", "endswith", "matches":
+			// operators of conditions are handled by the condition parser
 		default:
 			return zeroRune, nil, &token.ParserError{
 				Message:  fmt.Sprintf("Operator %q is unknown", op),
@@ -817,62 +1154,174 @@ func (p *tavorParser) parseExpressionTerm(definitionName string, c rune, variabl
 	return c, tok, nil
 }
 
-func (p *tavorParser) parseExpressionGroup(definitionName string, variableScope *token.VariableScope, max int) ([]token.Token, error) {
-	_, err := p.expectScanRune('(')
-	if err != nil {
-		return nil, err
-	}
-
-	c := p.scan.Scan()
-
-	var tok token.Token
-	var toks []token.Token
-
-	for {
-		c, tok, err = p.parseExpressionTerm(definitionName, c, variableScope) // TODO
-		if err != nil {
-			return nil, err
-		} else if tok == nil {
-			return nil, &token.ParserError{
-				Message:  "expected a expression",
-				Type:     token.ParseErrorExpectedExpressionTerm,
-				Position: p.scan.Pos(),
-			}
+func isComputeFunction(name string) bool {
+	for _, f := range expressions.ComputeFunctions() {
+		if f == name {
+			return true
 		}
+	}
 
-		toks = append(toks, tok)
+	return false
+}
 
-		if (max != -1 && len(toks) >= max) || c != ',' {
-			break
+func isTransformFunction(name string) bool {
+	for _, f := range filters.TransformFunctions() {
+		if f == name {
+			return true
 		}
-
-		c = p.scan.Scan()
-	}
-
-	_, err = p.expectRune(')', c)
-	if err != nil {
-		return nil, err
 	}
 
-	return toks, err
+	return false
 }
 
-func (p *tavorParser) parseExpressionOperatorNotIn(tok *sequences.Sequence, definitionName string, c rune, variableScope *token.VariableScope) (rune, token.Token, error) {
-	log.Debug("Not in operator:")
-	log.IncreaseIndentation()
-	defer log.DecreaseIndentation()
+// parseExpressionTransform parses a transform call like base64(Payload) or base64(gzip(Body)) which embeds the encoded output of a token
+func (p *tavorParser) parseExpressionTransform(definitionName string, variableScope *token.VariableScope) (rune, token.Token, error) {
+	function := p.scan.TokenText()
+	functionPosition := p.scan.Position
 
-	_, err := p.expectText("not", c)
+	_, err := p.expectScanRune('(')
 	if err != nil {
 		return zeroRune, nil, err
 	}
 
-	_, err = p.expectScanText("in")
+	c, err := p.expectScanRune(scanner.Ident)
 	if err != nil {
 		return zeroRune, nil, err
 	}
 
-	expectToks, err := p.parseExpressionGroup(definitionName, variableScope, -1)
+	name := p.scan.TokenText()
+
+	var tok token.Token
+
+	if p.scan.Peek() == '(' && isTransformFunction(name) {
+		c, tok, err = p.parseExpressionTransform(definitionName, variableScope)
+		if err != nil {
+			return zeroRune, nil, err
+		}
+	} else {
+		tok = p.getToken(definitionName, name, variableScope.Push())
+
+		c = p.scan.Scan()
+	}
+
+	_, err = p.expectRune(')', c)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	c = p.scan.Scan()
+
+	t, err := filters.NewTransform(function, tok)
+	if err != nil {
+		return zeroRune, nil, &token.ParserError{
+			Message:  err.Error(),
+			Type:     token.ParseErrorInvalidArgumentValue,
+			Position: functionPosition,
+		}
+	}
+
+	return c, t, nil
+}
+
+// parseExpressionFunction parses a function call like crc32(Body) or len(Payload, u16be) which computes a value from the output of a token
+func (p *tavorParser) parseExpressionFunction(definitionName string, variableScope *token.VariableScope) (rune, token.Token, error) {
+	function := &tokenFunction{
+		name: p.scan.TokenText(),
+	}
+	functionPosition := p.scan.Position
+
+	_, err := p.expectScanRune('(')
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	_, err = p.expectScanRune(scanner.Ident)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	name := p.scan.TokenText()
+	tokenPosition := p.scan.Position
+
+	c := p.scan.Scan()
+
+	if c == ',' {
+		_, err = p.expectScanRune(scanner.Ident)
+		if err != nil {
+			return zeroRune, nil, err
+		}
+
+		function.encoding = p.scan.TokenText()
+
+		c = p.scan.Scan()
+	}
+
+	_, err = p.expectRune(')', c)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	c = p.scan.Scan()
+
+	return p.selectTokenReference(definitionName, name, tokenPosition, function.name, functionPosition, function, c, variableScope)
+}
+
+func (p *tavorParser) parseExpressionGroup(definitionName string, variableScope *token.VariableScope, max int) ([]token.Token, error) {
+	_, err := p.expectScanRune('(')
+	if err != nil {
+		return nil, err
+	}
+
+	c := p.scan.Scan()
+
+	var tok token.Token
+	var toks []token.Token
+
+	for {
+		c, tok, err = p.parseExpressionTerm(definitionName, c, variableScope) // TODO
+		if err != nil {
+			return nil, err
+		} else if tok == nil {
+			return nil, &token.ParserError{
+				Message:  "expected a expression",
+				Type:     token.ParseErrorExpectedExpressionTerm,
+				Position: p.scan.Pos(),
+			}
+		}
+
+		toks = append(toks, tok)
+
+		if (max != -1 && len(toks) >= max) || c != ',' {
+			break
+		}
+
+		c = p.scan.Scan()
+	}
+
+	_, err = p.expectRune(')', c)
+	if err != nil {
+		return nil, err
+	}
+
+	return toks, err
+}
+
+func (p *tavorParser) parseExpressionOperatorNotIn(tok *sequences.Sequence, definitionName string, c rune, variableScope *token.VariableScope) (rune, token.Token, error) {
+	log.Debug("Not in operator:")
+	log.IncreaseIndentation()
+	defer log.DecreaseIndentation()
+
+	_, err := p.expectText("not", c)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	_, err = p.expectScanText("in")
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	expectToks, err := p.parseExpressionGroup(definitionName, variableScope, -1)
 	if err != nil {
 		return zeroRune, nil, err
 	}
@@ -899,7 +1348,10 @@ func (p *tavorParser) parseExpressionOperatorInclude() (c rune, tok token.Token,
 		return zeroRune, nil, err
 	}
 
-	if tok, ok := includeCache[filepath]; ok {
+	// included formats are parsed with the same character universe
+	cacheKey := string(p.characterUniverse) + ":" + filepath
+
+	if tok, ok := includeCache[cacheKey]; ok {
 		tok = tok.Clone()
 
 		c = p.scan.Scan()
@@ -917,12 +1369,12 @@ func (p *tavorParser) parseExpressionOperatorInclude() (c rune, tok token.Token,
 		}
 	}()
 
-	tok, err = ParseTavor(f)
+	tok, err = newTavorParser(p.characterUniverse).parse(f)
 	if err != nil {
 		return zeroRune, nil, fmt.Errorf("%s: %#v", filepath, err)
 	}
 
-	includeCache[filepath] = tok
+	includeCache[cacheKey] = tok
 
 	c = p.scan.Scan()
 
@@ -1031,11 +1483,117 @@ func (p *tavorParser) parseTokenAttribute(definitionName string, c rune, variabl
 	attribute := p.scan.TokenText()
 	attributePosition := p.scan.Position
 
-	var op string
-	var opToken token.Token
+	c = p.scan.Scan()
+
+	if attribute == "Recursion" {
+		return p.parseTokenRecursion(definitionName, name, attributePosition, c, variableScope)
+	} else if attribute == "Encoding" {
+		return p.parseTokenEncoding(definitionName, name, attributePosition, c, variableScope)
+	}
+
+	return p.selectTokenReference(definitionName, name, tokenPosition, attribute, attributePosition, nil, c, variableScope)
+}
+
+// parseTokenRecursion parses the Recursion attribute with its optional maximum depth argument. The referenced token is embedded like a token usage but its definition is only cloned on its first use.
+func (p *tavorParser) parseTokenRecursion(definitionName string, name string, attributePosition scanner.Position, c rune, variableScope *token.VariableScope) (rune, token.Token, error) {
+	maxDepth := tavor.MaxRepeat
+
+	if c == '(' {
+		_, err := p.expectScanRune(scanner.Int)
+		if err != nil {
+			return zeroRune, nil, err
+		}
+
+		depthPosition := p.scan.Position
+
+		maxDepth, err = strconv.Atoi(p.scan.TokenText())
+		if err != nil || maxDepth < 1 {
+			return zeroRune, nil, &token.ParserError{
+				Message:  fmt.Sprintf("maximum depth of recursion must be a positive integer but got %q", p.scan.TokenText()),
+				Type:     token.ParseErrorInvalidArgumentValue,
+				Position: depthPosition,
+			}
+		}
+
+		_, err = p.expectScanRune(')')
+		if err != nil {
+			return zeroRune, nil, err
+		}
+
+		c = p.scan.Scan()
+	}
+
+	tok := p.getToken(definitionName, name, variableScope)
+
+	log.Debugf("Insert recursion of %s with maximum depth %d at %s", name, maxDepth, attributePosition)
+
+	return c, primitives.NewRecursion(tok, maxDepth), nil
+}
+
+// parseTokenEncoding parses the Encoding attribute with its encoding name and optional error mode arguments. The referenced token is embedded like a token usage.
+func (p *tavorParser) parseTokenEncoding(definitionName string, name string, attributePosition scanner.Position, c rune, variableScope *token.VariableScope) (rune, token.Token, error) {
+	_, err := p.expectRune('(', c)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	_, err = p.expectScanRune(scanner.Ident)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	encodingName := p.scan.TokenText()
+	encodingPosition := p.scan.Position
+
+	mode := encoding.ErrorModeReport
 
 	c = p.scan.Scan()
 
+	if c == ',' {
+		_, err = p.expectScanRune(scanner.Ident)
+		if err != nil {
+			return zeroRune, nil, err
+		}
+
+		mode = encoding.ErrorMode(p.scan.TokenText())
+		encodingPosition = p.scan.Position
+
+		c = p.scan.Scan()
+	}
+
+	_, err = p.expectRune(')', c)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	c = p.scan.Scan()
+
+	tok := p.getToken(definitionName, name, variableScope)
+
+	e, err := primitives.NewEncoded(tok, encodingName, mode)
+	if err != nil {
+		return zeroRune, nil, &token.ParserError{
+			Message:  err.Error(),
+			Type:     token.ParseErrorInvalidArgumentValue,
+			Position: encodingPosition,
+		}
+	}
+
+	log.Debugf("Insert %s encoding of %s at %s", encodingName, name, attributePosition)
+
+	return c, e, nil
+}
+
+// selectTokenReference resolves the token with the given name and selects either its attribute or, if function is not nil, a function applied to the token. If the token is not yet defined the selection is done after the whole format has been parsed.
+func (p *tavorParser) selectTokenReference(definitionName string, name string, tokenPosition scanner.Position, attribute string, attributePosition scanner.Position, function *tokenFunction, c rune, variableScope *token.VariableScope) (rune, token.Token, error) {
+	if arg, ok := p.bindings[name]; ok {
+		name = arg.name
+		tokenPosition = arg.position
+	}
+
+	var op string
+	var opToken token.Token
+
 	var tok token.Token
 
 	use, ok := p.lookup[name]
@@ -1072,6 +1630,7 @@ func (p *tavorParser) parseTokenAttribute(definitionName string, c rune, variabl
 				attributePosition: attributePosition,
 				operator:          op,
 				operatorToken:     opToken,
+				function:          function,
 				pointer:           pointer,
 				variableScope:     nVariableScope,
 			})
@@ -1087,7 +1646,14 @@ func (p *tavorParser) parseTokenAttribute(definitionName string, c rune, variabl
 		definitionName: definitionName,
 	})
 
-	c, rtok, err := p.selectTokenAttribute(definitionName, tok, name, attribute, attributePosition, op, opToken, c, variableScope)
+	var rtok token.Token
+	var err error
+
+	if function != nil {
+		rtok, err = p.selectTokenFunction(tok, function, attributePosition)
+	} else {
+		c, rtok, err = p.selectTokenAttribute(definitionName, tok, name, attribute, attributePosition, op, opToken, c, variableScope)
+	}
 
 	if err == nil {
 		log.Debugf("Insert token attribute %p(%#v)", rtok, rtok)
@@ -1152,6 +1718,11 @@ func (p *tavorParser) selectTokenAttribute(definitionName string, tok token.Toke
 		case "Value":
 			return c, i.Clone(), nil
 		}
+	case *primitives.BinaryInt:
+		switch attribute {
+		case "Value":
+			return c, i.Clone(), nil
+		}
 	case token.VariableToken:
 		switch attribute {
 		case "Count":
@@ -1197,12 +1768,36 @@ func (p *tavorParser) selectTokenAttribute(definitionName string, tok token.Toke
 	}
 }
 
+func (p *tavorParser) selectTokenFunction(tok token.Token, function *tokenFunction, functionPosition scanner.Position) (token.Token, error) {
+	if t, ok := tok.(*primitives.Scope); ok {
+		tok = t.Resolve()
+	}
+
+	rtok, err := expressions.NewComputedValue(function.name, function.encoding, tok)
+	if err != nil {
+		return nil, &token.ParserError{
+			Message:  err.Error(),
+			Type:     token.ParseErrorInvalidArgumentValue,
+			Position: functionPosition,
+		}
+	}
+
+	return rtok, nil
+}
+
 func (p *tavorParser) parseScope(definitionName string, c rune, variableScope *token.VariableScope) (rune, []token.Token, error) {
 	var err error
 	var tokens []token.Token
 
 	var toks []token.Token
 
+	position := p.scan.Position
+
+	c, weight, weighted, err := p.parseWeight(c)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
 	c, toks, err = p.parseTerm(definitionName, c, variableScope)
 	if err != nil {
 		return zeroRune, nil, err
@@ -1220,6 +1815,8 @@ SCOPE:
 			log.IncreaseIndentation()
 
 			var orTerms []token.Token
+			var orWeights []float64
+			var optionalWeight float64
 			optional := false
 
 			toks = tokens
@@ -1229,10 +1826,13 @@ SCOPE:
 				switch len(toks) {
 				case 0:
 					optional = true
+					optionalWeight += weight
 				case 1:
 					orTerms = append(orTerms, toks[0])
+					orWeights = append(orWeights, weight)
 				default:
 					orTerms = append(orTerms, lists.NewConcatenation(toks...))
+					orWeights = append(orWeights, weight)
 				}
 
 				if c == '|' {
@@ -1244,20 +1844,42 @@ SCOPE:
 					break OR
 				}
 
+				var ok bool
+				c, weight, ok, err = p.parseWeight(c)
+				if err != nil {
+					return zeroRune, nil, err
+				}
+				weighted = weighted || ok
+
 				c, toks, err = p.parseTerm(definitionName, c, variableScope)
 				if err != nil {
 					return zeroRune, nil, err
 				}
 			}
 
-			or := lists.NewOne(orTerms...)
+			var or *lists.One
+			if weighted {
+				or = lists.NewOneWithWeights(orWeights, orTerms...)
+			} else {
+				or = lists.NewOne(orTerms...)
+			}
+			p.addChoice(definitionName, or, position)
 
-			if optional {
-				tokens = []token.Token{constraints.NewOptional(or)}
+			if optional && weighted {
+				var sum float64
+				for _, w := range orWeights {
+					sum += w
+				}
+
+				tokens = []token.Token{p.addChoice(definitionName, constraints.NewOptionalWithProbability(or, sum/(sum+optionalWeight)), position)}
+			} else if optional {
+				tokens = []token.Token{p.addChoice(definitionName, constraints.NewOptional(or), position)}
 			} else {
 				tokens = []token.Token{or}
 			}
 
+			weighted = false
+
 			log.DecreaseIndentation()
 		case '{': // TODO make conditions work with ORs...
 			c = p.scan.Scan()
@@ -1341,67 +1963,322 @@ SCOPE:
 					Body: tok,
 				}
 
-				log.Debugf("Add if part %#v", ifPart)
+				log.Debugf("Add if part %#v", ifPart)
+
+				ifPairs = append(ifPairs, ifPart)
+			} else {
+				tokens = append(tokens, conditions.NewIf(ifPairs...))
+
+				ifPairs = nil
+
+				c, toks, err = p.parseTerm(definitionName, c, variableScope) // TODO this should be a a global scope or so ... we can do nesting
+				if err != nil {
+					return zeroRune, nil, err
+				}
+
+				tokens = append(tokens, toks...)
+			}
+
+			log.DecreaseIndentation()
+
+			continue SCOPE
+		}
+
+		break SCOPE
+	}
+
+	if len(ifPairs) > 0 {
+		panic("TODO if without endif")
+	}
+
+	if weighted {
+		return zeroRune, nil, &token.ParserError{
+			Message:  "weights are only allowed for alternation terms",
+			Type:     token.ParseErrorInvalidArgumentValue,
+			Position: position,
+		}
+	}
+
+	return c, tokens, nil
+}
+
+// addChoice sets the position of a token which makes a choice while fuzzing and records it for its definition if the definitions are collected
+func (p *tavorParser) addChoice(definitionName string, tok token.PositionedToken, position scanner.Position) token.Token {
+	if p.collectDefinitions {
+		tok.SetPosition(position)
+
+		p.choices[definitionName] = append(p.choices[definitionName], tok)
+	}
+
+	return tok
+}
+
+// parseWeight parses the weight of an alternation term which is a number directly followed by a colon. The weight is 1 if there is no weight.
+func (p *tavorParser) parseWeight(c rune) (rune, float64, bool, error) {
+	if (c != scanner.Int && c != scanner.Float) || p.scan.Peek() != ':' || isHexLiteral(p.scan.TokenText()) {
+		return c, 1, false, nil
+	}
+
+	weight, err := strconv.ParseFloat(p.scan.TokenText(), 64)
+	if err != nil || weight < 0 {
+		return zeroRune, 0, false, &token.ParserError{
+			Message:  fmt.Sprintf("invalid weight %q", p.scan.TokenText()),
+			Type:     token.ParseErrorInvalidArgumentValue,
+			Position: p.scan.Pos(),
+		}
+	}
+
+	// skip the colon
+	p.scan.Scan()
+
+	c = p.scan.Scan()
+	log.Debugf("parseWeight after weight %v %d:%v -> %v", weight, p.scan.Line, scanner.TokenString(c), p.scan.TokenText())
+
+	return c, weight, true, nil
+}
+
+// parseProbability parses the probability argument of a group modifier which has to be between 0 and 1
+func (p *tavorParser) parseProbability() (float64, error) {
+	probability, err := strconv.ParseFloat(p.scan.TokenText(), 64)
+	if err != nil || probability < 0 || probability > 1 {
+		return 0, &token.ParserError{
+			Message:  fmt.Sprintf("invalid probability %q, must be between 0 and 1", p.scan.TokenText()),
+			Type:     token.ParseErrorInvalidArgumentValue,
+			Position: p.scan.Pos(),
+		}
+	}
+
+	return probability, nil
+}
+
+// parseConditionExpression parses the condition of an if statement.
+// Conditions are combined by the operators "||", "&&" and "not" which are listed in ascending order of their precedence and can be grouped by parenthesis.
+func (p *tavorParser) parseConditionExpression(definitionName string, variableScope *token.VariableScope) (rune, conditions.BooleanExpression, error) {
+	c := p.scan.Scan()
+
+	c, tok, err := p.parseConditionOr(definitionName, c, variableScope)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	if ex, ok := tok.(conditions.BooleanExpression); ok {
+		return c, ex, nil
+	}
+
+	return zeroRune, nil, &token.ParserError{
+		Message:  fmt.Sprintf("unknown boolean operator %q", c),
+		Type:     token.ParseErrorUnknownBooleanOperator,
+		Position: p.scan.Pos(),
+	}
+}
+
+func (p *tavorParser) parseConditionOr(definitionName string, c rune, variableScope *token.VariableScope) (rune, token.Token, error) {
+	c, tok, err := p.parseConditionAnd(definitionName, c, variableScope)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	for c == '|' {
+		if _, err := p.expectScanRune('|'); err != nil {
+			return zeroRune, nil, err
+		}
+
+		var b token.Token
+		c, b, err = p.parseConditionAnd(definitionName, p.scan.Scan(), variableScope)
+		if err != nil {
+			return zeroRune, nil, err
+		}
+
+		ea, eb, err := p.expectConditions(tok, b)
+		if err != nil {
+			return zeroRune, nil, err
+		}
+
+		tok = conditions.NewBooleanOr(ea, eb)
+	}
+
+	return c, tok, nil
+}
+
+func (p *tavorParser) parseConditionAnd(definitionName string, c rune, variableScope *token.VariableScope) (rune, token.Token, error) {
+	c, tok, err := p.parseConditionNot(definitionName, c, variableScope)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	for c == '&' {
+		if _, err := p.expectScanRune('&'); err != nil {
+			return zeroRune, nil, err
+		}
+
+		var b token.Token
+		c, b, err = p.parseConditionNot(definitionName, p.scan.Scan(), variableScope)
+		if err != nil {
+			return zeroRune, nil, err
+		}
+
+		ea, eb, err := p.expectConditions(tok, b)
+		if err != nil {
+			return zeroRune, nil, err
+		}
+
+		tok = conditions.NewBooleanAnd(ea, eb)
+	}
+
+	return c, tok, nil
+}
+
+func (p *tavorParser) parseConditionNot(definitionName string, c rune, variableScope *token.VariableScope) (rune, token.Token, error) {
+	if c != scanner.Ident || p.scan.TokenText() != "not" {
+		return p.parseConditionComparison(definitionName, c, variableScope)
+	}
+
+	c, tok, err := p.parseConditionNot(definitionName, p.scan.Scan(), variableScope)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	ex, _, err := p.expectConditions(tok, nil)
+	if err != nil {
+		return zeroRune, nil, err
+	}
+
+	return c, conditions.NewBooleanNot(ex), nil
+}
+
+// parseConditionComparison parses a comparison of two expressions, a boolean expression like "defined" or a parenthesised condition.
+// An expression without comparison operator is returned as it is since it can be the left side of a comparison after parenthesis.
+func (p *tavorParser) parseConditionComparison(definitionName string, c rune, variableScope *token.VariableScope) (rune, token.Token, error) {
+	var a token.Token
+	var err error
+
+	if c == '(' {
+		c, a, err = p.parseConditionOr(definitionName, p.scan.Scan(), variableScope)
+		if err != nil {
+			return zeroRune, nil, err
+		}
+
+		if _, err := p.expectRune(')', c); err != nil {
+			return zeroRune, nil, err
+		}
 
-				ifPairs = append(ifPairs, ifPart)
-			} else {
-				tokens = append(tokens, conditions.NewIf(ifPairs...))
+		c = p.scan.Scan()
 
-				ifPairs = nil
+		// a parenthesised value can be the left operand of arithmetic operators
+		if _, ok := a.(conditions.BooleanExpression); !ok {
+			c, a, err = p.parseExpressionOperators(definitionName, c, a, 0, variableScope)
+			if err != nil {
+				return zeroRune, nil, err
+			}
+		}
+	} else {
+		c, a, err = p.parseExpressionTerm(definitionName, c, variableScope)
+		if err != nil {
+			return zeroRune, nil, err
+		} else if a == nil {
+			return zeroRune, nil, &token.ParserError{
+				Message:  "empty expressions are not allowed",
+				Type:     token.ParseErrorEmptyExpressionIsInvalid,
+				Position: p.scan.Pos(),
+			}
+		}
+	}
 
-				c, toks, err = p.parseTerm(definitionName, c, variableScope) // TODO this should be a a global scope or so ... we can do nesting
-				if err != nil {
-					return zeroRune, nil, err
-				}
+	if _, ok := a.(conditions.BooleanExpression); ok {
+		return c, a, nil
+	}
 
-				tokens = append(tokens, toks...)
-			}
+	var operator string
 
-			log.DecreaseIndentation()
+	switch c {
+	case '=', '!':
+		operator = string(c) + "="
 
-			continue SCOPE
+		if _, err := p.expectScanRune('='); err != nil {
+			return zeroRune, nil, err
 		}
+	case '<', '>':
+		operator = string(c)
 
-		break SCOPE
-	}
+		if p.scan.Peek() == '=' {
+			p.scan.Scan()
 
-	if len(ifPairs) > 0 {
-		panic("TODO if without endif")
-	}
+			operator += "="
+		}
+	case scanner.Ident:
+		operator = p.scan.TokenText()
 
-	return c, tokens, nil
-}
+		if !isConditionOperator(operator) {
+			return zeroRune, nil, &token.ParserError{
+				Message:  fmt.Sprintf("unknown boolean operator %q", operator),
+				Type:     token.ParseErrorUnknownBooleanOperator,
+				Position: p.scan.Pos(),
+			}
+		}
+	default:
+		return c, a, nil
+	}
 
-func (p *tavorParser) parseConditionExpression(definitionName string, variableScope *token.VariableScope) (rune, conditions.BooleanExpression, error) {
-	c, a, err := p.parseExpression(definitionName, variableScope)
+	c, b, err := p.parseExpression(definitionName, variableScope)
 	if err != nil {
 		return zeroRune, nil, err
 	}
 
-	if ex, ok := a.(conditions.BooleanExpression); ok {
-		return c, ex, nil
+	switch operator {
+	case "==":
+		return c, conditions.NewBooleanEqual(a, b), nil
+	case "!=":
+		return c, conditions.NewBooleanNotEqual(a, b), nil
+	case "<":
+		return c, conditions.NewBooleanLess(a, b), nil
+	case "<=":
+		return c, conditions.NewBooleanLessEqual(a, b), nil
+	case ">":
+		return c, conditions.NewBooleanGreater(a, b), nil
+	case ">=":
+		return c, conditions.NewBooleanGreaterEqual(a, b), nil
+	case "contains":
+		return c, conditions.NewBooleanContains(a, b), nil
+	case "startswith":
+		return c, conditions.NewBooleanStartsWith(a, b), nil
+	case "endswith":
+		return c, conditions.NewBooleanEndsWith(a, b), nil
+	default:
+		return c, conditions.NewBooleanMatches(a, b), nil
 	}
+}
 
-	switch c {
-	case '=':
-		_, err = p.expectScanRune('=')
-		if err != nil {
-			return zeroRune, nil, err
+// expectConditions returns the given tokens as boolean expressions or an error if one of them is no boolean expression. The second token can be nil.
+func (p *tavorParser) expectConditions(a, b token.Token) (conditions.BooleanExpression, conditions.BooleanExpression, error) {
+	var exs [2]conditions.BooleanExpression
+
+	for i, tok := range []token.Token{a, b} {
+		if tok == nil {
+			continue
 		}
-	default:
-		return zeroRune, nil, &token.ParserError{
-			Message:  fmt.Sprintf("unknown boolean operator %q", c),
-			Type:     token.ParseErrorUnknownBooleanOperator,
-			Position: p.scan.Pos(),
+
+		ex, ok := tok.(conditions.BooleanExpression)
+		if !ok {
+			return nil, nil, &token.ParserError{
+				Message:  "expected a condition but got a value",
+				Type:     token.ParseErrorUnknownBooleanOperator,
+				Position: p.scan.Pos(),
+			}
 		}
+
+		exs[i] = ex
 	}
 
-	c, b, err := p.parseExpression(definitionName, variableScope)
-	if err != nil {
-		return zeroRune, nil, err
+	return exs[0], exs[1], nil
+}
+
+func isConditionOperator(name string) bool {
+	switch name {
+	case "contains", "startswith", "endswith", "matches":
+		return true
 	}
 
-	return c, conditions.NewBooleanEqual(a, b), nil
+	return false
 }
 
 func (p *tavorParser) parseTokenDefinition(variableScope *token.VariableScope) (c rune, err error) {
@@ -1433,15 +2310,20 @@ func (p *tavorParser) parseTokenDefinition(variableScope *token.VariableScope) (
 		return zeroRune, err
 	}
 
-	// each definition start its own scope
-	variableScope = variableScope.Push()
-
 	// start reading definition
 	c = p.scan.Scan()
 	log.Debugf("parseTokenDefinition after = %d:%v -> %v:", p.scan.Line, scanner.TokenString(c), p.scan.TokenText())
+
+	return p.parseTokenDefinitionBody(name, tokenPosition, c, variableScope)
+}
+
+func (p *tavorParser) parseTokenDefinitionBody(name string, tokenPosition scanner.Position, c rune, variableScope *token.VariableScope) (rune, error) {
 	log.IncreaseIndentation()
 	defer log.DecreaseIndentation()
 
+	// each definition start its own scope
+	variableScope = variableScope.Push()
+
 	c, tokens, err := p.parseScope(name, c, variableScope)
 	if err != nil {
 		return zeroRune, err
@@ -1488,6 +2370,243 @@ func (p *tavorParser) parseTokenDefinition(variableScope *token.VariableScope) (
 	return c, nil
 }
 
+// scanTemplateHeader scans the name and parameters of a parameterised token definition up to and including the equal sign
+func scanTemplateHeader(scan *scanner.Scanner) (*tokenTemplate, rune, error) {
+	t := &tokenTemplate{
+		name:     scan.TokenText(),
+		position: scan.Position,
+	}
+
+	scan.Scan() // (
+
+	for {
+		c := scan.Scan()
+		// parameters can be continued on the next line after a comma
+		for c == '\n' && len(t.parameters) > 0 {
+			c = scan.Scan()
+		}
+		if c != scanner.Ident {
+			return nil, zeroRune, &token.ParserError{
+				Message:  fmt.Sprintf("expected parameter name but got %s", scanner.TokenString(c)),
+				Type:     token.ParseErrorInvalidTokenName,
+				Position: scan.Position,
+			}
+		}
+
+		t.parameters = append(t.parameters, scan.TokenText())
+
+		c = scan.Scan()
+		if c == ')' {
+			break
+		} else if c != ',' {
+			return nil, zeroRune, &token.ParserError{
+				Message:  fmt.Sprintf("expected %s but got %s", scanner.TokenString(')'), scanner.TokenString(c)),
+				Type:     token.ParseErrorExpectRune,
+				Position: scan.Position,
+			}
+		}
+	}
+
+	if c := scan.Scan(); c != '=' {
+		return nil, zeroRune, &token.ParserError{
+			Message:  fmt.Sprintf("expected %s but got %s", scanner.TokenString('='), scanner.TokenString(c)),
+			Type:     token.ParseErrorExpectRune,
+			Position: scan.Position,
+		}
+	}
+
+	t.bodyPosition = scan.Position
+	t.bodyPosition.Offset++
+	t.bodyPosition.Column++
+
+	return t, '=', nil
+}
+
+// skipTokenDefinitionBody scans over the body of a token definition including its terminating new line and returns the offset of the end of the body
+func skipTokenDefinitionBody(scan *scanner.Scanner) int {
+	var last rune
+
+	for {
+		c := scan.Scan()
+
+		switch {
+		case c == scanner.EOF:
+			return scan.Position.Offset
+		case c == '\n' && last != ',':
+			return scan.Position.Offset + 1
+		}
+
+		last = c
+	}
+}
+
+// collectTemplates searches the given format source for parameterised token definitions
+func collectTemplates(src []byte) (map[string]*tokenTemplate, error) {
+	templates := make(map[string]*tokenTemplate)
+
+	var scan scanner.Scanner
+	scan.Init(bytes.NewReader(src))
+	scan.Error = func(s *scanner.Scanner, msg string) {}
+	scan.Whitespace = 1<<'\t' | 1<<' ' | 1<<'\r'
+
+	for c := scan.Scan(); c != scanner.EOF; c = scan.Scan() {
+		switch {
+		case c == '\n':
+			// ignore new lines in the global scope
+		case c == scanner.Ident && scan.Peek() == '(':
+			t, _, err := scanTemplateHeader(&scan)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := templates[t.name]; ok {
+				return nil, &token.ParserError{
+					Message:  "token already defined",
+					Type:     token.ParseErrorTokenAlreadyDefined,
+					Position: t.bodyPosition,
+				}
+			}
+
+			t.body = string(src[t.bodyPosition.Offset:skipTokenDefinitionBody(&scan)])
+
+			templates[t.name] = t
+		default:
+			skipTokenDefinitionBody(&scan)
+		}
+	}
+
+	return templates, nil
+}
+
+// parseTemplateUsage parses the arguments of a usage of a parameterised token definition like List(Number) and returns the token name of the instance
+func (p *tavorParser) parseTemplateUsage(name string) (string, error) {
+	t := p.templates[name]
+	t.used = true
+	usagePosition := p.scan.Position
+
+	if _, err := p.expectScanRune('('); err != nil {
+		return "", err
+	}
+
+	var arguments []templateArgument
+
+	for {
+		c := p.scan.Scan()
+		// arguments can be continued on the next line after a comma
+		for c == '\n' && len(arguments) > 0 {
+			c = p.scan.Scan()
+		}
+		if _, err := p.expectRune(scanner.Ident, c); err != nil {
+			return "", err
+		}
+
+		arg := templateArgument{
+			name:     p.scan.TokenText(),
+			position: p.scan.Position,
+		}
+		if a, ok := p.bindings[arg.name]; ok {
+			arg = a
+		} else if _, ok := p.templates[arg.name]; ok && p.scan.Peek() == '(' {
+			var err error
+
+			arg.name, err = p.parseTemplateUsage(arg.name)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		arguments = append(arguments, arg)
+
+		c = p.scan.Scan()
+		if c == ')' {
+			break
+		} else if c != ',' {
+			_, err := p.expectRune(')', c)
+
+			return "", err
+		}
+	}
+
+	if len(arguments) != len(t.parameters) {
+		return "", &token.ParserError{
+			Message:  fmt.Sprintf("token %q needs %d arguments but got %d", name, len(t.parameters), len(arguments)),
+			Type:     token.ParseErrorInvalidArgumentValue,
+			Position: usagePosition,
+		}
+	}
+
+	names := make([]string, len(arguments))
+	for i, arg := range arguments {
+		names[i] = arg.name
+	}
+
+	instance := name + "(" + strings.Join(names, ",") + ")"
+
+	if _, ok := p.instances[instance]; !ok {
+		if p.templateDepth >= maxTemplateDepth {
+			return "", &token.ParserError{
+				Message:  fmt.Sprintf("token %q is instantiated recursively with ever growing arguments, stopped at a depth of %d", name, maxTemplateDepth),
+				Type:     token.ParseErrEndlessLoopDetected,
+				Position: usagePosition,
+			}
+		}
+
+		bindings := make(map[string]templateArgument, len(arguments))
+		for i, arg := range arguments {
+			bindings[t.parameters[i]] = arg
+		}
+
+		p.instances[instance] = struct{}{}
+		p.templateInstances = append(p.templateInstances, &templateInstance{
+			name:      instance,
+			template:  t,
+			arguments: bindings,
+			depth:     p.templateDepth + 1,
+		})
+	}
+
+	return instance, nil
+}
+
+// parseTemplateInstances parses the bodies of all used parameterised token definitions with their arguments as ordinary token definitions
+func (p *tavorParser) parseTemplateInstances(variableScope *token.VariableScope) error {
+	scan := p.scan
+	defer func() {
+		p.scan = scan
+		p.bindings = nil
+		p.templateDepth = 0
+	}()
+
+	// instances can use further instances
+	for len(p.templateInstances) > 0 {
+		i := p.templateInstances[0]
+		p.templateInstances = p.templateInstances[1:]
+
+		log.Debugf("parse instance %s", i.name)
+
+		// pad the body so that positions are the same as in the original source
+		pos := i.template.bodyPosition
+
+		p.scan.Init(strings.NewReader(strings.Repeat("\n", pos.Line-1) + strings.Repeat(" ", pos.Column-1) + i.template.body))
+		p.scan.Error = scan.Error
+		p.scan.Whitespace = scan.Whitespace
+
+		p.bindings = i.arguments
+		p.templateDepth = i.depth
+
+		c := p.scan.Scan()
+		for c == '\n' {
+			c = p.scan.Scan()
+		}
+
+		if _, err := p.parseTokenDefinitionBody(i.name, pos, c, variableScope); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (p *tavorParser) setEarlyUsage(name string, tok token.Token) error {
 	// self loop?
 	if uses, ok := p.earlyUse[name]; ok {
@@ -1729,21 +2848,79 @@ func (p *tavorParser) getVariable(fromDefinition string, name string, pos scanne
 	return v, nil
 }
 
-// ParseTavor reads and parses a Tavor formatted input and returns its token graph representation beginning with the START token.
-// The error return argument is not nil if an error is encountered during reading or parsing the file e.g. a syntax or semantic error.
-func ParseTavor(src io.Reader) (token.Token, error) {
-	p := &tavorParser{
+// Definition holds a global token definition of a Tavor formatted input
+type Definition struct {
+	Name     string
+	Position scanner.Position
+	Token    token.Token
+
+	// Choices holds the tokens of the definition which make a choice while fuzzing, i.e. alternations, optional and repeat groups, ordered by their position
+	Choices []token.PositionedToken
+}
+
+func newTavorParser(characterUniverse primitives.CharacterUniverse) *tavorParser {
+	return &tavorParser{
 		earlyUse:    make(map[string][]tokenUsage),
 		lookup:      make(map[string]tokenUsage),
 		lookupUsage: make(map[token.Token]struct{}),
 		used:        make(map[string][]tokenUsage),
 
 		called: make(map[string][]call),
+
+		instances: make(map[string]struct{}),
+
+		choices: make(map[string][]token.PositionedToken),
+
+		characterUniverse: characterUniverse,
 	}
+}
+
+// ParseTavor reads and parses a Tavor formatted input and returns its token graph representation beginning with the START token.
+// Character classes are complemented using the default character universe.
+// The error return argument is not nil if an error is encountered during reading or parsing the file e.g. a syntax or semantic error.
+func ParseTavor(src io.Reader) (token.Token, error) {
+	return ParseTavorWithUniverse(src, primitives.DefaultCharacterUniverse)
+}
+
+// ParseTavorWithUniverse reads and parses a Tavor formatted input like ParseTavor but complements character classes using the given character universe.
+func ParseTavorWithUniverse(src io.Reader, characterUniverse primitives.CharacterUniverse) (token.Token, error) {
+	return newTavorParser(characterUniverse).parse(src)
+}
+
+// ParseTavorDefinitions reads and parses a Tavor formatted input like ParseTavor and returns additionally the token graphs of all global token definitions ordered by their position.
+// Every definition is unrolled on its own like the START token which makes it possible to inspect parts of the format e.g. their permutation counts.
+// The choices of the START token graph and the definitions know their position in the format source which makes it possible to trace generations back to the format.
+func ParseTavorDefinitions(src io.Reader) (token.Token, []Definition, error) {
+	return ParseTavorDefinitionsWithUniverse(src, primitives.DefaultCharacterUniverse)
+}
+
+// ParseTavorDefinitionsWithUniverse reads and parses a Tavor formatted input like ParseTavorDefinitions but complements character classes using the given character universe.
+func ParseTavorDefinitionsWithUniverse(src io.Reader, characterUniverse primitives.CharacterUniverse) (token.Token, []Definition, error) {
+	p := newTavorParser(characterUniverse)
+	p.collectDefinitions = true
+
+	start, err := p.parse(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return start, p.definitions, nil
+}
 
+func (p *tavorParser) parse(src io.Reader) (token.Token, error) {
 	log.Debug("start parsing tavor file")
 
-	p.scan.Init(src)
+	data, err := ioutil.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p.templates, err = collectTemplates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p.scan.Init(bytes.NewReader(data))
 
 	p.scan.Error = func(s *scanner.Scanner, msg string) {
 		p.err = msg
@@ -1756,6 +2933,10 @@ func ParseTavor(src io.Reader) (token.Token, error) {
 		return nil, err
 	}
 
+	if err := p.parseTemplateInstances(variableScope); err != nil {
+		return nil, err
+	}
+
 	if _, ok := p.lookup["START"]; !ok {
 		return nil, &token.ParserError{
 			Message:  "no START token defined",
@@ -1851,8 +3032,15 @@ func ParseTavor(src io.Reader) (token.Token, error) {
 			}
 		}
 
-		// TODO zeroRune must be replaced with "c" we cannot scan in this selectTokenAttribute call
-		_, rtok, err := p.selectTokenAttribute(forwardUse.definitionName, tok, forwardUse.tokenName, forwardUse.attribute, forwardUse.attributePosition, forwardUse.operator, forwardUse.operatorToken, zeroRune, variableScope)
+		var rtok token.Token
+		var err error
+
+		if forwardUse.function != nil {
+			rtok, err = p.selectTokenFunction(tok, forwardUse.function, forwardUse.attributePosition)
+		} else {
+			// TODO zeroRune must be replaced with "c" we cannot scan in this selectTokenAttribute call
+			_, rtok, err = p.selectTokenAttribute(forwardUse.definitionName, tok, forwardUse.tokenName, forwardUse.attribute, forwardUse.attributePosition, forwardUse.operator, forwardUse.operatorToken, zeroRune, variableScope)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -1879,6 +3067,24 @@ func ParseTavor(src io.Reader) (token.Token, error) {
 		}
 	}
 
+	var unusedTemplates []*tokenTemplate
+	for _, t := range p.templates {
+		if !t.used {
+			unusedTemplates = append(unusedTemplates, t)
+		}
+	}
+	if len(unusedTemplates) > 0 {
+		sort.Slice(unusedTemplates, func(i, j int) bool {
+			return unusedTemplates[i].position.Offset < unusedTemplates[j].position.Offset
+		})
+
+		return nil, &token.ParserError{
+			Message:  fmt.Sprintf("token %q declared but not used", unusedTemplates[0].name),
+			Type:     token.ParseErrorUnusedToken,
+			Position: unusedTemplates[0].position,
+		}
+	}
+
 	for _, variable := range p.variableUsages {
 		tok := variable.(token.ForwardToken).InternalGet()
 
@@ -1903,6 +3109,12 @@ func ParseTavor(src io.Reader) (token.Token, error) {
 		}
 	}
 
+	if p.collectDefinitions {
+		if err := p.unrollDefinitions(); err != nil {
+			return nil, err
+		}
+	}
+
 	start := p.lookup["START"].token
 
 	// TODO this could be done much better especially we could add ALL resets here not just sequences
@@ -1923,7 +3135,7 @@ func ParseTavor(src io.Reader) (token.Token, error) {
 		start = lists.NewConcatenation(automaticResets...)
 	}
 
-	start, err := token.UnrollPointers(start)
+	start, err = token.UnrollPointers(start)
 	if err != nil {
 		return nil, err
 	}
@@ -1939,3 +3151,41 @@ func ParseTavor(src io.Reader) (token.Token, error) {
 
 	return start, nil
 }
+
+// unrollDefinitions unrolls a copy of every global token definition. Sequences are skipped since they are no usable tokens on their own.
+func (p *tavorParser) unrollDefinitions() error {
+	for name, use := range p.lookup {
+		tok := use.token
+		if t, ok := tok.(token.Resolve); ok {
+			tok = t.Resolve()
+		}
+
+		if _, ok := tok.(*sequences.Sequence); ok {
+			continue
+		}
+
+		tok, err := token.UnrollPointers(use.token.Clone())
+		if err != nil {
+			return err
+		}
+
+		choices := p.choices[name]
+		sort.SliceStable(choices, func(i, j int) bool {
+			return choices[i].Position().Offset < choices[j].Position().Offset
+		})
+
+		p.definitions = append(p.definitions, Definition{
+			Name:     name,
+			Position: use.position,
+			Token:    tok,
+
+			Choices: choices,
+		})
+	}
+
+	sort.Slice(p.definitions, func(i, j int) bool {
+		return p.definitions[i].Position.Offset < p.definitions[j].Position.Offset
+	})
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"encoding/binary"
 	"fmt"
 	"io/ioutil"
 	"math"
@@ -11,6 +12,7 @@ import (
 	. "github.com/zimmski/tavor/test/assert"
 
 	"github.com/zimmski/tavor"
+	"github.com/zimmski/tavor/encoding"
 	"github.com/zimmski/tavor/fuzz/strategy"
 	"github.com/zimmski/tavor/test"
 	"github.com/zimmski/tavor/token"
@@ -18,6 +20,7 @@ import (
 	"github.com/zimmski/tavor/token/conditions"
 	"github.com/zimmski/tavor/token/constraints"
 	"github.com/zimmski/tavor/token/expressions"
+	"github.com/zimmski/tavor/token/filters"
 	"github.com/zimmski/tavor/token/lists"
 	"github.com/zimmski/tavor/token/primitives"
 	"github.com/zimmski/tavor/token/sequences"
@@ -549,6 +552,92 @@ func TestTavorParserAlternationsAndGroupings(t *testing.T) {
 	)))
 }
 
+func TestTavorParserWeights(t *testing.T) {
+	var tok token.Token
+	var err error
+
+	// weighted alternation
+	tok, err = ParseTavor(strings.NewReader("START = 10: 1 | 2 | 0.5: 3\n"))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(lists.NewOneWithWeights(
+		[]float64{10, 1, 0.5},
+		primitives.NewConstantInt(1),
+		primitives.NewConstantInt(2),
+		primitives.NewConstantInt(3),
+	)))
+
+	// weighted optional alternation
+	tok, err = ParseTavor(strings.NewReader("START = 3: 1 | 1: 2 | 4:\n"))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(constraints.NewOptionalWithProbability(lists.NewOneWithWeights(
+		[]float64{3, 1},
+		primitives.NewConstantInt(1),
+		primitives.NewConstantInt(2),
+	), 0.5)))
+
+	// weighted concatenations
+	tok, err = ParseTavor(strings.NewReader("START = 2: 1 2 | 3\n"))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(lists.NewOneWithWeights(
+		[]float64{2, 1},
+		lists.NewConcatenation(
+			primitives.NewConstantInt(1),
+			primitives.NewConstantInt(2),
+		),
+		primitives.NewConstantInt(3),
+	)))
+
+	// optional group with probability
+	tok, err = ParseTavor(strings.NewReader("START = ?0.3(1)\n"))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(constraints.NewOptionalWithProbability(
+		primitives.NewConstantInt(1),
+		0.3,
+	)))
+
+	// repeat groups with probability
+	tok, err = ParseTavor(strings.NewReader("START = *0.8(1)\n"))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(lists.NewRepeatWithProbability(
+		primitives.NewConstantInt(1),
+		primitives.NewConstantInt(0),
+		primitives.NewConstantInt(int(tavor.MaxRepeat)),
+		0.8,
+	)))
+
+	tok, err = ParseTavor(strings.NewReader("START = +0.8(1)\n"))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(lists.NewRepeatWithProbability(
+		primitives.NewConstantInt(1),
+		primitives.NewConstantInt(1),
+		primitives.NewConstantInt(int(tavor.MaxRepeat)),
+		0.8,
+	)))
+
+	tok, err = ParseTavor(strings.NewReader("START = +2,4:0.5(1)\n"))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(lists.NewRepeatWithProbability(
+		primitives.NewConstantInt(1),
+		primitives.NewConstantInt(2),
+		primitives.NewConstantInt(4),
+		0.5,
+	)))
+
+	// weights are only allowed for alternation terms
+	tok, err = ParseTavor(strings.NewReader("START = 10: 1\n"))
+	Equal(t, token.ParseErrorInvalidArgumentValue, err.(*token.ParserError).Type)
+	Nil(t, tok)
+
+	// probabilities must be between 0 and 1
+	tok, err = ParseTavor(strings.NewReader("START = ?1.5(1)\n"))
+	Equal(t, token.ParseErrorInvalidArgumentValue, err.(*token.ParserError).Type)
+	Nil(t, tok)
+
+	tok, err = ParseTavor(strings.NewReader("START = +2:a(1)\n"))
+	Equal(t, token.ParseErrorInvalidArgumentValue, err.(*token.ParserError).Type)
+	Nil(t, tok)
+}
+
 func TestTavorParserTokenAttributes(t *testing.T) {
 	// token attribute List.Count
 	{
@@ -641,6 +730,37 @@ func TestTavorParserTypedTokens(t *testing.T) {
 	Nil(t, err)
 	Equal(t, tok, primitives.NewScope(primitives.NewRangeInt(-10, math.MaxInt32)))
 
+	// BinaryInt
+	tok, err = ParseTavor(strings.NewReader(
+		"$Spec u16le\nSTART = Spec\n",
+	))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(primitives.NewBinaryInt(2, binary.LittleEndian, 0, math.MaxUint16)))
+
+	tok, err = ParseTavor(strings.NewReader(
+		"$Spec u32be = from: 0x10,\nto: 0xFF\nSTART = Spec\n",
+	))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(primitives.NewBinaryInt(4, binary.BigEndian, 16, 255)))
+
+	tok, err = ParseTavor(strings.NewReader(
+		"$Spec varint = to: 300\nSTART = Spec\n",
+	))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(primitives.NewBinaryInt(0, nil, 0, 300)))
+
+	tok, err = ParseTavor(strings.NewReader(
+		"$Spec u8 = to: 256\nSTART = Spec\n",
+	))
+	Equal(t, token.ParseErrorInvalidArgumentValue, err.(*token.ParserError).Type)
+	Nil(t, tok)
+
+	tok, err = ParseTavor(strings.NewReader(
+		"$Spec u8 = from: -1\nSTART = Spec\n",
+	))
+	Equal(t, token.ParseErrorInvalidArgumentValue, err.(*token.ParserError).Type)
+	Nil(t, tok)
+
 	// Sequence
 	{
 		s := sequences.NewSequence(1, 1)
@@ -805,12 +925,68 @@ func TestTavorParserExpressions(t *testing.T) {
 	))
 	Nil(t, err)
 	Equal(t, tok, primitives.NewScope(expressions.NewAddArithmetic(
-		primitives.NewConstantInt(1),
 		expressions.NewAddArithmetic(
+			primitives.NewConstantInt(1),
+			primitives.NewConstantInt(2),
+		),
+		primitives.NewConstantInt(3),
+	)))
+
+	// operator precedence
+	tok, err = ParseTavor(strings.NewReader(
+		"START = ${2 * 3 + 4}\n",
+	))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(expressions.NewAddArithmetic(
+		expressions.NewMulArithmetic(
 			primitives.NewConstantInt(2),
 			primitives.NewConstantInt(3),
 		),
+		primitives.NewConstantInt(4),
 	)))
+	Equal(t, "10", tok.String())
+
+	// parentheses
+	tok, err = ParseTavor(strings.NewReader(
+		"START = ${2 * (3 + 4)}\n",
+	))
+	Nil(t, err)
+	Equal(t, tok, primitives.NewScope(expressions.NewMulArithmetic(
+		primitives.NewConstantInt(2),
+		expressions.NewAddArithmetic(
+			primitives.NewConstantInt(3),
+			primitives.NewConstantInt(4),
+		),
+	)))
+	Equal(t, "14", tok.String())
+
+	for expression, expected := range map[string]string{
+		"10 - 4 - 3":          "3",
+		"100 / 10 / 5":        "2",
+		"17 % 5":              "2",
+		"2 + 17 % 5 * 3":      "8",
+		"6 & 3":               "2",
+		"6 | 3":               "7",
+		"6 ^ 3":               "5",
+		"1 << 4":              "16",
+		"256 >> 2 + 2":        "16",
+		"1 | 2 ^ 3 & 6":       "1",
+		"(1 | 2) ^ (3 & 6)":   "1",
+		"((2 + 3)) * (4 - 1)": "15",
+		"16 >> 1 << (1 + 1)":  "32",
+	} {
+		tok, err = ParseTavor(strings.NewReader(
+			"START = ${" + expression + "}\n",
+		))
+		Nil(t, err, expression)
+		Equal(t, expected, tok.String(), expression)
+	}
+
+	// missing closing parenthesis
+	_, err = ParseTavor(strings.NewReader(
+		"START = ${2 * (3 + 4}\n",
+	))
+	Equal(t, token.ParseErrorExpectRune, err.(*token.ParserError).Type)
 
 	// mixed operator
 	{
@@ -1448,6 +1624,492 @@ func TestTavorParserCharacterClasses(t *testing.T) {
 
 		Equal(t, " ", tok.String())
 	}
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = [^\x20-\x2F\d] [\p{Greek}\P{L}] [\pN\W]
+		`))
+		Nil(t, err)
+		Equal(t, tok, primitives.NewScope(lists.NewConcatenation(
+			primitives.NewCharacterClass(`^\x20-\x2F\d`),
+			primitives.NewCharacterClass(`\p{Greek}\P{L}`),
+			primitives.NewCharacterClass(`\pN\W`),
+		)))
+
+		Equal(t, ":Ͱ0", tok.String())
+	}
+	{
+		tok, err := ParseTavorWithUniverse(strings.NewReader(`
+			START = [^a-z]
+		`), primitives.CharacterUniverseASCII)
+		Nil(t, err)
+		Equal(t, tok, primitives.NewScope(primitives.NewCharacterClassWithUniverse(`^a-z`, primitives.CharacterUniverseASCII)))
+
+		Equal(t, "\x00", tok.String())
+	}
+}
+
+func TestTavorParserBinary(t *testing.T) {
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = 0x89504E47 "\x0d\x0a"
+		`))
+		Nil(t, err)
+		Equal(t, tok, primitives.NewScope(lists.NewConcatenation(
+			primitives.NewConstantString("\x89PNG"),
+			primitives.NewConstantString("\r\n"),
+		)))
+
+		Equal(t, "\x89PNG\r\n", tok.String())
+	}
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = 0x00-0x1F
+		`))
+		Nil(t, err)
+		Equal(t, tok, primitives.NewScope(primitives.NewBinaryInt(1, nil, 0, 0x1F)))
+
+		Equal(t, "\x00", tok.String())
+	}
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = 0x123
+		`))
+		Equal(t, token.ParseErrorInvalidByteLiteral, err.(*token.ParserError).Type)
+		Nil(t, tok)
+	}
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = 0x1F-0x00
+		`))
+		Equal(t, token.ParseErrorInvalidByteLiteral, err.(*token.ParserError).Type)
+		Nil(t, tok)
+	}
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = 0x00-0x0102
+		`))
+		Equal(t, token.ParseErrorInvalidByteLiteral, err.(*token.ParserError).Type)
+		Nil(t, tok)
+	}
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = 0x00-"a"
+		`))
+		Equal(t, token.ParseErrorInvalidByteLiteral, err.(*token.ParserError).Type)
+		Nil(t, tok)
+	}
+}
+
+func TestTavorParserComputedValues(t *testing.T) {
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			Payload = "a" | "bb"
+			START = ${len(Payload)} ":" Payload
+		`))
+		Nil(t, err)
+		Equal(t, "1:a", tok.String())
+
+		Nil(t, ParseInternal(tok, strings.NewReader("2:bb")))
+		Equal(t, "2:bb", tok.String())
+
+		errs := ParseInternal(tok, strings.NewReader("1:bb"))
+		Equal(t, 1, len(errs))
+		Equal(t, token.ParseErrorUnexpectedData, errs[0].(*token.ParserError).Type)
+	}
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			Payload = "hello"
+			START = ${crc32(Payload, u32be)} Payload
+		`))
+		Nil(t, err)
+		Equal(t, "\x36\x10\xa6\x86hello", tok.String())
+
+		Nil(t, ParseInternal(tok, strings.NewReader("\x36\x10\xa6\x86hello")))
+
+		errs := ParseInternal(tok, strings.NewReader("\x36\x10\xa6\x87hello"))
+		Equal(t, 1, len(errs))
+		Equal(t, token.ParseErrorUnexpectedData, errs[0].(*token.ParserError).Type)
+	}
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			Body = +2("a")
+			START = Body ${md5(Body)}
+		`))
+		Nil(t, err)
+		Equal(t, "aa4124bc0a9335c27f086f24ba207a4912", tok.String())
+	}
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			Payload = "a"
+			START = ${len(Payload, raw)} Payload
+		`))
+		Equal(t, token.ParseErrorInvalidArgumentValue, err.(*token.ParserError).Type)
+		Nil(t, tok)
+	}
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = ${len(Payload)}
+		`))
+		Equal(t, token.ParseErrorTokenNotDefined, err.(*token.ParserError).Type)
+		Nil(t, tok)
+	}
+}
+
+func TestTavorParserParameterisedTokens(t *testing.T) {
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			List(X) = "(" X *("," X) ")"
+
+			Number = 1
+			Letter = "a"
+
+			START = List(Number) List(Letter)
+		`))
+		Nil(t, err)
+		Equal(t, "(1)(a)", tok.String())
+	}
+	{
+		// same instances are the same tokens, different instances are different tokens
+		tok, err := ParseTavor(strings.NewReader(`
+			START = Pair(Digit, Digit) Pair(Digit, Letter)
+
+			Pair(A, B) = A "=" B ";"
+
+			Digit = 1 | 2
+			Letter = "a"
+		`))
+		Nil(t, err)
+		Equal(t, primitives.NewScope(lists.NewConcatenation(
+			primitives.NewScope(lists.NewConcatenation(
+				primitives.NewScope(lists.NewOne(
+					primitives.NewConstantInt(1),
+					primitives.NewConstantInt(2),
+				)),
+				primitives.NewConstantString("="),
+				primitives.NewScope(lists.NewOne(
+					primitives.NewConstantInt(1),
+					primitives.NewConstantInt(2),
+				)),
+				primitives.NewConstantString(";"),
+			)),
+			primitives.NewScope(lists.NewConcatenation(
+				primitives.NewScope(lists.NewOne(
+					primitives.NewConstantInt(1),
+					primitives.NewConstantInt(2),
+				)),
+				primitives.NewConstantString("="),
+				primitives.NewScope(primitives.NewConstantString("a")),
+				primitives.NewConstantString(";"),
+			)),
+		)), tok)
+	}
+	{
+		// nested usages, multi line definitions and attributes of arguments
+		tok, err := ParseTavor(strings.NewReader(`
+			List(X) = "(" X ")"
+			Counted(X) = $X.Count ":" X,
+			             "!"
+
+			Items = +3("i")
+
+			START = List(List(Items)) " " Counted(Items)
+		`))
+		Nil(t, err)
+		Equal(t, "((iii)) 3:iii!", tok.String())
+	}
+	{
+		// recursive usage
+		tok, err := ParseTavor(strings.NewReader(`
+			Tree(X) = X | "[" Tree(X) "]"
+			Leaf = "x"
+			START = Tree(Leaf)
+		`))
+		Nil(t, err)
+		Equal(t, "x", tok.String())
+	}
+	{
+		// recursive usage with ever growing arguments
+		tok, err := ParseTavor(strings.NewReader(`
+			T(X) = X | "a" T(T(X))
+			Leaf = "x"
+			START = T(Leaf)
+		`))
+		Equal(t, token.ParseErrEndlessLoopDetected, err.(*token.ParserError).Type)
+		Nil(t, tok)
+	}
+	{
+		// errors
+		tok, err := ParseTavor(strings.NewReader(`
+			Pair(A, B) = A B
+			START = Pair(A)
+			A = 1
+		`))
+		Equal(t, token.ParseErrorInvalidArgumentValue, err.(*token.ParserError).Type)
+		Nil(t, tok)
+
+		tok, err = ParseTavor(strings.NewReader(`
+			Wrap(X) = "(" X Y ")"
+			Number = 1
+			START = Wrap(Number)
+		`))
+		Equal(t, token.ParseErrorTokenNotDefined, err.(*token.ParserError).Type)
+		Nil(t, tok)
+
+		tok, err = ParseTavor(strings.NewReader(`
+			Wrap(X) = "(" X )
+			Number = 1
+			START = Wrap(Number)
+		`))
+		Equal(t, 2, err.(*token.ParserError).Position.Line)
+		Nil(t, tok)
+
+		tok, err = ParseTavor(strings.NewReader(`
+			Wrap(1) = "(" X ")"
+			START = 1
+		`))
+		Equal(t, token.ParseErrorInvalidTokenName, err.(*token.ParserError).Type)
+		Nil(t, tok)
+
+		// undefined arguments are reported at the usage of the template
+		tok, err = ParseTavor(strings.NewReader(`
+			Wrap(X) = "(" X ")"
+
+			Number = 1
+
+			START = Wrap(Number) Wrap(Nope)
+		`))
+		Equal(t, token.ParseErrorTokenNotDefined, err.(*token.ParserError).Type)
+		Equal(t, 6, err.(*token.ParserError).Position.Line)
+		Equal(t, 30, err.(*token.ParserError).Position.Column)
+		Nil(t, tok)
+
+		tok, err = ParseTavor(strings.NewReader(`
+			Unused(X) = X X
+			Number = 1
+			START = Number
+		`))
+		Equal(t, token.ParseErrorUnusedToken, err.(*token.ParserError).Type)
+		Equal(t, 2, err.(*token.ParserError).Position.Line)
+		Nil(t, tok)
+	}
+	{
+		// arguments and parameters can be continued on the next line after a comma
+		tok, err := ParseTavor(strings.NewReader(`
+			Pair(A,
+			     B) = A "=" B
+
+			Number = 1
+			Letter = "a"
+
+			START = Pair(Number,
+			             Letter)
+		`))
+		Nil(t, err)
+		Equal(t, "1=a", tok.String())
+	}
+}
+
+func TestTavorParserRecursion(t *testing.T) {
+	{
+		// the recursion is not unrolled while parsing
+		tok, err := ParseTavor(strings.NewReader(`
+			Expr = "x" | "(" $Expr.Recursion(3) ")"
+
+			START = Expr
+		`))
+		Nil(t, err)
+
+		var recursions []*primitives.Recursion
+		Nil(t, token.WalkInternal(tok, func(tok token.Token) error {
+			if r, ok := tok.(*primitives.Recursion); ok {
+				recursions = append(recursions, r)
+			}
+
+			return nil
+		}))
+		Equal(t, 1, len(recursions))
+		Equal(t, 3, recursions[0].MaxDepth())
+		Nil(t, recursions[0].InternalGet())
+	}
+	{
+		// every depth up to the maximum is generated
+		tok, err := ParseTavor(strings.NewReader(`
+			Expr = "x" | "(" $Expr.Recursion(3) ")"
+
+			START = Expr
+		`))
+		Nil(t, err)
+
+		ch, err := strategy.NewAllPermutations(tok, test.NewRandTest(1))
+		Nil(t, err)
+
+		var got []string
+
+		for i := range ch {
+			got = append(got, tok.String())
+
+			ch <- i
+		}
+
+		Equal(t, []string{"x", "(x)", "((x))", "(((x)))"}, got)
+	}
+	{
+		// the maximum depth is checked while parsing data
+		tok, err := ParseTavor(strings.NewReader(`
+			Expr = "x" | "[" $Expr.Recursion(4) "+" $Expr.Recursion(4) "]"
+
+			START = Expr
+		`))
+		Nil(t, err)
+
+		Equal(t, 0, len(ParseInternal(tok, strings.NewReader("[[x+[x+[x+x]]]+x]"))))
+		NotEqual(t, 0, len(ParseInternal(tok, strings.NewReader("[[x+[x+[x+[x+x]]]]+x]"))))
+	}
+	{
+		// the default maximum depth is the maximum repeat
+		tok, err := ParseTavor(strings.NewReader(`
+			Expr = "x" | "(" $Expr.Recursion ")"
+
+			START = Expr
+		`))
+		Nil(t, err)
+
+		Equal(t, 0, len(ParseInternal(tok, strings.NewReader(strings.Repeat("(", tavor.MaxRepeat)+"x"+strings.Repeat(")", tavor.MaxRepeat)))))
+		NotEqual(t, 0, len(ParseInternal(tok, strings.NewReader(strings.Repeat("(", tavor.MaxRepeat+1)+"x"+strings.Repeat(")", tavor.MaxRepeat+1)))))
+	}
+	{
+		// invalid maximum depth
+		tok, err := ParseTavor(strings.NewReader(`
+			Expr = "x" | "(" $Expr.Recursion(0) ")"
+
+			START = Expr
+		`))
+		Equal(t, token.ParseErrorInvalidArgumentValue, err.(*token.ParserError).Type)
+		Nil(t, tok)
+	}
+}
+
+func TestTavorParserEncoding(t *testing.T) {
+	{
+		// the referenced token is encoded
+		tok, err := ParseTavor(strings.NewReader(`
+			Name = "Zoë" | "Chloé"
+
+			START = "<" $Name.Encoding(utf16be) ">"
+		`))
+		Nil(t, err)
+
+		var encoded []*primitives.Encoded
+		Nil(t, token.WalkInternal(tok, func(tok token.Token) error {
+			if e, ok := tok.(*primitives.Encoded); ok {
+				encoded = append(encoded, e)
+			}
+
+			return nil
+		}))
+		Equal(t, 1, len(encoded))
+		Equal(t, "utf16be", encoded[0].Name())
+		Equal(t, encoding.ErrorModeReport, encoded[0].Mode())
+
+		Equal(t, "<\x00Z\x00o\x00\xeb>", tok.String())
+
+		Equal(t, 0, len(ParseInternal(tok, strings.NewReader("<\x00C\x00h\x00l\x00o\x00\xe9>"))))
+		NotEqual(t, 0, len(ParseInternal(tok, strings.NewReader("<Chloé>"))))
+	}
+	{
+		// the error mode is an optional argument
+		tok, err := ParseTavor(strings.NewReader(`
+			START = $Name.Encoding(latin1, replace) $Name.Encoding(latin1, raw)
+
+			Name = "€"
+		`))
+		Nil(t, err)
+
+		Equal(t, "?€", tok.String())
+	}
+	{
+		// unknown encoding
+		tok, err := ParseTavor(strings.NewReader(`
+			Name = "a"
+
+			START = $Name.Encoding(ebcdic)
+		`))
+		Equal(t, token.ParseErrorInvalidArgumentValue, err.(*token.ParserError).Type)
+		Nil(t, tok)
+	}
+	{
+		// unknown error mode
+		tok, err := ParseTavor(strings.NewReader(`
+			Name = "a"
+
+			START = $Name.Encoding(utf8, ignore)
+		`))
+		Equal(t, token.ParseErrorInvalidArgumentValue, err.(*token.ParserError).Type)
+		Nil(t, tok)
+	}
+	{
+		// the encoding name is required
+		tok, err := ParseTavor(strings.NewReader(`
+			Name = "a"
+
+			START = $Name.Encoding
+		`))
+		NotNil(t, err)
+		Nil(t, tok)
+	}
+}
+
+func TestTavorParserTransforms(t *testing.T) {
+	{
+		// the output of the referenced token is encoded
+		tok, err := ParseTavor(strings.NewReader(`
+			Query = "a b" | "c&d"
+
+			START = "q=" ${urlencode(Query)} "&x=" ${hex(Query)}
+		`))
+		Nil(t, err)
+
+		var transforms []*filters.Transform
+		Nil(t, token.WalkInternal(tok, func(tok token.Token) error {
+			if f, ok := tok.(*filters.Transform); ok {
+				transforms = append(transforms, f)
+			}
+
+			return nil
+		}))
+		Equal(t, 2, len(transforms))
+		Equal(t, "urlencode", transforms[0].Function())
+		Equal(t, "hex", transforms[1].Function())
+
+		Equal(t, "q=a+b&x=612062", tok.String())
+
+		Equal(t, 0, len(ParseInternal(tok, strings.NewReader("q=c%26d&x=612062"))))
+		NotEqual(t, 0, len(ParseInternal(tok, strings.NewReader("q=c&d&x=612062"))))
+		NotEqual(t, 0, len(ParseInternal(tok, strings.NewReader("q=a b&x=612062"))))
+	}
+	{
+		// transforms can be nested
+		tok, err := ParseTavor(strings.NewReader(`
+			Body = "hello" | "world"
+
+			START = "<" ${base64(gzip(Body))} ">"
+		`))
+		Nil(t, err)
+
+		out := tok.String()
+		True(t, strings.HasPrefix(out, "<H4sI"))
+
+		Equal(t, 0, len(ParseInternal(tok, strings.NewReader(out))))
+		NotEqual(t, 0, len(ParseInternal(tok, strings.NewReader("<hello>"))))
+	}
+	{
+		// a transform needs a token argument
+		tok, err := ParseTavor(strings.NewReader(`
+			START = ${base64()}
+		`))
+		NotNil(t, err)
+		Nil(t, tok)
+	}
 }
 
 func TestTavorParserVariables(t *testing.T) {
@@ -1673,6 +2335,96 @@ func TestTavorParserIfElseIfElsedd(t *testing.T) {
 	}
 }
 
+func TestTavorParserIfOperators(t *testing.T) {
+	// comparisons and logical operators
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = 5<var> {if var.Value != 1 && not (var.Value < 3 || var.Value >= 10)} "a" {endif}
+		`))
+		Nil(t, err)
+
+		nVariable := variables.NewVariable("var", primitives.NewConstantInt(5))
+
+		Equal(t, tok, primitives.NewScope(lists.NewConcatenation(
+			nVariable,
+			conditions.NewIf(
+				conditions.IfPair{
+					Head: conditions.NewBooleanAnd(
+						conditions.NewBooleanNotEqual(variables.NewVariableValue(nVariable), primitives.NewConstantInt(1)),
+						conditions.NewBooleanNot(conditions.NewBooleanOr(
+							conditions.NewBooleanLess(variables.NewVariableValue(nVariable), primitives.NewConstantInt(3)),
+							conditions.NewBooleanGreaterEqual(variables.NewVariableValue(nVariable), primitives.NewConstantInt(10)),
+						)),
+					),
+					Body: primitives.NewConstantString("a"),
+				},
+			),
+		)))
+
+		Equal(t, "5a", tok.String())
+	}
+	// && binds stronger than ||
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = 5<var> {if var.Value == 1 && var.Value == 2 || var.Value == 5} "a" {else} "b" {endif}
+		`))
+		Nil(t, err)
+		Equal(t, "5a", tok.String())
+
+		tok, err = ParseTavor(strings.NewReader(`
+			START = 5<var> {if var.Value == 1 && (var.Value == 2 || var.Value == 5)} "a" {else} "b" {endif}
+		`))
+		Nil(t, err)
+		Equal(t, "5b", tok.String())
+	}
+	// arithmetic operators bind stronger than comparisons
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = 5<var> {if (var.Value + 1) * 2 == 12 && var.Value & 1 == 1 && 1 << 2 < var.Value} "a" {else} "b" {endif}
+		`))
+		Nil(t, err)
+		Equal(t, "5a", tok.String())
+	}
+	// string predicates
+	{
+		tok, err := ParseTavor(strings.NewReader(`
+			START = Choose<var> Print
+
+			Choose = "alice" | "bob" | "carol"
+
+			Print = {if var.Value startswith "a"} "1" {else if var.Value endswith "ol"} "2" {else if var.Value contains "o" && var.Value matches "^b.b$"} "3" {endif}
+		`))
+		Nil(t, err)
+
+		variable, _ := tok.(*primitives.Scope).InternalGet().(*lists.Concatenation).InternalGet(0)
+		one := variable.(*variables.Variable).InternalGet().(*primitives.Scope).InternalGet()
+
+		Equal(t, "alice1", tok.String())
+
+		Nil(t, one.Permutation(1))
+		Equal(t, "bob3", tok.String())
+
+		Nil(t, one.Permutation(2))
+		Equal(t, "carol2", tok.String())
+	}
+	// errors
+	for _, format := range []string{
+		`START = 1<var> {if var.Value} 2 {endif}`,
+		`START = 1<var> {if var.Value == 1 && var.Value} 2 {endif}`,
+		`START = 1<var> {if not var.Value} 2 {endif}`,
+	} {
+		_, err := ParseTavor(strings.NewReader(format))
+		Equal(t, token.ParseErrorUnknownBooleanOperator, err.(*token.ParserError).Type, format)
+	}
+	for _, format := range []string{
+		`START = 1<var> {if var.Value == 1 &&} 2 {endif}`,
+		`START = 1<var> {if (var.Value == 1} 2 {endif}`,
+	} {
+		_, err := ParseTavor(strings.NewReader(format))
+		NotNil(t, err, format)
+	}
+}
+
 func TestParseTavorExpressionOperatorInclude(t *testing.T) {
 	tmpfile, err := ioutil.TempFile("", "")
 	Nil(t, err)
@@ -1689,3 +2441,61 @@ func TestParseTavorExpressionOperatorInclude(t *testing.T) {
 	Nil(t, err)
 	Equal(t, tok, primitives.NewScope(primitives.NewConstantInt(123)))
 }
+
+func TestParseTavorDefinitions(t *testing.T) {
+	tok, definitions, err := ParseTavorDefinitions(strings.NewReader(`
+START = Pair ?(Digit)
+Pair = Digit Digit
+Digit = 0 | 1 | 2
+$Counter Sequence = start: 1,
+	step: 1
+Unused = 1
+`))
+	Nil(t, tok)
+	Nil(t, definitions)
+	NotNil(t, err)
+
+	tok, definitions, err = ParseTavorDefinitions(strings.NewReader(`
+START = Pair ?(Digit) $Counter.Next
+Pair = Digit Digit
+Digit = 0 | 1 | 2
+$Counter Sequence = start: 1,
+	step: 1
+`))
+	Nil(t, err)
+	Equal(t, 36, tok.PermutationsAll())
+
+	var names []string
+	counts := make(map[string]uint)
+	for _, d := range definitions {
+		names = append(names, d.Name)
+		counts[d.Name] = d.Token.PermutationsAll()
+	}
+
+	Equal(t, []string{"START", "Pair", "Digit"}, names)
+	Equal(t, map[string]uint{
+		"START": 36,
+		"Pair":  9,
+		"Digit": 3,
+	}, counts)
+
+	// choices know their position in the format source
+	Equal(t, 1, len(definitions[0].Choices))
+	Equal(t, "2:14", fmt.Sprintf("%d:%d", definitions[0].Choices[0].Position().Line, definitions[0].Choices[0].Position().Column))
+	Equal(t, 0, len(definitions[1].Choices))
+	Equal(t, 1, len(definitions[2].Choices))
+	Equal(t, "4:9", fmt.Sprintf("%d:%d", definitions[2].Choices[0].Position().Line, definitions[2].Choices[0].Position().Column))
+
+	// the positions are part of the token graph
+	var positions []string
+	err = token.Walk(tok, func(tok token.Token) error {
+		if t, ok := tok.(token.PositionedToken); ok {
+			pos := t.Position()
+			positions = append(positions, fmt.Sprintf("%d:%d", pos.Line, pos.Column))
+		}
+
+		return nil
+	})
+	Nil(t, err)
+	Equal(t, []string{"4:9", "4:9", "2:14", "4:9"}, positions)
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 type argumentsParser struct {
@@ -31,7 +32,7 @@ func (ap *argumentsParser) GetInt(name string, defaultValue int) int {
 		return defaultValue
 	}
 
-	val, err := strconv.Atoi(raw)
+	val, err := parseInt(raw)
 	if err != nil {
 		ap.err = fmt.Errorf("%q needs an integer value", name)
 		return -1
@@ -41,6 +42,22 @@ func (ap *argumentsParser) GetInt(name string, defaultValue int) int {
 	return val
 }
 
+// parseInt parses a decimal or, if prefixed with "0x", a hexadecimal integer with an optional sign
+func parseInt(raw string) (int, error) {
+	sign := ""
+	if strings.HasPrefix(raw, "-") || strings.HasPrefix(raw, "+") {
+		sign, raw = raw[:1], raw[1:]
+	}
+
+	if strings.HasPrefix(raw, "0x") || strings.HasPrefix(raw, "0X") {
+		v, err := strconv.ParseInt(sign+raw[2:], 16, 0)
+
+		return int(v), err
+	}
+
+	return strconv.Atoi(sign + raw)
+}
+
 // Err returns the first error encountered by the ArgumentsParser
 func (ap *argumentsParser) Err() error {
 	return ap.err
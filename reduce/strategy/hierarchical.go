@@ -0,0 +1,276 @@
+package strategy
+
+import (
+	"github.com/zimmski/container/list/linkedlist"
+
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/token"
+)
+
+func init() {
+	Register("Hierarchical", NewHierarchical)
+}
+
+// hierarchicalUnit is one reducible child of a level e.g. an item of a repeat or the referenced token of an optional
+type hierarchicalUnit struct {
+	token token.ReduceSelectToken
+	index int
+	child token.Token
+}
+
+type hierarchicalStrategy struct {
+	root token.Token
+
+	continueReducing chan struct{}
+	feedbackReducing <-chan ReduceFeedbackType
+}
+
+// NewHierarchical implements a reduce strategy that reduces the data through hierarchical delta debugging.
+// The reducible children of the token graph, which are for example the items of repeats and the referenced tokens of optionals, are grouped into levels. Every level is minimized with the ddmin algorithm by testing subsets and complements of partitions of its children. The partitions are refined until no child of the level can be removed anymore. Only the children which are kept are then used to reduce the next level. Every step of the strategy generates a new valid token graph state and the generation is deterministic. Compared to the linear strategy many children can be removed with one step which needs far less feedback for big inputs.
+func NewHierarchical(root token.Token) (chan struct{}, chan<- ReduceFeedbackType, error) {
+	if token.LoopExists(root) {
+		return nil, nil, &Error{
+			Message: "found endless loop in graph. Cannot proceed.",
+			Type:    ErrEndlessLoopDetected,
+		}
+	}
+
+	continueReducing := make(chan struct{})
+	feedbackReducing := make(chan ReduceFeedbackType)
+
+	s := &hierarchicalStrategy{
+		root: root,
+
+		continueReducing: continueReducing,
+		feedbackReducing: feedbackReducing,
+	}
+
+	go func() {
+		log.Debug("start hierarchical routine")
+
+		level := s.getLevel(s.root)
+
+		if len(level) > 0 {
+			log.Debug("start reducing step")
+
+			if contin := s.reduce(level); !contin {
+				return
+			}
+		} else {
+			log.Debug("no reduceable tokens to begin with")
+		}
+
+		log.Debug("finished reducing")
+
+		close(continueReducing)
+		close(feedbackReducing)
+	}()
+
+	return continueReducing, feedbackReducing, nil
+}
+
+func (s *hierarchicalStrategy) reduce(level []hierarchicalUnit) bool {
+	log.Debugf("reducing level with %d units", len(level))
+
+	kept, contin := s.ddmin(level)
+	if !contin {
+		return false
+	}
+
+	for _, u := range kept {
+		children := s.getLevel(u.child)
+
+		if len(children) > 0 {
+			log.Debugf("reduce the children of (%p)%#v at %d", u.token, u.token, u.index)
+
+			if contin := s.reduce(children); !contin {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// ddmin returns the minimal selection of the level's units which still produces good feedback. The original selection of all units is assumed to be good.
+func (s *hierarchicalStrategy) ddmin(level []hierarchicalUnit) ([]hierarchicalUnit, bool) {
+	// removing everything is the biggest possible reduction
+	good, contin := s.test(level, nil)
+	if !contin {
+		return nil, false
+	} else if good {
+		return nil, true
+	}
+
+	current := level
+	n := 2
+
+	for len(current) >= 2 {
+		chunks := partition(current, n)
+		reduced := false
+
+		for _, chunk := range chunks {
+			good, contin := s.test(level, chunk)
+			if !contin {
+				return nil, false
+			} else if good {
+				current = chunk
+				n = 2
+				reduced = true
+
+				break
+			}
+		}
+
+		// with two chunks the complements are the chunks themselves
+		if !reduced && n > 2 {
+			for i := range chunks {
+				complement := make([]hierarchicalUnit, 0, len(current)-len(chunks[i]))
+				for j, chunk := range chunks {
+					if i != j {
+						complement = append(complement, chunk...)
+					}
+				}
+
+				good, contin := s.test(level, complement)
+				if !contin {
+					return nil, false
+				} else if good {
+					current = complement
+					n--
+					reduced = true
+
+					break
+				}
+			}
+		}
+
+		if !reduced {
+			if n >= len(current) {
+				break
+			}
+
+			n *= 2
+			if n > len(current) {
+				n = len(current)
+			}
+		}
+	}
+
+	log.Debugf("reduced level from %d to %d units", len(level), len(current))
+
+	if !s.apply(level, current) {
+		panic("could not apply a good selection")
+	}
+
+	return current, true
+}
+
+// test applies the selection of the level's units and returns true if the feedback is good. Selections which cannot be applied are not handed over as reducing step and count as bad feedback.
+func (s *hierarchicalStrategy) test(level []hierarchicalUnit, selection []hierarchicalUnit) (bool, bool) {
+	if !s.apply(level, selection) {
+		log.Debug("skip selection which cannot be applied")
+
+		return false, true
+	}
+
+	contin, feedback := nextStep(s.root, s.continueReducing, s.feedbackReducing)
+
+	return feedback == Good, contin
+}
+
+// apply reduces every token of the level to the given selection of units and returns false if this is not allowed by a token
+func (s *hierarchicalStrategy) apply(level []hierarchicalUnit, selection []hierarchicalUnit) bool {
+	var tokens []token.ReduceSelectToken
+	selections := make(map[token.ReduceSelectToken][]int)
+
+	for _, u := range level {
+		if _, ok := selections[u.token]; !ok {
+			tokens = append(tokens, u.token)
+			selections[u.token] = []int{}
+		}
+	}
+	for _, u := range selection {
+		selections[u.token] = append(selections[u.token], u.index)
+	}
+
+	for _, t := range tokens {
+		log.Debugf("select %v of (%p)%#v", selections[t], t, t)
+
+		if err := t.ReduceSelect(selections[t]); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getLevel returns the reducible units of the given token which are not children of other reducible units
+func (s *hierarchicalStrategy) getLevel(root token.Token) []hierarchicalUnit {
+	var level []hierarchicalUnit
+	var queue = linkedlist.New()
+
+	queue.Unshift(root)
+
+	for !queue.Empty() {
+		tok, _ := queue.Shift()
+
+		if t, ok := tok.(token.ReduceSelectToken); ok {
+			if n := t.ReduceSelectable(); n > 0 {
+				for i := 0; i < n; i++ {
+					level = append(level, hierarchicalUnit{
+						token: t,
+						index: i,
+						child: child(t, i),
+					})
+				}
+
+				continue
+			}
+		}
+
+		switch t := tok.(type) {
+		case token.ForwardToken:
+			if c := t.Get(); c != nil {
+				queue.Unshift(c)
+			}
+		case token.ListToken:
+			for i := t.Len() - 1; i >= 0; i-- {
+				c, _ := t.Get(i)
+
+				queue.Unshift(c)
+			}
+		}
+	}
+
+	return level
+}
+
+func child(tok token.Token, i int) token.Token {
+	switch t := tok.(type) {
+	case token.ForwardToken:
+		return t.Get()
+	case token.ListToken:
+		c, _ := t.Get(i)
+
+		return c
+	}
+
+	return nil
+}
+
+// partition splits the units into n chunks of nearly the same size
+func partition(units []hierarchicalUnit, n int) [][]hierarchicalUnit {
+	chunks := make([][]hierarchicalUnit, 0, n)
+
+	start := 0
+	for i := 0; i < n; i++ {
+		end := start + (len(units)-start)/(n-i)
+
+		chunks = append(chunks, units[start:end])
+
+		start = end
+	}
+
+	return chunks
+}
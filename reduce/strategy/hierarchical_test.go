@@ -0,0 +1,171 @@
+package strategy
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/parser"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/lists"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func TestHierarchicalStrategy(t *testing.T) {
+	{
+		root := primitives.NewConstantInt(1)
+
+		contin, _, err := NewHierarchical(root)
+		Nil(t, err)
+
+		_, ok := <-contin
+		False(t, ok)
+
+		Equal(t, "1", root.String())
+	}
+	{
+		// Test that inputs are never changed if they cannot be reduced
+
+		root := lists.NewRepeat(primitives.NewCharacterClass(`\w`), 10, 10)
+		input := "KrOxDOj4fU"
+
+		errs := parser.ParseInternal(root, bytes.NewBufferString(input))
+		Nil(t, errs)
+
+		contin, _, err := NewHierarchical(root)
+		Nil(t, err)
+
+		_, ok := <-contin
+		False(t, ok)
+
+		Equal(t, input, root.String())
+	}
+	{
+		// Test the subsets and complements of the partitions
+		tok := lists.NewRepeat(primitives.NewCharacterClass(`\d`), 0, 100)
+
+		validateTavorHierarchical(
+			t,
+			tok,
+			"12345678",
+			func(out string) ReduceFeedbackType {
+				if strings.Contains(out, "3") && strings.Contains(out, "6") {
+					return Good
+				}
+
+				return Bad
+			},
+			[]string{
+				"",
+				"1234", "5678",
+				"12", "34", "56", "78",
+				"345678",
+				"34", "56", "78",
+				"5678", "3478", "3456",
+				"34", "56",
+				"3", "4", "5", "6",
+				"456", "356",
+				"3", "5", "6",
+				"56", "36",
+				"3", "6",
+			},
+			"36",
+		)
+	}
+	{
+		// Test that the minimum repetition is never undercut
+		tok := lists.NewRepeat(primitives.NewCharacterClass(`\d`), 2, 100)
+
+		validateTavorHierarchical(
+			t,
+			tok,
+			"1234",
+			func(out string) ReduceFeedbackType {
+				if strings.Contains(out, "4") {
+					return Good
+				}
+
+				return Bad
+			},
+			[]string{
+				// selections with less than two digits are skipped
+				"12", "34",
+			},
+			"34",
+		)
+	}
+	{
+		// Hierarchical reduction which reduces only the kept children further
+		tok, err := parser.ParseTavor(bytes.NewBufferString(`
+			START = A *(B)
+
+			A = ?("a")
+			B = "b" ?(C)
+			C = "c"
+		`))
+		Nil(t, err)
+
+		validateTavorHierarchical(
+			t,
+			tok,
+			"abcbc",
+			func(out string) ReduceFeedbackType {
+				if strings.Contains(out, "bc") {
+					return Good
+				}
+
+				return Bad
+			},
+			[]string{
+				"",
+				"a", "bcbc",
+				"bc",
+				// the optional of the kept B
+				"b",
+			},
+			"bc",
+		)
+	}
+}
+
+func validateTavorHierarchical(t *testing.T, tok token.Token, input string, feedback func(out string) ReduceFeedbackType, expected []string, final string) {
+	errs := parser.ParseInternal(tok, bytes.NewBufferString(input))
+	Nil(t, errs)
+	if errs != nil {
+		panic(errs)
+	}
+
+	Equal(t, input, tok.String(), "Generation 0")
+
+	continueFuzzing, feedbackReducing, err := NewHierarchical(tok)
+	if err != nil {
+		panic(err)
+	}
+
+	n := 0
+
+	for i := range continueFuzzing {
+		out := tok.String()
+
+		if n == len(expected) {
+			Fail(t, fmt.Sprintf("%q is an unexpected generation at index  %d", out, n))
+		} else {
+			Equal(t, expected[n], out, fmt.Sprintf("Generation %d", n))
+		}
+		n++
+
+		feedbackReducing <- feedback(out)
+
+		continueFuzzing <- i
+	}
+
+	Equal(t, len(expected), n, "Generation count")
+	Equal(t, final, tok.String(), "Final generation")
+}
+
+func TestHierarchicalStrategyLoopDetection(t *testing.T) {
+	testStrategyLoopDetection(t, NewHierarchical)
+}
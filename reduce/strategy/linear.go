@@ -106,7 +106,9 @@ func (s *linearStrategy) reduce(continueReducing chan struct{}, feedbackReducing
 		if len(c.children) > 0 {
 			log.Debugf("reduce the children of (%p)%#v %d/%d", c.token, c.token, c.reduction, c.maxReductions)
 
-			s.reduce(continueReducing, feedbackReducing, c.children)
+			if contin := s.reduce(continueReducing, feedbackReducing, c.children); !contin {
+				return false
+			}
 		}
 	}
 
@@ -114,35 +116,7 @@ func (s *linearStrategy) reduce(continueReducing chan struct{}, feedbackReducing
 }
 
 func (s *linearStrategy) nextStep(continueReducing chan struct{}, feedbackReducing <-chan ReduceFeedbackType) (bool, ReduceFeedbackType) {
-	token.ResetScope(s.root)
-	_ = token.ResetResetTokens(s.root)
-	token.ResetScope(s.root)
-
-	log.Debug("done with reducing step")
-
-	// done with this reduce step
-	continueReducing <- struct{}{}
-
-	// wait until we got feedback to the current state
-	feedback, ok := <-feedbackReducing
-	if ok {
-		log.Debugf("GOT FEEDBACK -> Looks %s", feedback)
-	} else {
-		log.Debug("reducing feedback channel closed from outside")
-
-		return false, Unknown
-	}
-
-	// wait until we are allowed to continue
-	if _, ok := <-continueReducing; !ok {
-		log.Debug("reducing continue channel closed from outside")
-
-		return false, Unknown
-	}
-
-	log.Debug("start reducing step")
-
-	return true, feedback
+	return nextStep(s.root, continueReducing, feedbackReducing)
 }
 
 func (s *linearStrategy) getTree(root token.Token, fromChildren bool) []linearStrategyLevel {
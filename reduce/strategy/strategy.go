@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/zimmski/tavor/log"
 	"github.com/zimmski/tavor/token"
 )
 
@@ -80,3 +81,36 @@ func Register(name string, strat Strategy) {
 
 	strategyLookup[name] = strat
 }
+
+// nextStep hands the current state of the token graph over as a reducing step and waits for its feedback. It returns false if the reducing was stopped from outside.
+func nextStep(root token.Token, continueReducing chan struct{}, feedbackReducing <-chan ReduceFeedbackType) (bool, ReduceFeedbackType) {
+	token.ResetScope(root)
+	_ = token.ResetResetTokens(root)
+	token.ResetScope(root)
+
+	log.Debug("done with reducing step")
+
+	// done with this reduce step
+	continueReducing <- struct{}{}
+
+	// wait until we got feedback to the current state
+	feedback, ok := <-feedbackReducing
+	if ok {
+		log.Debugf("GOT FEEDBACK -> Looks %s", feedback)
+	} else {
+		log.Debug("reducing feedback channel closed from outside")
+
+		return false, Unknown
+	}
+
+	// wait until we are allowed to continue
+	if _, ok := <-continueReducing; !ok {
+		log.Debug("reducing continue channel closed from outside")
+
+		return false, Unknown
+	}
+
+	log.Debug("start reducing step")
+
+	return true, feedback
+}
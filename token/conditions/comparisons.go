@@ -0,0 +1,1026 @@
+package conditions
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/lists"
+)
+
+// compareValues compares the values of two tokens numerically if both values are integers and lexicographically otherwise.
+// The result is negative if a is less than b, zero if they are equal and positive if a is greater than b.
+func compareValues(a, b token.Token) int {
+	as, bs := a.String(), b.String()
+
+	if ai, err := strconv.Atoi(as); err == nil {
+		if bi, err := strconv.Atoi(bs); err == nil {
+			switch {
+			case ai < bi:
+				return -1
+			case ai > bi:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return strings.Compare(as, bs)
+}
+
+// matchValue returns true if the value of the token matches the regular expression which is the value of the pattern token. Invalid regular expressions never match.
+func matchValue(tok, pattern token.Token) bool {
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		log.Errorf("invalid regular expression %q: %v", pattern.String(), err)
+
+		return false
+	}
+
+	return re.MatchString(tok.String())
+}
+
+// BooleanNotEqual implements a boolean expression which checks that the values of two tokens differ
+type BooleanNotEqual struct {
+	a, b token.Token
+}
+
+// NewBooleanNotEqual returns a new instance of a BooleanNotEqual token referencing two tokens
+func NewBooleanNotEqual(a, b token.Token) *BooleanNotEqual {
+	return &BooleanNotEqual{
+		a: a,
+		b: b,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanNotEqual) Evaluate() bool {
+	return c.a.String() != c.b.String()
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanNotEqual) Clone() token.Token {
+	return &BooleanNotEqual{
+		a: c.a,
+		b: c.b,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanNotEqual) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanNotEqual) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanNotEqual) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanNotEqual) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanNotEqual) String() string {
+	return fmt.Sprintf("(%p)%#v != (%p)%#v", c.a, c.a, c.b, c.b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanNotEqual) Get(i int) (token.Token, error) {
+	return nil, &lists.ListError{
+		Type: lists.ListErrorOutOfBound,
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (c *BooleanNotEqual) Len() int {
+	return 0
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanNotEqual) InternalGet(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return c.a, nil
+	case 1:
+		return c.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (c *BooleanNotEqual) InternalLen() int {
+	return 2
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanNotEqual) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == c.a || tok == c.b {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanNotEqual) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == c.a {
+		c.a = newToken
+	}
+	if oldToken == c.b {
+		c.b = newToken
+	}
+
+	return nil
+}
+
+// BooleanLess implements a boolean expression which checks that the value of the first token is less than the value of the second token
+type BooleanLess struct {
+	a, b token.Token
+}
+
+// NewBooleanLess returns a new instance of a BooleanLess token referencing two tokens
+func NewBooleanLess(a, b token.Token) *BooleanLess {
+	return &BooleanLess{
+		a: a,
+		b: b,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanLess) Evaluate() bool {
+	return compareValues(c.a, c.b) < 0
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanLess) Clone() token.Token {
+	return &BooleanLess{
+		a: c.a,
+		b: c.b,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanLess) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanLess) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanLess) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanLess) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanLess) String() string {
+	return fmt.Sprintf("(%p)%#v < (%p)%#v", c.a, c.a, c.b, c.b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanLess) Get(i int) (token.Token, error) {
+	return nil, &lists.ListError{
+		Type: lists.ListErrorOutOfBound,
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (c *BooleanLess) Len() int {
+	return 0
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanLess) InternalGet(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return c.a, nil
+	case 1:
+		return c.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (c *BooleanLess) InternalLen() int {
+	return 2
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanLess) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == c.a || tok == c.b {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanLess) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == c.a {
+		c.a = newToken
+	}
+	if oldToken == c.b {
+		c.b = newToken
+	}
+
+	return nil
+}
+
+// BooleanLessEqual implements a boolean expression which checks that the value of the first token is less than or equal to the value of the second token
+type BooleanLessEqual struct {
+	a, b token.Token
+}
+
+// NewBooleanLessEqual returns a new instance of a BooleanLessEqual token referencing two tokens
+func NewBooleanLessEqual(a, b token.Token) *BooleanLessEqual {
+	return &BooleanLessEqual{
+		a: a,
+		b: b,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanLessEqual) Evaluate() bool {
+	return compareValues(c.a, c.b) <= 0
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanLessEqual) Clone() token.Token {
+	return &BooleanLessEqual{
+		a: c.a,
+		b: c.b,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanLessEqual) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanLessEqual) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanLessEqual) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanLessEqual) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanLessEqual) String() string {
+	return fmt.Sprintf("(%p)%#v <= (%p)%#v", c.a, c.a, c.b, c.b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanLessEqual) Get(i int) (token.Token, error) {
+	return nil, &lists.ListError{
+		Type: lists.ListErrorOutOfBound,
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (c *BooleanLessEqual) Len() int {
+	return 0
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanLessEqual) InternalGet(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return c.a, nil
+	case 1:
+		return c.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (c *BooleanLessEqual) InternalLen() int {
+	return 2
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanLessEqual) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == c.a || tok == c.b {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanLessEqual) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == c.a {
+		c.a = newToken
+	}
+	if oldToken == c.b {
+		c.b = newToken
+	}
+
+	return nil
+}
+
+// BooleanGreater implements a boolean expression which checks that the value of the first token is greater than the value of the second token
+type BooleanGreater struct {
+	a, b token.Token
+}
+
+// NewBooleanGreater returns a new instance of a BooleanGreater token referencing two tokens
+func NewBooleanGreater(a, b token.Token) *BooleanGreater {
+	return &BooleanGreater{
+		a: a,
+		b: b,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanGreater) Evaluate() bool {
+	return compareValues(c.a, c.b) > 0
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanGreater) Clone() token.Token {
+	return &BooleanGreater{
+		a: c.a,
+		b: c.b,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanGreater) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanGreater) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanGreater) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanGreater) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanGreater) String() string {
+	return fmt.Sprintf("(%p)%#v > (%p)%#v", c.a, c.a, c.b, c.b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanGreater) Get(i int) (token.Token, error) {
+	return nil, &lists.ListError{
+		Type: lists.ListErrorOutOfBound,
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (c *BooleanGreater) Len() int {
+	return 0
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanGreater) InternalGet(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return c.a, nil
+	case 1:
+		return c.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (c *BooleanGreater) InternalLen() int {
+	return 2
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanGreater) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == c.a || tok == c.b {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanGreater) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == c.a {
+		c.a = newToken
+	}
+	if oldToken == c.b {
+		c.b = newToken
+	}
+
+	return nil
+}
+
+// BooleanGreaterEqual implements a boolean expression which checks that the value of the first token is greater than or equal to the value of the second token
+type BooleanGreaterEqual struct {
+	a, b token.Token
+}
+
+// NewBooleanGreaterEqual returns a new instance of a BooleanGreaterEqual token referencing two tokens
+func NewBooleanGreaterEqual(a, b token.Token) *BooleanGreaterEqual {
+	return &BooleanGreaterEqual{
+		a: a,
+		b: b,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanGreaterEqual) Evaluate() bool {
+	return compareValues(c.a, c.b) >= 0
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanGreaterEqual) Clone() token.Token {
+	return &BooleanGreaterEqual{
+		a: c.a,
+		b: c.b,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanGreaterEqual) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanGreaterEqual) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanGreaterEqual) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanGreaterEqual) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanGreaterEqual) String() string {
+	return fmt.Sprintf("(%p)%#v >= (%p)%#v", c.a, c.a, c.b, c.b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanGreaterEqual) Get(i int) (token.Token, error) {
+	return nil, &lists.ListError{
+		Type: lists.ListErrorOutOfBound,
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (c *BooleanGreaterEqual) Len() int {
+	return 0
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanGreaterEqual) InternalGet(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return c.a, nil
+	case 1:
+		return c.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (c *BooleanGreaterEqual) InternalLen() int {
+	return 2
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanGreaterEqual) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == c.a || tok == c.b {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanGreaterEqual) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == c.a {
+		c.a = newToken
+	}
+	if oldToken == c.b {
+		c.b = newToken
+	}
+
+	return nil
+}
+
+// BooleanContains implements a boolean expression which checks that the value of the first token contains the value of the second token
+type BooleanContains struct {
+	a, b token.Token
+}
+
+// NewBooleanContains returns a new instance of a BooleanContains token referencing two tokens
+func NewBooleanContains(a, b token.Token) *BooleanContains {
+	return &BooleanContains{
+		a: a,
+		b: b,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanContains) Evaluate() bool {
+	return strings.Contains(c.a.String(), c.b.String())
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanContains) Clone() token.Token {
+	return &BooleanContains{
+		a: c.a,
+		b: c.b,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanContains) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanContains) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanContains) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanContains) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanContains) String() string {
+	return fmt.Sprintf("(%p)%#v contains (%p)%#v", c.a, c.a, c.b, c.b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanContains) Get(i int) (token.Token, error) {
+	return nil, &lists.ListError{
+		Type: lists.ListErrorOutOfBound,
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (c *BooleanContains) Len() int {
+	return 0
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanContains) InternalGet(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return c.a, nil
+	case 1:
+		return c.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (c *BooleanContains) InternalLen() int {
+	return 2
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanContains) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == c.a || tok == c.b {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanContains) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == c.a {
+		c.a = newToken
+	}
+	if oldToken == c.b {
+		c.b = newToken
+	}
+
+	return nil
+}
+
+// BooleanStartsWith implements a boolean expression which checks that the value of the first token starts with the value of the second token
+type BooleanStartsWith struct {
+	a, b token.Token
+}
+
+// NewBooleanStartsWith returns a new instance of a BooleanStartsWith token referencing two tokens
+func NewBooleanStartsWith(a, b token.Token) *BooleanStartsWith {
+	return &BooleanStartsWith{
+		a: a,
+		b: b,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanStartsWith) Evaluate() bool {
+	return strings.HasPrefix(c.a.String(), c.b.String())
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanStartsWith) Clone() token.Token {
+	return &BooleanStartsWith{
+		a: c.a,
+		b: c.b,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanStartsWith) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanStartsWith) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanStartsWith) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanStartsWith) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanStartsWith) String() string {
+	return fmt.Sprintf("(%p)%#v startswith (%p)%#v", c.a, c.a, c.b, c.b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanStartsWith) Get(i int) (token.Token, error) {
+	return nil, &lists.ListError{
+		Type: lists.ListErrorOutOfBound,
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (c *BooleanStartsWith) Len() int {
+	return 0
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanStartsWith) InternalGet(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return c.a, nil
+	case 1:
+		return c.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (c *BooleanStartsWith) InternalLen() int {
+	return 2
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanStartsWith) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == c.a || tok == c.b {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanStartsWith) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == c.a {
+		c.a = newToken
+	}
+	if oldToken == c.b {
+		c.b = newToken
+	}
+
+	return nil
+}
+
+// BooleanEndsWith implements a boolean expression which checks that the value of the first token ends with the value of the second token
+type BooleanEndsWith struct {
+	a, b token.Token
+}
+
+// NewBooleanEndsWith returns a new instance of a BooleanEndsWith token referencing two tokens
+func NewBooleanEndsWith(a, b token.Token) *BooleanEndsWith {
+	return &BooleanEndsWith{
+		a: a,
+		b: b,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanEndsWith) Evaluate() bool {
+	return strings.HasSuffix(c.a.String(), c.b.String())
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanEndsWith) Clone() token.Token {
+	return &BooleanEndsWith{
+		a: c.a,
+		b: c.b,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanEndsWith) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanEndsWith) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanEndsWith) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanEndsWith) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanEndsWith) String() string {
+	return fmt.Sprintf("(%p)%#v endswith (%p)%#v", c.a, c.a, c.b, c.b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanEndsWith) Get(i int) (token.Token, error) {
+	return nil, &lists.ListError{
+		Type: lists.ListErrorOutOfBound,
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (c *BooleanEndsWith) Len() int {
+	return 0
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanEndsWith) InternalGet(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return c.a, nil
+	case 1:
+		return c.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (c *BooleanEndsWith) InternalLen() int {
+	return 2
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanEndsWith) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == c.a || tok == c.b {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanEndsWith) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == c.a {
+		c.a = newToken
+	}
+	if oldToken == c.b {
+		c.b = newToken
+	}
+
+	return nil
+}
+
+// BooleanMatches implements a boolean expression which checks that the value of the first token matches the regular expression which is the value of the second token
+type BooleanMatches struct {
+	a, b token.Token
+}
+
+// NewBooleanMatches returns a new instance of a BooleanMatches token referencing two tokens
+func NewBooleanMatches(a, b token.Token) *BooleanMatches {
+	return &BooleanMatches{
+		a: a,
+		b: b,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanMatches) Evaluate() bool {
+	return matchValue(c.a, c.b)
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanMatches) Clone() token.Token {
+	return &BooleanMatches{
+		a: c.a,
+		b: c.b,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanMatches) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanMatches) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanMatches) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanMatches) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanMatches) String() string {
+	return fmt.Sprintf("(%p)%#v matches (%p)%#v", c.a, c.a, c.b, c.b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanMatches) Get(i int) (token.Token, error) {
+	return nil, &lists.ListError{
+		Type: lists.ListErrorOutOfBound,
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (c *BooleanMatches) Len() int {
+	return 0
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanMatches) InternalGet(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return c.a, nil
+	case 1:
+		return c.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (c *BooleanMatches) InternalLen() int {
+	return 2
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanMatches) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == c.a || tok == c.b {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanMatches) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == c.a {
+		c.a = newToken
+	}
+	if oldToken == c.b {
+		c.b = newToken
+	}
+
+	return nil
+}
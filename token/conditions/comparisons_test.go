@@ -0,0 +1,66 @@
+package conditions
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func TestComparisonsToBeBooleanExpression(t *testing.T) {
+	var ex *BooleanExpression
+
+	Implements(t, ex, &BooleanNotEqual{})
+	Implements(t, ex, &BooleanLess{})
+	Implements(t, ex, &BooleanLessEqual{})
+	Implements(t, ex, &BooleanGreater{})
+	Implements(t, ex, &BooleanGreaterEqual{})
+	Implements(t, ex, &BooleanContains{})
+	Implements(t, ex, &BooleanStartsWith{})
+	Implements(t, ex, &BooleanEndsWith{})
+	Implements(t, ex, &BooleanMatches{})
+}
+
+func TestBooleanNotEqual(t *testing.T) {
+	o := NewBooleanNotEqual(primitives.NewConstantInt(1), primitives.NewConstantInt(2))
+	True(t, o.Evaluate())
+
+	o = NewBooleanNotEqual(primitives.NewConstantInt(1), primitives.NewConstantInt(1))
+	False(t, o.Evaluate())
+}
+
+func TestBooleanLessAndGreater(t *testing.T) {
+	// integers are compared numerically
+	True(t, NewBooleanLess(primitives.NewConstantInt(2), primitives.NewConstantInt(10)).Evaluate())
+	False(t, NewBooleanLess(primitives.NewConstantInt(10), primitives.NewConstantInt(10)).Evaluate())
+	True(t, NewBooleanLessEqual(primitives.NewConstantInt(10), primitives.NewConstantInt(10)).Evaluate())
+	False(t, NewBooleanLessEqual(primitives.NewConstantInt(11), primitives.NewConstantInt(10)).Evaluate())
+	True(t, NewBooleanGreater(primitives.NewConstantInt(10), primitives.NewConstantInt(2)).Evaluate())
+	False(t, NewBooleanGreater(primitives.NewConstantInt(2), primitives.NewConstantInt(2)).Evaluate())
+	True(t, NewBooleanGreaterEqual(primitives.NewConstantInt(2), primitives.NewConstantInt(2)).Evaluate())
+	False(t, NewBooleanGreaterEqual(primitives.NewConstantInt(-3), primitives.NewConstantInt(2)).Evaluate())
+
+	// everything else is compared lexicographically
+	True(t, NewBooleanLess(primitives.NewConstantString("10"), primitives.NewConstantString("2a")).Evaluate())
+	True(t, NewBooleanGreater(primitives.NewConstantString("b"), primitives.NewConstantString("abc")).Evaluate())
+}
+
+func TestBooleanStringPredicates(t *testing.T) {
+	s := primitives.NewConstantString("foobar")
+
+	True(t, NewBooleanContains(s, primitives.NewConstantString("oba")).Evaluate())
+	False(t, NewBooleanContains(s, primitives.NewConstantString("baz")).Evaluate())
+
+	True(t, NewBooleanStartsWith(s, primitives.NewConstantString("foo")).Evaluate())
+	False(t, NewBooleanStartsWith(s, primitives.NewConstantString("bar")).Evaluate())
+
+	True(t, NewBooleanEndsWith(s, primitives.NewConstantString("bar")).Evaluate())
+	False(t, NewBooleanEndsWith(s, primitives.NewConstantString("foo")).Evaluate())
+
+	True(t, NewBooleanMatches(s, primitives.NewConstantString("^fo+b")).Evaluate())
+	False(t, NewBooleanMatches(s, primitives.NewConstantString("^bar")).Evaluate())
+
+	// invalid regular expressions never match
+	False(t, NewBooleanMatches(s, primitives.NewConstantString("(")).Evaluate())
+}
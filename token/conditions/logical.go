@@ -0,0 +1,342 @@
+package conditions
+
+import (
+	"fmt"
+
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/lists"
+)
+
+// BooleanAnd implements a boolean expression which evaluates to true if both of its boolean expressions are true
+type BooleanAnd struct {
+	a, b BooleanExpression
+}
+
+// NewBooleanAnd returns a new instance of a BooleanAnd token referencing two boolean expressions
+func NewBooleanAnd(a, b BooleanExpression) *BooleanAnd {
+	return &BooleanAnd{
+		a: a,
+		b: b,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanAnd) Evaluate() bool {
+	return c.a.Evaluate() && c.b.Evaluate()
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanAnd) Clone() token.Token {
+	return &BooleanAnd{
+		a: c.a.Clone().(BooleanExpression),
+		b: c.b.Clone().(BooleanExpression),
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanAnd) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanAnd) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanAnd) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanAnd) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanAnd) String() string {
+	return fmt.Sprintf("(%s && %s)", c.a, c.b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanAnd) Get(i int) (token.Token, error) {
+	return nil, &lists.ListError{
+		Type: lists.ListErrorOutOfBound,
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (c *BooleanAnd) Len() int {
+	return 0
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanAnd) InternalGet(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return c.a, nil
+	case 1:
+		return c.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (c *BooleanAnd) InternalLen() int {
+	return 2
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanAnd) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == c.a || tok == c.b {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanAnd) InternalReplace(oldToken, newToken token.Token) error {
+	ex, ok := newToken.(BooleanExpression)
+	if !ok {
+		return nil
+	}
+
+	if oldToken == c.a {
+		c.a = ex
+	}
+	if oldToken == c.b {
+		c.b = ex
+	}
+
+	return nil
+}
+
+// ScopeToken interface methods
+
+// SetScope sets the scope of the token
+func (c *BooleanAnd) SetScope(variableScope *token.VariableScope) {
+	token.SetScope(c.a, variableScope)
+	token.SetScope(c.b, variableScope)
+}
+
+// BooleanOr implements a boolean expression which evaluates to true if at least one of its boolean expressions is true
+type BooleanOr struct {
+	a, b BooleanExpression
+}
+
+// NewBooleanOr returns a new instance of a BooleanOr token referencing two boolean expressions
+func NewBooleanOr(a, b BooleanExpression) *BooleanOr {
+	return &BooleanOr{
+		a: a,
+		b: b,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanOr) Evaluate() bool {
+	return c.a.Evaluate() || c.b.Evaluate()
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanOr) Clone() token.Token {
+	return &BooleanOr{
+		a: c.a.Clone().(BooleanExpression),
+		b: c.b.Clone().(BooleanExpression),
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanOr) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanOr) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanOr) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanOr) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanOr) String() string {
+	return fmt.Sprintf("(%s || %s)", c.a, c.b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanOr) Get(i int) (token.Token, error) {
+	return nil, &lists.ListError{
+		Type: lists.ListErrorOutOfBound,
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (c *BooleanOr) Len() int {
+	return 0
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (c *BooleanOr) InternalGet(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return c.a, nil
+	case 1:
+		return c.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (c *BooleanOr) InternalLen() int {
+	return 2
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanOr) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == c.a || tok == c.b {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanOr) InternalReplace(oldToken, newToken token.Token) error {
+	ex, ok := newToken.(BooleanExpression)
+	if !ok {
+		return nil
+	}
+
+	if oldToken == c.a {
+		c.a = ex
+	}
+	if oldToken == c.b {
+		c.b = ex
+	}
+
+	return nil
+}
+
+// ScopeToken interface methods
+
+// SetScope sets the scope of the token
+func (c *BooleanOr) SetScope(variableScope *token.VariableScope) {
+	token.SetScope(c.a, variableScope)
+	token.SetScope(c.b, variableScope)
+}
+
+// BooleanNot implements a boolean expression which negates its boolean expression
+type BooleanNot struct {
+	a BooleanExpression
+}
+
+// NewBooleanNot returns a new instance of a BooleanNot token referencing the given boolean expression
+func NewBooleanNot(a BooleanExpression) *BooleanNot {
+	return &BooleanNot{
+		a: a,
+	}
+}
+
+// Evaluate evaluates the boolean expression and returns its result
+func (c *BooleanNot) Evaluate() bool {
+	return !c.a.Evaluate()
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (c *BooleanNot) Clone() token.Token {
+	return &BooleanNot{
+		a: c.a.Clone().(BooleanExpression),
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (c *BooleanNot) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("This should never happen")
+}
+
+// Permutation sets a specific permutation for this token
+func (c *BooleanNot) Permutation(i uint) error {
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (c *BooleanNot) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (c *BooleanNot) PermutationsAll() uint {
+	return 1
+}
+
+func (c *BooleanNot) String() string {
+	return fmt.Sprintf("not %s", c.a)
+}
+
+// ForwardToken interface methods
+
+// Get returns the current referenced token
+func (c *BooleanNot) Get() token.Token {
+	return nil
+}
+
+// InternalGet returns the current referenced internal token
+func (c *BooleanNot) InternalGet() token.Token {
+	return c.a
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (c *BooleanNot) InternalLogicalRemove(tok token.Token) token.Token {
+	if c.a == tok {
+		return nil
+	}
+
+	return c
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (c *BooleanNot) InternalReplace(oldToken, newToken token.Token) error {
+	if ex, ok := newToken.(BooleanExpression); ok && c.a == oldToken {
+		c.a = ex
+	}
+
+	return nil
+}
+
+// ScopeToken interface methods
+
+// SetScope sets the scope of the token
+func (c *BooleanNot) SetScope(variableScope *token.VariableScope) {
+	token.SetScope(c.a, variableScope)
+}
@@ -0,0 +1,40 @@
+package conditions
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func TestLogicalToBeBooleanExpression(t *testing.T) {
+	var ex *BooleanExpression
+
+	Implements(t, ex, &BooleanAnd{})
+	Implements(t, ex, &BooleanOr{})
+	Implements(t, ex, &BooleanNot{})
+}
+
+func TestBooleanAndOrNot(t *testing.T) {
+	yes := NewBooleanTrue()
+	no := NewBooleanEqual(primitives.NewConstantInt(1), primitives.NewConstantInt(2))
+
+	True(t, NewBooleanAnd(yes, yes).Evaluate())
+	False(t, NewBooleanAnd(yes, no).Evaluate())
+	False(t, NewBooleanAnd(no, yes).Evaluate())
+
+	True(t, NewBooleanOr(yes, no).Evaluate())
+	True(t, NewBooleanOr(no, yes).Evaluate())
+	False(t, NewBooleanOr(no, no).Evaluate())
+
+	True(t, NewBooleanNot(no).Evaluate())
+	False(t, NewBooleanNot(yes).Evaluate())
+
+	// nested expressions
+	o := NewBooleanNot(NewBooleanAnd(yes, NewBooleanOr(no, yes)))
+	False(t, o.Evaluate())
+
+	o2 := o.Clone().(*BooleanNot)
+	Equal(t, o.Evaluate(), o2.Evaluate())
+}
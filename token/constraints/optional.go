@@ -1,6 +1,8 @@
 package constraints
 
 import (
+	"text/scanner"
+
 	"github.com/zimmski/tavor/token"
 )
 
@@ -9,6 +11,11 @@ type Optional struct {
 	token token.Token
 	value bool
 
+	weighted    bool
+	probability float64
+
+	position scanner.Position
+
 	reducing              bool
 	reducingOriginalValue bool
 }
@@ -21,6 +28,19 @@ func NewOptional(tok token.Token) *Optional {
 	}
 }
 
+// NewOptionalWithProbability returns a new instance of a Optional token referencing the given token which is activated with the given probability
+func NewOptionalWithProbability(tok token.Token, probability float64) *Optional {
+	if probability < 0 || probability > 1 {
+		panic("probability must be between 0 and 1")
+	}
+
+	c := NewOptional(tok)
+	c.weighted = true
+	c.probability = probability
+
+	return c
+}
+
 // Token interface methods
 
 // Clone returns a copy of the token and all its children
@@ -28,6 +48,11 @@ func (c *Optional) Clone() token.Token {
 	return &Optional{
 		token: c.token.Clone(),
 		value: c.value,
+
+		weighted:    c.weighted,
+		probability: c.probability,
+
+		position: c.position,
 	}
 }
 
@@ -73,7 +98,7 @@ func (c *Optional) Permutations() uint {
 
 // PermutationsAll returns the number of all possible permutations for this token including its children
 func (c *Optional) PermutationsAll() uint {
-	return 1 + c.token.PermutationsAll()
+	return token.AddPermutations(1, c.token.PermutationsAll())
 }
 
 func (c *Optional) String() string {
@@ -159,3 +184,54 @@ func (c *Optional) Reduces() uint {
 
 	return 0
 }
+
+// ReduceSelectToken interface methods
+
+// ReduceSelect reduces the token to the given selection of its original children
+// The empty selection deactivates the token, the selection of the only child restores the original state.
+func (c *Optional) ReduceSelect(selection []int) error {
+	if c.ReduceSelectable() == 0 || len(selection) > 1 || (len(selection) == 1 && selection[0] != 0) {
+		return &token.ReduceError{
+			Type: token.ReduceErrorInvalidSelection,
+		}
+	}
+
+	if len(selection) == 0 {
+		return c.Reduce(0)
+	}
+
+	return c.Reduce(1)
+}
+
+// ReduceSelectable returns the number of original children which can be selected
+func (c *Optional) ReduceSelectable() int {
+	if c.Reduces() == 0 {
+		return 0
+	}
+
+	return 1
+}
+
+// Weighted interface methods
+
+// Weights returns the relative weights of the permutations of the token or nil if all permutations have the same weight
+func (c *Optional) Weights() []float64 {
+	if !c.weighted {
+		return nil
+	}
+
+	// the first permutation deactivates the token
+	return []float64{1 - c.probability, c.probability}
+}
+
+// Positioned interface methods
+
+// Position returns the position of the token in the format source which is not valid if the position is unknown
+func (c *Optional) Position() scanner.Position {
+	return c.position
+}
+
+// SetPosition sets the position of the token in the format source
+func (c *Optional) SetPosition(pos scanner.Position) {
+	c.position = pos
+}
@@ -58,3 +58,49 @@ func TestOptionalOptionalTokenInterface(t *testing.T) {
 	Equal(t, o.Get(), a)
 	Equal(t, "1", o.String())
 }
+
+func TestOptionalReduceSelect(t *testing.T) {
+	a := primitives.NewConstantInt(1)
+
+	o := NewOptional(a)
+
+	var reduceSelectTok *token.ReduceSelectToken
+
+	Implements(t, reduceSelectTok, o)
+
+	Equal(t, 1, o.ReduceSelectable())
+
+	Nil(t, o.ReduceSelect(nil))
+	Equal(t, "", o.String())
+	Equal(t, 1, o.ReduceSelectable())
+
+	Nil(t, o.ReduceSelect([]int{0}))
+	Equal(t, "1", o.String())
+
+	Equal(t, o.ReduceSelect([]int{1}).(*token.ReduceError).Type, token.ReduceErrorInvalidSelection)
+	Equal(t, o.ReduceSelect([]int{0, 0}).(*token.ReduceError).Type, token.ReduceErrorInvalidSelection)
+
+	// a deactivated optional cannot be reduced
+	o = NewOptional(a)
+	o.Deactivate()
+
+	Equal(t, 0, o.ReduceSelectable())
+	Equal(t, o.ReduceSelect(nil).(*token.ReduceError).Type, token.ReduceErrorInvalidSelection)
+}
+
+func TestOptionalWithProbability(t *testing.T) {
+	a := primitives.NewConstantInt(1)
+
+	Nil(t, NewOptional(a).Weights())
+
+	o := NewOptionalWithProbability(a, 0.25)
+	Equal(t, "1", o.String())
+	Equal(t, []float64{0.75, 0.25}, o.Weights())
+
+	o2 := o.Clone().(*Optional)
+	Equal(t, []float64{0.75, 0.25}, o2.Weights())
+
+	Panics(t, func() {
+		NewOptionalWithProbability(a, 1.5)
+	})
+}
@@ -56,7 +56,7 @@ func (e *AddArithmetic) Permutations() uint {
 
 // PermutationsAll returns the number of all possible permutations for this token including its children
 func (e *AddArithmetic) PermutationsAll() uint {
-	return e.a.PermutationsAll() * e.b.PermutationsAll()
+	return token.MulPermutations(e.a.PermutationsAll(), e.b.PermutationsAll())
 }
 
 func (e *AddArithmetic) String() string {
@@ -180,7 +180,7 @@ func (e *SubArithmetic) Permutations() uint {
 
 // PermutationsAll returns the number of all possible permutations for this token including its children
 func (e *SubArithmetic) PermutationsAll() uint {
-	return e.a.PermutationsAll() * e.b.PermutationsAll()
+	return token.MulPermutations(e.a.PermutationsAll(), e.b.PermutationsAll())
 }
 
 func (e *SubArithmetic) String() string {
@@ -304,7 +304,7 @@ func (e *MulArithmetic) Permutations() uint {
 
 // PermutationsAll returns the number of all possible permutations for this token including its children
 func (e *MulArithmetic) PermutationsAll() uint {
-	return e.a.PermutationsAll() * e.b.PermutationsAll()
+	return token.MulPermutations(e.a.PermutationsAll(), e.b.PermutationsAll())
 }
 
 func (e *MulArithmetic) String() string {
@@ -428,7 +428,7 @@ func (e *DivArithmetic) Permutations() uint {
 
 // PermutationsAll returns the number of all possible permutations for this token including its children
 func (e *DivArithmetic) PermutationsAll() uint {
-	return e.a.PermutationsAll() * e.b.PermutationsAll()
+	return token.MulPermutations(e.a.PermutationsAll(), e.b.PermutationsAll())
 }
 
 func (e *DivArithmetic) String() string {
@@ -502,3 +502,747 @@ func (e *DivArithmetic) InternalReplace(oldToken, newToken token.Token) error {
 
 	return nil
 }
+
+// ModArithmetic implements an arithmetic token computing the remainder of the division of the values of two tokens
+type ModArithmetic struct {
+	a token.Token
+	b token.Token
+}
+
+// NewModArithmetic returns a new instance of a ModArithmetic token
+func NewModArithmetic(a, b token.Token) *ModArithmetic {
+	return &ModArithmetic{
+		a: a,
+		b: b,
+	}
+}
+
+// Clone returns a copy of the token and all its children
+func (e *ModArithmetic) Clone() token.Token {
+	return &ModArithmetic{
+		a: e.a.Clone(),
+		b: e.b.Clone(),
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (e *ModArithmetic) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("TODO implement")
+}
+
+// Permutation sets a specific permutation for this token
+func (e *ModArithmetic) Permutation(i uint) error {
+	permutations := e.Permutations()
+
+	if i < 0 || i >= permutations {
+		return &token.PermutationError{
+			Type: token.PermutationErrorIndexOutOfBound,
+		}
+	}
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (e *ModArithmetic) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (e *ModArithmetic) PermutationsAll() uint {
+	return token.MulPermutations(e.a.PermutationsAll(), e.b.PermutationsAll())
+}
+
+func (e *ModArithmetic) String() string {
+	as := e.a.String()
+	bs := e.b.String()
+
+	if as == "" || bs == "" || as == "TODO" || bs == "TODO" {
+		return "TODO"
+	}
+
+	a, err := strconv.Atoi(as)
+	if err != nil {
+		panic(err)
+	}
+	b, err := strconv.Atoi(bs)
+	if err != nil {
+		panic(err)
+	}
+
+	return strconv.Itoa(a % b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *ModArithmetic) Get(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return e.a, nil
+	case 1:
+		return e.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (e *ModArithmetic) Len() int {
+	return 2
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *ModArithmetic) InternalGet(i int) (token.Token, error) {
+	return e.Get(i)
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (e *ModArithmetic) InternalLen() int {
+	return e.Len()
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (e *ModArithmetic) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == e.a || tok == e.b {
+		return nil
+	}
+
+	return e
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (e *ModArithmetic) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == e.a {
+		e.a = newToken
+	}
+	if oldToken == e.b {
+		e.b = newToken
+	}
+
+	return nil
+}
+
+// AndArithmetic implements an arithmetic token combining the values of two tokens with a bitwise AND
+type AndArithmetic struct {
+	a token.Token
+	b token.Token
+}
+
+// NewAndArithmetic returns a new instance of a AndArithmetic token
+func NewAndArithmetic(a, b token.Token) *AndArithmetic {
+	return &AndArithmetic{
+		a: a,
+		b: b,
+	}
+}
+
+// Clone returns a copy of the token and all its children
+func (e *AndArithmetic) Clone() token.Token {
+	return &AndArithmetic{
+		a: e.a.Clone(),
+		b: e.b.Clone(),
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (e *AndArithmetic) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("TODO implement")
+}
+
+// Permutation sets a specific permutation for this token
+func (e *AndArithmetic) Permutation(i uint) error {
+	permutations := e.Permutations()
+
+	if i < 0 || i >= permutations {
+		return &token.PermutationError{
+			Type: token.PermutationErrorIndexOutOfBound,
+		}
+	}
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (e *AndArithmetic) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (e *AndArithmetic) PermutationsAll() uint {
+	return token.MulPermutations(e.a.PermutationsAll(), e.b.PermutationsAll())
+}
+
+func (e *AndArithmetic) String() string {
+	as := e.a.String()
+	bs := e.b.String()
+
+	if as == "" || bs == "" || as == "TODO" || bs == "TODO" {
+		return "TODO"
+	}
+
+	a, err := strconv.Atoi(as)
+	if err != nil {
+		panic(err)
+	}
+	b, err := strconv.Atoi(bs)
+	if err != nil {
+		panic(err)
+	}
+
+	return strconv.Itoa(a & b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *AndArithmetic) Get(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return e.a, nil
+	case 1:
+		return e.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (e *AndArithmetic) Len() int {
+	return 2
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *AndArithmetic) InternalGet(i int) (token.Token, error) {
+	return e.Get(i)
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (e *AndArithmetic) InternalLen() int {
+	return e.Len()
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (e *AndArithmetic) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == e.a || tok == e.b {
+		return nil
+	}
+
+	return e
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (e *AndArithmetic) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == e.a {
+		e.a = newToken
+	}
+	if oldToken == e.b {
+		e.b = newToken
+	}
+
+	return nil
+}
+
+// OrArithmetic implements an arithmetic token combining the values of two tokens with a bitwise OR
+type OrArithmetic struct {
+	a token.Token
+	b token.Token
+}
+
+// NewOrArithmetic returns a new instance of a OrArithmetic token
+func NewOrArithmetic(a, b token.Token) *OrArithmetic {
+	return &OrArithmetic{
+		a: a,
+		b: b,
+	}
+}
+
+// Clone returns a copy of the token and all its children
+func (e *OrArithmetic) Clone() token.Token {
+	return &OrArithmetic{
+		a: e.a.Clone(),
+		b: e.b.Clone(),
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (e *OrArithmetic) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("TODO implement")
+}
+
+// Permutation sets a specific permutation for this token
+func (e *OrArithmetic) Permutation(i uint) error {
+	permutations := e.Permutations()
+
+	if i < 0 || i >= permutations {
+		return &token.PermutationError{
+			Type: token.PermutationErrorIndexOutOfBound,
+		}
+	}
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (e *OrArithmetic) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (e *OrArithmetic) PermutationsAll() uint {
+	return token.MulPermutations(e.a.PermutationsAll(), e.b.PermutationsAll())
+}
+
+func (e *OrArithmetic) String() string {
+	as := e.a.String()
+	bs := e.b.String()
+
+	if as == "" || bs == "" || as == "TODO" || bs == "TODO" {
+		return "TODO"
+	}
+
+	a, err := strconv.Atoi(as)
+	if err != nil {
+		panic(err)
+	}
+	b, err := strconv.Atoi(bs)
+	if err != nil {
+		panic(err)
+	}
+
+	return strconv.Itoa(a | b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *OrArithmetic) Get(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return e.a, nil
+	case 1:
+		return e.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (e *OrArithmetic) Len() int {
+	return 2
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *OrArithmetic) InternalGet(i int) (token.Token, error) {
+	return e.Get(i)
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (e *OrArithmetic) InternalLen() int {
+	return e.Len()
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (e *OrArithmetic) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == e.a || tok == e.b {
+		return nil
+	}
+
+	return e
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (e *OrArithmetic) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == e.a {
+		e.a = newToken
+	}
+	if oldToken == e.b {
+		e.b = newToken
+	}
+
+	return nil
+}
+
+// XorArithmetic implements an arithmetic token combining the values of two tokens with a bitwise XOR
+type XorArithmetic struct {
+	a token.Token
+	b token.Token
+}
+
+// NewXorArithmetic returns a new instance of a XorArithmetic token
+func NewXorArithmetic(a, b token.Token) *XorArithmetic {
+	return &XorArithmetic{
+		a: a,
+		b: b,
+	}
+}
+
+// Clone returns a copy of the token and all its children
+func (e *XorArithmetic) Clone() token.Token {
+	return &XorArithmetic{
+		a: e.a.Clone(),
+		b: e.b.Clone(),
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (e *XorArithmetic) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("TODO implement")
+}
+
+// Permutation sets a specific permutation for this token
+func (e *XorArithmetic) Permutation(i uint) error {
+	permutations := e.Permutations()
+
+	if i < 0 || i >= permutations {
+		return &token.PermutationError{
+			Type: token.PermutationErrorIndexOutOfBound,
+		}
+	}
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (e *XorArithmetic) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (e *XorArithmetic) PermutationsAll() uint {
+	return token.MulPermutations(e.a.PermutationsAll(), e.b.PermutationsAll())
+}
+
+func (e *XorArithmetic) String() string {
+	as := e.a.String()
+	bs := e.b.String()
+
+	if as == "" || bs == "" || as == "TODO" || bs == "TODO" {
+		return "TODO"
+	}
+
+	a, err := strconv.Atoi(as)
+	if err != nil {
+		panic(err)
+	}
+	b, err := strconv.Atoi(bs)
+	if err != nil {
+		panic(err)
+	}
+
+	return strconv.Itoa(a ^ b)
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *XorArithmetic) Get(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return e.a, nil
+	case 1:
+		return e.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (e *XorArithmetic) Len() int {
+	return 2
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *XorArithmetic) InternalGet(i int) (token.Token, error) {
+	return e.Get(i)
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (e *XorArithmetic) InternalLen() int {
+	return e.Len()
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (e *XorArithmetic) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == e.a || tok == e.b {
+		return nil
+	}
+
+	return e
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (e *XorArithmetic) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == e.a {
+		e.a = newToken
+	}
+	if oldToken == e.b {
+		e.b = newToken
+	}
+
+	return nil
+}
+
+// ShiftLeftArithmetic implements an arithmetic token shifting the value of the first token to the left by the value of the second token
+type ShiftLeftArithmetic struct {
+	a token.Token
+	b token.Token
+}
+
+// NewShiftLeftArithmetic returns a new instance of a ShiftLeftArithmetic token
+func NewShiftLeftArithmetic(a, b token.Token) *ShiftLeftArithmetic {
+	return &ShiftLeftArithmetic{
+		a: a,
+		b: b,
+	}
+}
+
+// Clone returns a copy of the token and all its children
+func (e *ShiftLeftArithmetic) Clone() token.Token {
+	return &ShiftLeftArithmetic{
+		a: e.a.Clone(),
+		b: e.b.Clone(),
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (e *ShiftLeftArithmetic) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("TODO implement")
+}
+
+// Permutation sets a specific permutation for this token
+func (e *ShiftLeftArithmetic) Permutation(i uint) error {
+	permutations := e.Permutations()
+
+	if i < 0 || i >= permutations {
+		return &token.PermutationError{
+			Type: token.PermutationErrorIndexOutOfBound,
+		}
+	}
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (e *ShiftLeftArithmetic) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (e *ShiftLeftArithmetic) PermutationsAll() uint {
+	return token.MulPermutations(e.a.PermutationsAll(), e.b.PermutationsAll())
+}
+
+func (e *ShiftLeftArithmetic) String() string {
+	as := e.a.String()
+	bs := e.b.String()
+
+	if as == "" || bs == "" || as == "TODO" || bs == "TODO" {
+		return "TODO"
+	}
+
+	a, err := strconv.Atoi(as)
+	if err != nil {
+		panic(err)
+	}
+	b, err := strconv.Atoi(bs)
+	if err != nil {
+		panic(err)
+	}
+
+	return strconv.Itoa(a << uint(b))
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *ShiftLeftArithmetic) Get(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return e.a, nil
+	case 1:
+		return e.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (e *ShiftLeftArithmetic) Len() int {
+	return 2
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *ShiftLeftArithmetic) InternalGet(i int) (token.Token, error) {
+	return e.Get(i)
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (e *ShiftLeftArithmetic) InternalLen() int {
+	return e.Len()
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (e *ShiftLeftArithmetic) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == e.a || tok == e.b {
+		return nil
+	}
+
+	return e
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (e *ShiftLeftArithmetic) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == e.a {
+		e.a = newToken
+	}
+	if oldToken == e.b {
+		e.b = newToken
+	}
+
+	return nil
+}
+
+// ShiftRightArithmetic implements an arithmetic token shifting the value of the first token to the right by the value of the second token
+type ShiftRightArithmetic struct {
+	a token.Token
+	b token.Token
+}
+
+// NewShiftRightArithmetic returns a new instance of a ShiftRightArithmetic token
+func NewShiftRightArithmetic(a, b token.Token) *ShiftRightArithmetic {
+	return &ShiftRightArithmetic{
+		a: a,
+		b: b,
+	}
+}
+
+// Clone returns a copy of the token and all its children
+func (e *ShiftRightArithmetic) Clone() token.Token {
+	return &ShiftRightArithmetic{
+		a: e.a.Clone(),
+		b: e.b.Clone(),
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (e *ShiftRightArithmetic) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	panic("TODO implement")
+}
+
+// Permutation sets a specific permutation for this token
+func (e *ShiftRightArithmetic) Permutation(i uint) error {
+	permutations := e.Permutations()
+
+	if i < 0 || i >= permutations {
+		return &token.PermutationError{
+			Type: token.PermutationErrorIndexOutOfBound,
+		}
+	}
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (e *ShiftRightArithmetic) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (e *ShiftRightArithmetic) PermutationsAll() uint {
+	return token.MulPermutations(e.a.PermutationsAll(), e.b.PermutationsAll())
+}
+
+func (e *ShiftRightArithmetic) String() string {
+	as := e.a.String()
+	bs := e.b.String()
+
+	if as == "" || bs == "" || as == "TODO" || bs == "TODO" {
+		return "TODO"
+	}
+
+	a, err := strconv.Atoi(as)
+	if err != nil {
+		panic(err)
+	}
+	b, err := strconv.Atoi(bs)
+	if err != nil {
+		panic(err)
+	}
+
+	return strconv.Itoa(a >> uint(b))
+}
+
+// List interface methods
+
+// Get returns the current referenced token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *ShiftRightArithmetic) Get(i int) (token.Token, error) {
+	switch i {
+	case 0:
+		return e.a, nil
+	case 1:
+		return e.b, nil
+	default:
+		return nil, &lists.ListError{
+			Type: lists.ListErrorOutOfBound,
+		}
+	}
+}
+
+// Len returns the number of the current referenced tokens
+func (e *ShiftRightArithmetic) Len() int {
+	return 2
+}
+
+// InternalGet returns the current referenced internal token at the given index. The error return argument is not nil, if the index is out of bound.
+func (e *ShiftRightArithmetic) InternalGet(i int) (token.Token, error) {
+	return e.Get(i)
+}
+
+// InternalLen returns the number of referenced internal tokens
+func (e *ShiftRightArithmetic) InternalLen() int {
+	return e.Len()
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (e *ShiftRightArithmetic) InternalLogicalRemove(tok token.Token) token.Token {
+	if tok == e.a || tok == e.b {
+		return nil
+	}
+
+	return e
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (e *ShiftRightArithmetic) InternalReplace(oldToken, newToken token.Token) error {
+	if oldToken == e.a {
+		e.a = newToken
+	}
+	if oldToken == e.b {
+		e.b = newToken
+	}
+
+	return nil
+}
@@ -17,6 +17,12 @@ func TestArithmeticExpressionTokensToBeTokens(t *testing.T) {
 	Implements(t, tok, &SubArithmetic{})
 	Implements(t, tok, &MulArithmetic{})
 	Implements(t, tok, &DivArithmetic{})
+	Implements(t, tok, &ModArithmetic{})
+	Implements(t, tok, &AndArithmetic{})
+	Implements(t, tok, &OrArithmetic{})
+	Implements(t, tok, &XorArithmetic{})
+	Implements(t, tok, &ShiftLeftArithmetic{})
+	Implements(t, tok, &ShiftRightArithmetic{})
 }
 
 func TestAddArithmetic(t *testing.T) {
@@ -122,3 +128,159 @@ func TestDivArithmetic(t *testing.T) {
 	o2 := o.Clone()
 	Equal(t, o.String(), o2.String())
 }
+
+func TestModArithmetic(t *testing.T) {
+	a := primitives.NewRangeInt(6, 10)
+	b := primitives.NewConstantInt(4)
+
+	o := NewModArithmetic(a, b)
+	Equal(t, "2", o.String())
+	Equal(t, 1, o.Permutations())
+	Equal(t, 5, o.PermutationsAll())
+
+	i, err := o.Get(0)
+	Nil(t, err)
+	True(t, Exactly(t, a, i))
+	i, err = o.Get(1)
+	Nil(t, err)
+	True(t, Exactly(t, b, i))
+	i, err = o.Get(2)
+	Equal(t, err.(*lists.ListError).Type, lists.ListErrorOutOfBound)
+	Nil(t, i)
+
+	Nil(t, a.Permutation(2))
+	Equal(t, "0", o.String())
+
+	o2 := o.Clone()
+	Equal(t, o.String(), o2.String())
+}
+
+func TestAndArithmetic(t *testing.T) {
+	a := primitives.NewRangeInt(6, 10)
+	b := primitives.NewConstantInt(3)
+
+	o := NewAndArithmetic(a, b)
+	Equal(t, "2", o.String())
+	Equal(t, 1, o.Permutations())
+	Equal(t, 5, o.PermutationsAll())
+
+	i, err := o.Get(0)
+	Nil(t, err)
+	True(t, Exactly(t, a, i))
+	i, err = o.Get(1)
+	Nil(t, err)
+	True(t, Exactly(t, b, i))
+	i, err = o.Get(2)
+	Equal(t, err.(*lists.ListError).Type, lists.ListErrorOutOfBound)
+	Nil(t, i)
+
+	Nil(t, a.Permutation(2))
+	Equal(t, "0", o.String())
+
+	o2 := o.Clone()
+	Equal(t, o.String(), o2.String())
+}
+
+func TestOrArithmetic(t *testing.T) {
+	a := primitives.NewRangeInt(6, 10)
+	b := primitives.NewConstantInt(3)
+
+	o := NewOrArithmetic(a, b)
+	Equal(t, "7", o.String())
+	Equal(t, 1, o.Permutations())
+	Equal(t, 5, o.PermutationsAll())
+
+	i, err := o.Get(0)
+	Nil(t, err)
+	True(t, Exactly(t, a, i))
+	i, err = o.Get(1)
+	Nil(t, err)
+	True(t, Exactly(t, b, i))
+	i, err = o.Get(2)
+	Equal(t, err.(*lists.ListError).Type, lists.ListErrorOutOfBound)
+	Nil(t, i)
+
+	Nil(t, a.Permutation(2))
+	Equal(t, "11", o.String())
+
+	o2 := o.Clone()
+	Equal(t, o.String(), o2.String())
+}
+
+func TestXorArithmetic(t *testing.T) {
+	a := primitives.NewRangeInt(6, 10)
+	b := primitives.NewConstantInt(3)
+
+	o := NewXorArithmetic(a, b)
+	Equal(t, "5", o.String())
+	Equal(t, 1, o.Permutations())
+	Equal(t, 5, o.PermutationsAll())
+
+	i, err := o.Get(0)
+	Nil(t, err)
+	True(t, Exactly(t, a, i))
+	i, err = o.Get(1)
+	Nil(t, err)
+	True(t, Exactly(t, b, i))
+	i, err = o.Get(2)
+	Equal(t, err.(*lists.ListError).Type, lists.ListErrorOutOfBound)
+	Nil(t, i)
+
+	Nil(t, a.Permutation(2))
+	Equal(t, "11", o.String())
+
+	o2 := o.Clone()
+	Equal(t, o.String(), o2.String())
+}
+
+func TestShiftLeftArithmetic(t *testing.T) {
+	a := primitives.NewRangeInt(6, 10)
+	b := primitives.NewConstantInt(2)
+
+	o := NewShiftLeftArithmetic(a, b)
+	Equal(t, "24", o.String())
+	Equal(t, 1, o.Permutations())
+	Equal(t, 5, o.PermutationsAll())
+
+	i, err := o.Get(0)
+	Nil(t, err)
+	True(t, Exactly(t, a, i))
+	i, err = o.Get(1)
+	Nil(t, err)
+	True(t, Exactly(t, b, i))
+	i, err = o.Get(2)
+	Equal(t, err.(*lists.ListError).Type, lists.ListErrorOutOfBound)
+	Nil(t, i)
+
+	Nil(t, a.Permutation(2))
+	Equal(t, "32", o.String())
+
+	o2 := o.Clone()
+	Equal(t, o.String(), o2.String())
+}
+
+func TestShiftRightArithmetic(t *testing.T) {
+	a := primitives.NewRangeInt(6, 10)
+	b := primitives.NewConstantInt(1)
+
+	o := NewShiftRightArithmetic(a, b)
+	Equal(t, "3", o.String())
+	Equal(t, 1, o.Permutations())
+	Equal(t, 5, o.PermutationsAll())
+
+	i, err := o.Get(0)
+	Nil(t, err)
+	True(t, Exactly(t, a, i))
+	i, err = o.Get(1)
+	Nil(t, err)
+	True(t, Exactly(t, b, i))
+	i, err = o.Get(2)
+	Equal(t, err.(*lists.ListError).Type, lists.ListErrorOutOfBound)
+	Nil(t, i)
+
+	Nil(t, a.Permutation(2))
+	Equal(t, "4", o.String())
+
+	o2 := o.Clone()
+	Equal(t, o.String(), o2.String())
+}
@@ -0,0 +1,314 @@
+package expressions
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/adler32"
+	"hash/crc32"
+	"sort"
+	"strconv"
+
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+type computeFunction struct {
+	// integer computes an integer value which is encoded as decimal text or as binary integer
+	integer func(data []byte) uint64
+	// digest computes a digest which is encoded as hexadecimal text or as raw bytes
+	digest func(data []byte) []byte
+	// digestSize is the size of the digest in bytes
+	digestSize int
+}
+
+var computeFunctions = map[string]computeFunction{
+	"len": {
+		integer: func(data []byte) uint64 {
+			return uint64(len(data))
+		},
+	},
+	"crc32": {
+		integer: func(data []byte) uint64 {
+			return uint64(crc32.ChecksumIEEE(data))
+		},
+	},
+	"adler32": {
+		integer: func(data []byte) uint64 {
+			return uint64(adler32.Checksum(data))
+		},
+	},
+	"md5": {
+		digest: func(data []byte) []byte {
+			s := md5.Sum(data)
+
+			return s[:]
+		},
+		digestSize: md5.Size,
+	},
+	"sha1": {
+		digest: func(data []byte) []byte {
+			s := sha1.Sum(data)
+
+			return s[:]
+		},
+		digestSize: sha1.Size,
+	},
+	"sha256": {
+		digest: func(data []byte) []byte {
+			s := sha256.Sum256(data)
+
+			return s[:]
+		},
+		digestSize: sha256.Size,
+	},
+}
+
+// ComputeFunctions returns a list of all function names which can be used with a ComputedValue token.
+func ComputeFunctions() []string {
+	names := make([]string, 0, len(computeFunctions))
+
+	for name := range computeFunctions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// ComputedValue implements an expression token which computes its value from the current output of a referenced token
+// Length and integer checksum functions are encoded by default as decimal text, or as raw bytes if one of the binary integer types like u16be is given as encoding. Digest functions are encoded by default as lowercase hexadecimal text, or as raw bytes if the encoding "raw" is given. The value is computed on output and therefore always reflects the current permutation of the referenced token. If corruption is enabled the token has a second permutation which holds a wrong value.
+type ComputedValue struct {
+	function string
+	compute  computeFunction
+	encoding string
+	binary   *primitives.BinaryInt
+	token    token.Token
+
+	corruptible bool
+	corrupted   bool
+
+	parsed       []byte
+	parsedParser *token.InternalParser
+	parsedCur    int
+}
+
+// NewComputedValue returns a new instance of a ComputedValue token given the function name, the encoding and the referenced token.
+// The error return argument is not nil if the function or encoding is unknown.
+func NewComputedValue(function string, encoding string, tok token.Token) (*ComputedValue, error) {
+	compute, ok := computeFunctions[function]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", function)
+	}
+
+	e := &ComputedValue{
+		function: function,
+		compute:  compute,
+		encoding: encoding,
+		token:    tok,
+	}
+
+	if compute.integer != nil {
+		if encoding != "" {
+			b, ok := primitives.NewBinaryIntType(encoding)
+			if !ok {
+				return nil, fmt.Errorf("unknown encoding %q for function %q", encoding, function)
+			}
+
+			e.binary = b
+		}
+	} else if encoding != "" && encoding != "raw" {
+		return nil, fmt.Errorf("unknown encoding %q for function %q", encoding, function)
+	}
+
+	return e, nil
+}
+
+// Function returns the name of the compute function
+func (e *ComputedValue) Function() string {
+	return e.function
+}
+
+// Encoding returns the encoding of the computed value
+func (e *ComputedValue) Encoding() string {
+	return e.encoding
+}
+
+// Corruptible returns if the token can hold a wrong value
+func (e *ComputedValue) Corruptible() bool {
+	return e.corruptible
+}
+
+// SetCorruptible enables or disables that the token can hold a wrong value
+func (e *ComputedValue) SetCorruptible(corruptible bool) {
+	e.corruptible = corruptible
+
+	if !corruptible {
+		e.corrupted = false
+	}
+}
+
+func (e *ComputedValue) value(corrupted bool) []byte {
+	data := []byte(e.token.String())
+
+	if e.compute.integer != nil {
+		v := e.compute.integer(data)
+		if corrupted {
+			v++
+		}
+
+		if e.binary == nil {
+			return []byte(strconv.FormatUint(v, 10))
+		}
+
+		return e.binary.Encode(v)
+	}
+
+	d := e.compute.digest(data)
+	if corrupted {
+		d[0] ^= 0xFF
+	}
+
+	if e.encoding == "raw" {
+		return d
+	}
+
+	return []byte(hex.EncodeToString(d))
+}
+
+// Clone returns a copy of the token and all its children
+func (e *ComputedValue) Clone() token.Token {
+	return &ComputedValue{
+		function: e.function,
+		compute:  e.compute,
+		encoding: e.encoding,
+		binary:   e.binary,
+		token:    e.token,
+
+		corruptible: e.corruptible,
+		corrupted:   e.corrupted,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+// Since the referenced token is not necessarily parsed at this point, only the form of the value is checked. The value itself is checked by Verify.
+func (e *ComputedValue) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	var nextIndex int
+
+	switch {
+	case e.binary != nil:
+		i, errs := e.binary.Clone().Parse(pars, cur)
+		if len(errs) > 0 {
+			return cur, errs
+		}
+
+		nextIndex = i
+	case e.compute.integer != nil:
+		nextIndex = cur
+		for nextIndex < pars.DataLen && pars.Data[nextIndex] >= '0' && pars.Data[nextIndex] <= '9' {
+			nextIndex++
+		}
+
+		if nextIndex == cur {
+			return cur, []error{e.parseError(pars, cur, "decimal integer")}
+		}
+	default:
+		size := e.compute.digestSize
+		if e.encoding != "raw" {
+			size *= 2
+		}
+
+		nextIndex = cur + size
+
+		if nextIndex > pars.DataLen {
+			return cur, []error{&token.ParserError{
+				Message: fmt.Sprintf("expected %s value but got early EOF", e.function),
+				Type:    token.ParseErrorUnexpectedEOF,
+
+				Position: pars.GetPosition(cur),
+			}}
+		}
+
+		if e.encoding != "raw" {
+			if _, err := hex.DecodeString(string(pars.Data[cur:nextIndex])); err != nil {
+				return cur, []error{e.parseError(pars, cur, "hexadecimal digest")}
+			}
+		}
+	}
+
+	e.parsed = pars.Data[cur:nextIndex]
+	e.parsedParser = pars
+	e.parsedCur = cur
+
+	log.Debugf("Parsed %q", e.parsed)
+
+	return nextIndex, nil
+}
+
+func (e *ComputedValue) parseError(pars *token.InternalParser, cur int, expected string) error {
+	return &token.ParserError{
+		Message: fmt.Sprintf("expected %s value as %s", e.function, expected),
+		Type:    token.ParseErrorUnexpectedData,
+
+		Position: pars.GetPosition(cur),
+	}
+}
+
+// Permutation sets a specific permutation for this token
+func (e *ComputedValue) Permutation(i uint) error {
+	permutations := e.Permutations()
+
+	if i < 0 || i >= permutations {
+		return &token.PermutationError{
+			Type: token.PermutationErrorIndexOutOfBound,
+		}
+	}
+
+	e.corrupted = i == 1
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (e *ComputedValue) Permutations() uint {
+	if e.corruptible {
+		return 2
+	}
+
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (e *ComputedValue) PermutationsAll() uint {
+	return e.Permutations()
+}
+
+func (e *ComputedValue) String() string {
+	return string(e.value(e.corrupted))
+}
+
+// Verify interface methods
+
+// Verify checks if the parsed value is the correct value for the current state of the referenced token
+func (e *ComputedValue) Verify() error {
+	if e.parsedParser == nil {
+		return nil
+	}
+
+	if expected := e.value(false); !bytes.Equal(e.parsed, expected) {
+		return &token.ParserError{
+			Message: fmt.Sprintf("expected %s value %q but got %q", e.function, expected, e.parsed),
+			Type:    token.ParseErrorUnexpectedData,
+
+			Position: e.parsedParser.GetPosition(e.parsedCur),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package expressions
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/lists"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func TestComputedValueTokensToBeTokens(t *testing.T) {
+	var tok *token.Token
+
+	Implements(t, tok, &ComputedValue{})
+}
+
+func TestComputedValue(t *testing.T) {
+	payload := lists.NewOne(
+		primitives.NewConstantString("abc"),
+		primitives.NewConstantString("hello"),
+	)
+
+	o, err := NewComputedValue("len", "", payload)
+	Nil(t, err)
+	Equal(t, "3", o.String())
+	Equal(t, 1, o.Permutations())
+
+	Nil(t, payload.Permutation(1))
+	Equal(t, "5", o.String())
+
+	o, err = NewComputedValue("len", "u16be", payload)
+	Nil(t, err)
+	Equal(t, "\x00\x05", o.String())
+
+	o, err = NewComputedValue("crc32", "", payload)
+	Nil(t, err)
+	Equal(t, "907060870", o.String())
+
+	o, err = NewComputedValue("crc32", "u32le", payload)
+	Nil(t, err)
+	Equal(t, "\x86\xa6\x10\x36", o.String())
+
+	o, err = NewComputedValue("adler32", "", payload)
+	Nil(t, err)
+	Equal(t, "103547413", o.String())
+
+	o, err = NewComputedValue("md5", "", payload)
+	Nil(t, err)
+	Equal(t, "5d41402abc4b2a76b9719d911017c592", o.String())
+
+	o, err = NewComputedValue("md5", "raw", payload)
+	Nil(t, err)
+	Equal(t, 16, len(o.String()))
+
+	o, err = NewComputedValue("sha1", "", payload)
+	Nil(t, err)
+	Equal(t, "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d", o.String())
+
+	o, err = NewComputedValue("sha256", "", payload)
+	Nil(t, err)
+	Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", o.String())
+
+	// corruption
+	o, err = NewComputedValue("len", "", payload)
+	Nil(t, err)
+	o.SetCorruptible(true)
+	Equal(t, 2, o.Permutations())
+
+	Nil(t, o.Permutation(1))
+	Equal(t, "6", o.String())
+	Nil(t, o.Permutation(0))
+	Equal(t, "5", o.String())
+
+	o2 := o.Clone()
+	Equal(t, o.String(), o2.String())
+	Equal(t, 2, o2.Permutations())
+
+	// errors
+	_, err = NewComputedValue("unknown", "", payload)
+	NotNil(t, err)
+
+	_, err = NewComputedValue("len", "raw", payload)
+	NotNil(t, err)
+
+	_, err = NewComputedValue("md5", "u8", payload)
+	NotNil(t, err)
+}
+
+func TestComputedValueVerify(t *testing.T) {
+	payload := primitives.NewConstantString("hello")
+
+	o, err := NewComputedValue("len", "", payload)
+	Nil(t, err)
+
+	// nothing parsed yet
+	Nil(t, o.Verify())
+
+	i, errs := o.Parse(&token.InternalParser{Data: []byte("5hello"), DataLen: 6}, 0)
+	Nil(t, errs)
+	Equal(t, 1, i)
+	Nil(t, o.Verify())
+
+	i, errs = o.Parse(&token.InternalParser{Data: []byte("6hello"), DataLen: 6}, 0)
+	Nil(t, errs)
+	Equal(t, 1, i)
+	Equal(t, token.ParseErrorUnexpectedData, o.Verify().(*token.ParserError).Type)
+
+	_, errs = o.Parse(&token.InternalParser{Data: []byte("hello"), DataLen: 5}, 0)
+	Equal(t, token.ParseErrorUnexpectedData, errs[0].(*token.ParserError).Type)
+
+	o, err = NewComputedValue("md5", "", payload)
+	Nil(t, err)
+
+	_, errs = o.Parse(&token.InternalParser{Data: []byte("5d41"), DataLen: 4}, 0)
+	Equal(t, token.ParseErrorUnexpectedEOF, errs[0].(*token.ParserError).Type)
+}
@@ -0,0 +1,142 @@
+package filters
+
+import (
+	"fmt"
+
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/token"
+)
+
+// Transform implements a filter token which encodes the output of its referenced token e.g. as base64 or gzip
+// Parsing decodes the data before it is handed to the referenced token, which makes it possible to validate and reduce encoded data.
+type Transform struct {
+	function  string
+	transform transformFunction
+	token     token.Token
+}
+
+// NewTransform returns a new instance of a Transform token given the function name and the referenced token.
+// The error return argument is not nil if the function is unknown.
+func NewTransform(function string, tok token.Token) (*Transform, error) {
+	transform, ok := transformFunctions[function]
+	if !ok {
+		return nil, fmt.Errorf("unknown transform function %q", function)
+	}
+
+	return &Transform{
+		function:  function,
+		transform: transform,
+		token:     tok,
+	}, nil
+}
+
+// Function returns the name of the transform function
+func (f *Transform) Function() string {
+	return f.function
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (f *Transform) Clone() token.Token {
+	return &Transform{
+		function:  f.function,
+		transform: f.transform,
+		token:     f.token.Clone(),
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+// Errors of the referenced token hold positions of the decoded data.
+func (f *Transform) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	text, offsets := f.transform.decode(pars.Data[cur:pars.DataLen])
+
+	i, errs := f.token.Parse(&token.InternalParser{
+		Data:    text,
+		DataLen: len(text),
+	}, 0)
+	if len(errs) > 0 {
+		if len(text) == 0 {
+			return cur, []error{f.parseError(pars, cur, fmt.Sprintf("expected %s data", f.function))}
+		}
+
+		return cur, errs
+	}
+
+	if offsets[i] < 0 {
+		return cur, []error{f.parseError(pars, cur, fmt.Sprintf("expected %s data to end after %q", f.function, text[:i]))}
+	}
+
+	log.Debugf("Parsed %q as %s", text[:i], f.function)
+
+	return cur + offsets[i], nil
+}
+
+func (f *Transform) parseError(pars *token.InternalParser, cur int, message string) error {
+	return &token.ParserError{
+		Message: message,
+		Type:    token.ParseErrorUnexpectedData,
+
+		Position: pars.GetPosition(cur),
+	}
+}
+
+// Permutation sets a specific permutation for this token
+func (f *Transform) Permutation(i uint) error {
+	permutations := f.Permutations()
+
+	if i < 0 || i >= permutations {
+		return &token.PermutationError{
+			Type: token.PermutationErrorIndexOutOfBound,
+		}
+	}
+
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (f *Transform) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (f *Transform) PermutationsAll() uint {
+	return f.token.PermutationsAll()
+}
+
+func (f *Transform) String() string {
+	return string(f.transform.encode([]byte(f.token.String())))
+}
+
+// ForwardToken interface methods
+
+// Get returns the current referenced token
+func (f *Transform) Get() token.Token {
+	return f.token
+}
+
+// InternalGet returns the current referenced internal token
+func (f *Transform) InternalGet() token.Token {
+	return f.token
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (f *Transform) InternalLogicalRemove(tok token.Token) token.Token {
+	if f.token == tok {
+		return nil
+	}
+
+	return f
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (f *Transform) InternalReplace(oldToken, newToken token.Token) error {
+	if f.token == oldToken {
+		f.token = newToken
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package filters
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/lists"
+	"github.com/zimmski/tavor/token/primitives"
+)
+
+func TestTransformTokensToBeTokens(t *testing.T) {
+	var tok *token.Token
+
+	Implements(t, tok, &Transform{})
+
+	var forward *token.ForwardToken
+
+	Implements(t, forward, &Transform{})
+}
+
+func parseTransform(tok token.Token, data string) (int, []error) {
+	return tok.Parse(&token.InternalParser{
+		Data:    []byte(data),
+		DataLen: len(data),
+	}, 0)
+}
+
+func TestTransform(t *testing.T) {
+	_, err := NewTransform("rot13", primitives.NewConstantString("a"))
+	NotNil(t, err)
+
+	Equal(t, []string{"base64", "gzip", "hex", "jsonescape", "urlencode", "xmlescape"}, TransformFunctions())
+
+	o := lists.NewOne(primitives.NewConstantString("hi"), primitives.NewConstantString("hello!"))
+
+	f, err := NewTransform("base64", o)
+	Nil(t, err)
+	Equal(t, "base64", f.Function())
+	Equal(t, 1, f.Permutations())
+	Equal(t, 2, f.PermutationsAll())
+
+	Equal(t, "aGk=", f.String())
+
+	Nil(t, o.Permutation(1))
+	Equal(t, "aGVsbG8h", f.String())
+
+	f2 := f.Clone().(*Transform)
+	Nil(t, f2.Get().Permutation(0))
+	Equal(t, "aGk=", f2.String())
+	Equal(t, "aGVsbG8h", f.String())
+
+	Equal(t, f.Permutation(1).(*token.PermutationError).Type, token.PermutationErrorIndexOutOfBound)
+}
+
+func TestTransformFunctions(t *testing.T) {
+	for _, tc := range []struct {
+		function string
+		text     string
+		encoded  string
+	}{
+		{"base64", "hello!", "aGVsbG8h"},
+		{"base64", "hi", "aGk="},
+		{"hex", "hi\xff", "6869ff"},
+		{"jsonescape", "a\"b\\c\n\x01é", `a\"b\\c\n\u0001é`},
+		{"urlencode", "a b&c/ü", "a+b%26c%2F%C3%BC"},
+		{"xmlescape", `<a href="x">&'`, "&lt;a href=&quot;x&quot;&gt;&amp;&apos;"},
+	} {
+		f, err := NewTransform(tc.function, primitives.NewConstantString(tc.text))
+		Nil(t, err)
+
+		Equal(t, tc.encoded, f.String(), tc.function)
+
+		// the encoded data is followed by the data of other tokens
+		i, errs := parseTransform(f, tc.encoded+"\"<!")
+		Nil(t, errs, tc.function)
+		Equal(t, len(tc.encoded), i, tc.function)
+	}
+
+	// gzip output can only be checked by decompressing it
+	f, err := NewTransform("gzip", primitives.NewConstantString("hello hello hello"))
+	Nil(t, err)
+
+	encoded := f.String()
+	NotEqual(t, "hello hello hello", encoded)
+
+	i, errs := parseTransform(f, encoded+"trailer")
+	Nil(t, errs)
+	Equal(t, len(encoded), i)
+}
+
+func TestTransformDecoding(t *testing.T) {
+	for _, tc := range []struct {
+		function string
+		encoded  string
+		text     string
+	}{
+		{"hex", "6869FF", "hi\xff"},
+		{"jsonescape", `\/\té😀`, "/\té😀"},
+		{"urlencode", "%41b~", "Ab~"},
+		{"xmlescape", "&#65;&#x42;&quot;", `AB"`},
+	} {
+		f, err := NewTransform(tc.function, primitives.NewConstantString(tc.text))
+		Nil(t, err)
+
+		i, errs := parseTransform(f, tc.encoded)
+		Nil(t, errs, tc.function)
+		Equal(t, len(tc.encoded), i, tc.function)
+	}
+}
+
+func TestTransformParseErrors(t *testing.T) {
+	f, err := NewTransform("base64", primitives.NewConstantString("hello!"))
+	Nil(t, err)
+
+	// not encoded at all
+	i, errs := parseTransform(f, "hello!")
+	NotNil(t, errs)
+	Equal(t, 0, i)
+
+	// nothing can be decoded
+	i, errs = parseTransform(f, "!!!!")
+	NotNil(t, errs)
+	Equal(t, 0, i)
+	Equal(t, token.ParseErrorUnexpectedData, errs[0].(*token.ParserError).Type)
+
+	// the token ends within an encoded block
+	f, err = NewTransform("base64", primitives.NewConstantString("he"))
+	Nil(t, err)
+
+	i, errs = parseTransform(f, "aGVsbG8h")
+	NotNil(t, errs)
+	Equal(t, 0, i)
+	Equal(t, token.ParseErrorUnexpectedData, errs[0].(*token.ParserError).Type)
+
+	// compressed data has to be parsed as a whole
+	f, err = NewTransform("gzip", primitives.NewConstantString("hello"))
+	Nil(t, err)
+
+	g, err := NewTransform("gzip", primitives.NewConstantString("hello!"))
+	Nil(t, err)
+
+	i, errs = parseTransform(f, g.String())
+	NotNil(t, errs)
+	Equal(t, 0, i)
+
+	i, errs = parseTransform(f, "hello")
+	NotNil(t, errs)
+	Equal(t, 0, i)
+}
@@ -0,0 +1,430 @@
+package filters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// transformFunction defines how the output of a token is encoded and how encoded data is decoded again
+type transformFunction struct {
+	// encode encodes the output of a token
+	encode func(data []byte) []byte
+	// decode decodes the longest prefix of the data which is valid for the transform. It returns the decoded text and for every byte of the text the offset of its encoded form in the data followed by the offset after the decoded prefix. Bytes of the text which do not begin an encoded block, and can therefore not be the end of a token, have the offset -1.
+	decode func(data []byte) ([]byte, []int)
+}
+
+var transformFunctions = map[string]transformFunction{
+	"base64": {
+		encode: func(data []byte) []byte {
+			return []byte(base64.StdEncoding.EncodeToString(data))
+		},
+		decode: decodeBase64,
+	},
+	"gzip": {
+		encode: func(data []byte) []byte {
+			var buf bytes.Buffer
+
+			w := gzip.NewWriter(&buf)
+			_, _ = w.Write(data)
+			_ = w.Close()
+
+			return buf.Bytes()
+		},
+		decode: decodeGzip,
+	},
+	"hex": {
+		encode: func(data []byte) []byte {
+			return []byte(hex.EncodeToString(data))
+		},
+		decode: decodeHex,
+	},
+	"jsonescape": {
+		encode: encodeJSONEscape,
+		decode: decodeJSONEscape,
+	},
+	"urlencode": {
+		encode: func(data []byte) []byte {
+			return []byte(url.QueryEscape(string(data)))
+		},
+		decode: decodeURLEncode,
+	},
+	"xmlescape": {
+		encode: encodeXMLEscape,
+		decode: decodeXMLEscape,
+	},
+}
+
+// TransformFunctions returns a list of all function names which can be used with a Transform token.
+func TransformFunctions() []string {
+	names := make([]string, 0, len(transformFunctions))
+
+	for name := range transformFunctions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func isBase64Character(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '+' || c == '/' || c == '='
+}
+
+func decodeBase64(data []byte) ([]byte, []int) {
+	var text []byte
+	var offsets []int
+
+	i := 0
+
+	for i+4 <= len(data) {
+		block := data[i : i+4]
+
+		if !isBase64Character(block[0]) || !isBase64Character(block[1]) || !isBase64Character(block[2]) || !isBase64Character(block[3]) {
+			break
+		}
+
+		var decoded [3]byte
+
+		n, err := base64.StdEncoding.Decode(decoded[:], block)
+		if err != nil {
+			break
+		}
+
+		for j := 0; j < n; j++ {
+			if j == 0 {
+				offsets = append(offsets, i)
+			} else {
+				offsets = append(offsets, -1)
+			}
+		}
+		text = append(text, decoded[:n]...)
+
+		i += 4
+
+		// padding ends the encoded data
+		if n < 3 {
+			break
+		}
+	}
+
+	return text, append(offsets, i)
+}
+
+func decodeGzip(data []byte) ([]byte, []int) {
+	// a bytes.Reader is read byte by byte by the decompressor which makes it possible to determine the end of the compressed data
+	r := bytes.NewReader(data)
+
+	z, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, []int{0}
+	}
+	z.Multistream(false)
+
+	text, err := ioutil.ReadAll(z)
+	if err != nil {
+		return nil, []int{0}
+	}
+
+	// the compressed data can only be parsed as a whole
+	offsets := make([]int, len(text)+1)
+	for i := range text {
+		offsets[i] = -1
+	}
+	if len(text) > 0 {
+		offsets[0] = 0
+	}
+	offsets[len(text)] = len(data) - r.Len()
+
+	return text, offsets
+}
+
+func decodeHex(data []byte) ([]byte, []int) {
+	var text []byte
+	var offsets []int
+
+	i := 0
+
+	for ; i+2 <= len(data); i += 2 {
+		var decoded [1]byte
+
+		if _, err := hex.Decode(decoded[:], data[i:i+2]); err != nil {
+			break
+		}
+
+		offsets = append(offsets, i)
+		text = append(text, decoded[0])
+	}
+
+	return text, append(offsets, i)
+}
+
+func encodeJSONEscape(data []byte) []byte {
+	var buf bytes.Buffer
+
+	for _, c := range data {
+		switch c {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				fmt.Fprintf(&buf, `\u%04x`, c)
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+
+	return buf.Bytes()
+}
+
+var jsonSimpleEscapes = map[byte]byte{
+	'"':  '"',
+	'\\': '\\',
+	'/':  '/',
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+}
+
+func decodeJSONUnicodeEscape(data []byte) (rune, bool) {
+	if len(data) < 6 || data[0] != '\\' || data[1] != 'u' {
+		return 0, false
+	}
+
+	v, err := strconv.ParseUint(string(data[2:6]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+
+	return rune(v), true
+}
+
+func decodeJSONEscape(data []byte) ([]byte, []int) {
+	var text []byte
+	var offsets []int
+
+	var buf [utf8.UTFMax]byte
+
+	i := 0
+
+DECODE:
+	for i < len(data) {
+		c := data[i]
+		var decoded []byte
+		size := 1
+
+		switch {
+		case c == '"' || c < 0x20:
+			break DECODE
+		case c != '\\':
+			decoded = data[i : i+1]
+		case i+1 < len(data) && data[i+1] == 'u':
+			r, ok := decodeJSONUnicodeEscape(data[i:])
+			if !ok {
+				break DECODE
+			}
+			size = 6
+
+			if utf16.IsSurrogate(r) {
+				r2, ok := decodeJSONUnicodeEscape(data[i+6:])
+				if r = utf16.DecodeRune(r, r2); ok && r != utf8.RuneError {
+					size = 12
+				}
+			}
+
+			decoded = buf[:utf8.EncodeRune(buf[:], r)]
+		case i+1 < len(data):
+			e, ok := jsonSimpleEscapes[data[i+1]]
+			if !ok {
+				break DECODE
+			}
+			size = 2
+
+			decoded = []byte{e}
+		default:
+			break DECODE
+		}
+
+		for j := range decoded {
+			if j == 0 {
+				offsets = append(offsets, i)
+			} else {
+				offsets = append(offsets, -1)
+			}
+		}
+		text = append(text, decoded...)
+
+		i += size
+	}
+
+	return text, append(offsets, i)
+}
+
+func isURLUnreserved(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~'
+}
+
+func decodeURLEncode(data []byte) ([]byte, []int) {
+	var text []byte
+	var offsets []int
+
+	i := 0
+
+	for i < len(data) {
+		c := data[i]
+		size := 1
+
+		switch {
+		case isURLUnreserved(c):
+		case c == '+':
+			c = ' '
+		case c == '%' && i+3 <= len(data):
+			var decoded [1]byte
+
+			if _, err := hex.Decode(decoded[:], data[i+1:i+3]); err != nil {
+				return text, append(offsets, i)
+			}
+
+			c = decoded[0]
+			size = 3
+		default:
+			return text, append(offsets, i)
+		}
+
+		offsets = append(offsets, i)
+		text = append(text, c)
+
+		i += size
+	}
+
+	return text, append(offsets, i)
+}
+
+var xmlEscapes = map[byte]string{
+	'&':  "&amp;",
+	'<':  "&lt;",
+	'>':  "&gt;",
+	'"':  "&quot;",
+	'\'': "&apos;",
+}
+
+var xmlEntities = map[string]rune{
+	"amp":  '&',
+	"lt":   '<',
+	"gt":   '>',
+	"quot": '"',
+	"apos": '\'',
+}
+
+func encodeXMLEscape(data []byte) []byte {
+	var buf bytes.Buffer
+
+	for _, c := range data {
+		if e, ok := xmlEscapes[c]; ok {
+			buf.WriteString(e)
+		} else {
+			buf.WriteByte(c)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// decodeXMLEntity decodes a named or numeric character reference like &amp; or &#x26; at the beginning of the data
+func decodeXMLEntity(data []byte) (rune, int, bool) {
+	// the longest reference is a hexadecimal reference of the highest code point like &#x10FFFF;
+	if len(data) > 10 {
+		data = data[:10]
+	}
+
+	end := bytes.IndexByte(data, ';')
+	if end < 2 {
+		return 0, 0, false
+	}
+
+	name := string(data[1:end])
+
+	if name[0] != '#' {
+		r, ok := xmlEntities[name]
+
+		return r, end + 1, ok
+	}
+
+	var v uint64
+	var err error
+
+	if len(name) > 2 && (name[1] == 'x' || name[1] == 'X') {
+		v, err = strconv.ParseUint(name[2:], 16, 32)
+	} else {
+		v, err = strconv.ParseUint(name[1:], 10, 32)
+	}
+	if err != nil || !utf8.ValidRune(rune(v)) {
+		return 0, 0, false
+	}
+
+	return rune(v), end + 1, true
+}
+
+func decodeXMLEscape(data []byte) ([]byte, []int) {
+	var text []byte
+	var offsets []int
+
+	var buf [utf8.UTFMax]byte
+
+	i := 0
+
+	for i < len(data) {
+		c := data[i]
+		decoded := data[i : i+1]
+		size := 1
+
+		if c == '&' {
+			r, n, ok := decodeXMLEntity(data[i:])
+			if !ok {
+				break
+			}
+
+			decoded = buf[:utf8.EncodeRune(buf[:], r)]
+			size = n
+		} else if _, ok := xmlEscapes[c]; ok {
+			break
+		}
+
+		for j := range decoded {
+			if j == 0 {
+				offsets = append(offsets, i)
+			} else {
+				offsets = append(offsets, -1)
+			}
+		}
+		text = append(text, decoded...)
+
+		i += size
+	}
+
+	return text, append(offsets, i)
+}
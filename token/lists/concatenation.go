@@ -78,7 +78,7 @@ func (l *Concatenation) PermutationsAll() uint {
 	sum := l.Permutations()
 
 	for _, tok := range l.tokens {
-		sum *= tok.PermutationsAll()
+		sum = token.MulPermutations(sum, tok.PermutationsAll())
 	}
 
 	return sum
@@ -6,6 +6,8 @@ type ListErrorType int
 const (
 	// ListErrorOutOfBound an index not in the bound of available list items was used.
 	ListErrorOutOfBound ListErrorType = iota
+	// ListErrorOutOfRepeatRange the operation would leave the repeat range of the list.
+	ListErrorOutOfRepeatRange
 )
 
 // ListError holds a list error
@@ -15,6 +17,8 @@ type ListError struct {
 
 func (err *ListError) Error() string {
 	switch err.Type {
+	case ListErrorOutOfRepeatRange:
+		return "Out of repeat range"
 	default:
 		return "Out of bound"
 	}
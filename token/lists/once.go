@@ -2,18 +2,26 @@ package lists
 
 import (
 	"bytes"
+	"fmt"
 
 	"github.com/zimmski/tavor/token"
 )
 
 // Once implements a list token which holds a set of tokens that get shuffled on every permutation
 type Once struct {
-	tokens []token.Token
-	values []int
+	tokens    []token.Token
+	values    []int
+	separator string
 }
 
 // NewOnce returns a new instance of a Once token given the set of tokens
 func NewOnce(toks ...token.Token) *Once {
+	return NewOnceWithSeparator("", toks...)
+}
+
+// NewOnceWithSeparator returns a new instance of a Once token given the set of tokens which are separated by the given separator.
+// Tokens which do not generate data, e.g. deactivated optional tokens, are not separated.
+func NewOnceWithSeparator(separator string, toks ...token.Token) *Once {
 	if len(toks) == 0 {
 		panic("at least one token needed")
 	}
@@ -24,8 +32,9 @@ func NewOnce(toks ...token.Token) *Once {
 	}
 
 	return &Once{
-		tokens: toks,
-		values: values,
+		tokens:    toks,
+		values:    values,
+		separator: separator,
 	}
 }
 
@@ -34,8 +43,9 @@ func NewOnce(toks ...token.Token) *Once {
 // Clone returns a copy of the token and all its children
 func (l *Once) Clone() token.Token {
 	c := Once{
-		tokens: make([]token.Token, len(l.tokens)),
-		values: make([]int, len(l.values)),
+		tokens:    make([]token.Token, len(l.tokens)),
+		values:    make([]int, len(l.values)),
+		separator: l.separator,
 	}
 
 	for i, tok := range l.tokens {
@@ -51,8 +61,81 @@ func (l *Once) Clone() token.Token {
 
 // Parse tries to parse the token beginning from the current position in the parser data.
 // If the parsing is successful the error argument is nil and the next current position after the token is returned.
+// Tokens which consume data are preferred over tokens which parse without consuming data e.g. deactivated optional tokens.
 func (l *Once) Parse(pars *token.InternalParser, cur int) (int, []error) {
-	panic("TODO implement")
+	rest := make([]int, len(l.tokens))
+	for i := range rest {
+		rest[i] = i
+	}
+	values := make([]int, 0, len(l.tokens))
+
+	// the separator is needed in front of every token which consumes data after the first one
+	separated := false
+
+	for len(rest) > 0 {
+		var errs []error
+
+		found := -1
+		nex := cur
+
+		start := cur
+		if separated {
+			start += len(l.separator)
+
+			if start > pars.DataLen || string(pars.Data[cur:start]) != l.separator {
+				errs = append(errs, &token.ParserError{
+					Message: fmt.Sprintf("expected separator %q", l.separator),
+					Type:    token.ParseErrorUnexpectedData,
+
+					Position: pars.GetPosition(cur),
+				})
+
+				start = -1
+			}
+		}
+
+		if start != -1 {
+			for i, t := range rest {
+				n, es := l.tokens[t].Parse(pars, start)
+
+				if len(es) == 0 && n > start {
+					found, nex = i, n
+
+					break
+				}
+
+				errs = append(errs, es...)
+			}
+		}
+
+		if found == -1 {
+			// the remaining tokens have to parse without consuming data
+			for i, t := range rest {
+				if n, es := l.tokens[t].Parse(pars, cur); len(es) == 0 && n == cur {
+					found, nex = i, n
+
+					break
+				}
+			}
+
+			if found == -1 {
+				return cur, errs
+			}
+		}
+
+		values = append(values, rest[found])
+		rest = append(rest[:found], rest[found+1:]...)
+
+		if nex > cur && l.separator != "" {
+			separated = true
+		}
+
+		cur = nex
+	}
+
+	l.values = values
+
+	return cur, nil
 }
 
 func (l *Once) permutation(i uint) {
@@ -65,11 +148,11 @@ func (l *Once) permutation(i uint) {
 
 	n := uint(le)
 	for n > 0 {
-		var pers uint = 1
-		for j := uint(2); j <= n; j++ {
-			pers *= j
+		// the permutations of the rest saturate for long lists, every index then selects the first token of the rest
+		var split uint = 1
+		for j := uint(2); j < n; j++ {
+			split = token.MulPermutations(split, j)
 		}
-		split := pers / n
 
 		ti := i / split
 		i = i % split
@@ -103,7 +186,7 @@ func (l *Once) Permutations() uint {
 	var sum uint = 1
 
 	for i := 2; i <= len(l.tokens); i++ {
-		sum *= uint(i)
+		sum = token.MulPermutations(sum, uint(i))
 	}
 
 	return sum
@@ -111,10 +194,14 @@ func (l *Once) Permutations() uint {
 
 // PermutationsAll returns the number of all possible permutations for this token including its children
 func (l *Once) PermutationsAll() uint {
-	sum := l.Permutations()
+	var sum uint = 1
+
+	for i := 2; i <= len(l.tokens); i++ {
+		sum = token.MulPermutations(sum, uint(i))
+	}
 
 	for _, tok := range l.tokens {
-		sum *= tok.PermutationsAll()
+		sum = token.MulPermutations(sum, tok.PermutationsAll())
 	}
 
 	return sum
@@ -124,7 +211,15 @@ func (l *Once) String() string {
 	var buffer bytes.Buffer
 
 	for i := range l.values {
-		if _, err := buffer.WriteString(l.tokens[l.values[i]].String()); err != nil {
+		s := l.tokens[l.values[i]].String()
+
+		if s != "" && buffer.Len() != 0 {
+			if _, err := buffer.WriteString(l.separator); err != nil {
+				panic(err)
+			}
+		}
+
+		if _, err := buffer.WriteString(s); err != nil {
 			panic(err)
 		}
 	}
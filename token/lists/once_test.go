@@ -6,6 +6,7 @@ import (
 	. "github.com/zimmski/tavor/test/assert"
 
 	"github.com/zimmski/tavor/token"
+	"github.com/zimmski/tavor/token/constraints"
 	"github.com/zimmski/tavor/token/primitives"
 )
 
@@ -64,4 +65,78 @@ func TestOnce(t *testing.T) {
 
 	o2 := o.Clone()
 	Equal(t, o.String(), o2.String())
+
+	// more tokens than permutations
+	var toks []token.Token
+	for i := 0; i < 21; i++ {
+		toks = append(toks, primitives.NewConstantInt(i))
+	}
+	o = NewOnce(toks...)
+	Equal(t, token.MaxPermutations, o.Permutations())
+	Equal(t, token.MaxPermutations, o.PermutationsAll())
+
+	Nil(t, o.Permutation(o.Permutations()-1))
+}
+
+func TestOnceWithSeparator(t *testing.T) {
+	a := primitives.NewConstantString("a")
+	b := constraints.NewOptional(primitives.NewConstantString("b"))
+	c := primitives.NewConstantString("c")
+
+	o := NewOnceWithSeparator(",", a, b, c)
+	Equal(t, "a,b,c", o.String())
+
+	Nil(t, o.Permutation(3))
+	Equal(t, "b,c,a", o.String())
+
+	// tokens without data are not separated
+	Nil(t, b.Permutation(0))
+	Equal(t, "c,a", o.String())
+
+	o2 := o.Clone()
+	Equal(t, o.String(), o2.String())
+
+	for _, data := range []string{"a,b,c", "c,a", "b,c,a", "a,c"} {
+		nex, errs := o.Parse(&token.InternalParser{
+			Data:    []byte(data),
+			DataLen: len(data),
+		}, 0)
+		Nil(t, errs, data)
+		Equal(t, len(data), nex, data)
+		Equal(t, data, o.String())
+	}
+
+	for _, data := range []string{"ac", "a,,c", ",a,c", "a,b,"} {
+		nex, errs := o.Parse(&token.InternalParser{
+			Data:    []byte(data),
+			DataLen: len(data),
+		}, 0)
+		True(t, len(errs) != 0 || nex != len(data), data)
+	}
+}
+
+func TestOnceParse(t *testing.T) {
+	o := NewOnce(
+		primitives.NewConstantString("a"),
+		constraints.NewOptional(primitives.NewConstantString("b")),
+		primitives.NewConstantString("c"),
+	)
+
+	for _, data := range []string{"abc", "cba", "ca", "ac", "bca"} {
+		nex, errs := o.Parse(&token.InternalParser{
+			Data:    []byte(data),
+			DataLen: len(data),
+		}, 0)
+		Nil(t, errs, data)
+		Equal(t, len(data), nex, data)
+		Equal(t, data, o.String())
+	}
+
+	for _, data := range []string{"a", "aa", "abb"} {
+		_, errs := o.Parse(&token.InternalParser{
+			Data:    []byte(data),
+			DataLen: len(data),
+		}, 0)
+		NotNil(t, errs, data)
+	}
 }
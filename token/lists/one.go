@@ -1,14 +1,19 @@
 package lists
 
 import (
+	"text/scanner"
+
 	"github.com/zimmski/tavor/token"
 )
 
 // One implements a list token which chooses of a set of referenced token exactly one token
 // Every permutation chooses one token out of the token set.
 type One struct {
-	tokens []token.Token
-	value  int
+	tokens  []token.Token
+	value   int
+	weights []float64
+
+	position scanner.Position
 }
 
 // NewOne returns a new instance of a One token given the set of tokens
@@ -23,13 +28,28 @@ func NewOne(toks ...token.Token) *One {
 	}
 }
 
+// NewOneWithWeights returns a new instance of a One token given the set of tokens and their relative weights
+func NewOneWithWeights(weights []float64, toks ...token.Token) *One {
+	if len(weights) != len(toks) {
+		panic("every token needs a weight")
+	}
+
+	l := NewOne(toks...)
+	l.weights = weights
+
+	return l
+}
+
 // Token interface methods
 
 // Clone returns a copy of the token and all its children
 func (l *One) Clone() token.Token {
 	c := One{
-		tokens: make([]token.Token, len(l.tokens)),
-		value:  l.value,
+		tokens:  make([]token.Token, len(l.tokens)),
+		value:   l.value,
+		weights: l.weights,
+
+		position: l.position,
 	}
 
 	for i, tok := range l.tokens {
@@ -89,7 +109,7 @@ func (l *One) PermutationsAll() uint {
 	var sum uint
 
 	for _, tok := range l.tokens {
-		sum += tok.PermutationsAll()
+		sum = token.AddPermutations(sum, tok.PermutationsAll())
 	}
 
 	return sum
@@ -133,7 +153,8 @@ func (l *One) InternalLen() int {
 func (l *One) InternalLogicalRemove(tok token.Token) token.Token {
 	for i := 0; i < len(l.tokens); i++ {
 		if l.tokens[i] == tok {
-			if l.value == i {
+			// keep the current alternative if a previous one is removed
+			if l.value >= i {
 				l.value--
 			}
 
@@ -143,6 +164,12 @@ func (l *One) InternalLogicalRemove(tok token.Token) token.Token {
 				l.tokens = append(l.tokens[:i], l.tokens[i+1:]...)
 			}
 
+			if l.weights != nil {
+				weights := make([]float64, 0, len(l.weights)-1)
+				weights = append(weights, l.weights[:i]...)
+				l.weights = append(weights, l.weights[i+1:]...)
+			}
+
 			i--
 		}
 	}
@@ -182,3 +209,22 @@ func (l *One) Minimize() token.Token {
 
 	return nil
 }
+
+// Weighted interface methods
+
+// Weights returns the relative weights of the permutations of the token or nil if all permutations have the same weight
+func (l *One) Weights() []float64 {
+	return l.weights
+}
+
+// Positioned interface methods
+
+// Position returns the position of the token in the format source which is not valid if the position is unknown
+func (l *One) Position() scanner.Position {
+	return l.position
+}
+
+// SetPosition sets the position of the token in the format source
+func (l *One) SetPosition(pos scanner.Position) {
+	l.position = pos
+}
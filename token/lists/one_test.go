@@ -54,3 +54,47 @@ func TestOne(t *testing.T) {
 
 	Equal(t, o.Permutation(1).(*token.PermutationError).Type, token.PermutationErrorIndexOutOfBound)
 }
+
+func TestOneInternalLogicalRemove(t *testing.T) {
+	a := primitives.NewConstantString("a")
+	b := primitives.NewConstantString("b")
+	c := primitives.NewConstantString("c")
+
+	o := NewOne(a, b, c)
+	Nil(t, o.Permutation(2))
+	Equal(t, "c", o.String())
+
+	// the current alternative stays selected
+	Equal(t, o, o.InternalLogicalRemove(a))
+	Equal(t, "c", o.String())
+
+	// the previous alternative is selected instead
+	Equal(t, b, o.InternalLogicalRemove(c))
+	Equal(t, "b", o.String())
+
+	Nil(t, o.InternalLogicalRemove(b))
+}
+
+func TestOneWeights(t *testing.T) {
+	a := primitives.NewConstantString("a")
+	b := primitives.NewConstantString("b")
+	c := primitives.NewConstantString("c")
+
+	Nil(t, NewOne(a, b, c).Weights())
+
+	o := NewOneWithWeights([]float64{10, 1, 0}, a, b, c)
+	Equal(t, []float64{10, 1, 0}, o.Weights())
+	Equal(t, 3, o.Permutations())
+
+	o2 := o.Clone().(*One)
+	Equal(t, []float64{10, 1, 0}, o2.Weights())
+
+	// the weight of a removed alternative is removed too
+	Equal(t, o, o.InternalLogicalRemove(b))
+	Equal(t, []float64{10, 0}, o.Weights())
+	Equal(t, []float64{10, 1, 0}, o2.Weights())
+
+	Panics(t, func() {
+		NewOneWithWeights([]float64{1}, a, b)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"math"
 	"strconv"
+	"text/scanner"
 
 	"github.com/zimmski/tavor/token"
 	"github.com/zimmski/tavor/token/primitives"
@@ -16,6 +17,11 @@ type Repeat struct {
 	token token.Token
 	value []token.Token
 
+	weighted    bool
+	probability float64
+
+	position scanner.Position
+
 	reducing              bool
 	reducingOriginalValue []token.Token
 }
@@ -47,6 +53,20 @@ func NewRepeatWithTokens(tok token.Token, from token.Token, to token.Token) *Rep
 	return l
 }
 
+// NewRepeatWithProbability returns a new instance of a Repeat token referencing the given token and the given token range
+// Every repetition after the from value is done with the given probability which means that fewer repetitions are more likely for probabilities below 0.5.
+func NewRepeatWithProbability(tok token.Token, from token.Token, to token.Token, probability float64) *Repeat {
+	if probability < 0 || probability > 1 {
+		panic("probability must be between 0 and 1")
+	}
+
+	l := NewRepeatWithTokens(tok, from, to)
+	l.weighted = true
+	l.probability = probability
+
+	return l
+}
+
 // From returns the from value of the repeat range
 func (l *Repeat) From() int64 {
 	iFrom, err := strconv.Atoi(l.from.String())
@@ -67,6 +87,39 @@ func (l *Repeat) To() int64 {
 	return int64(iTo)
 }
 
+// Duplicate inserts a copy of the current referenced token at the given index directly after it. The error return argument is not nil, if the index is out of bound or if the maximum repetition is already reached.
+func (l *Repeat) Duplicate(i int) error {
+	if i < 0 || i >= len(l.value) {
+		return &ListError{ListErrorOutOfBound}
+	}
+	if int64(len(l.value)) >= l.To() {
+		return &ListError{ListErrorOutOfRepeatRange}
+	}
+
+	value := make([]token.Token, 0, len(l.value)+1)
+	value = append(value, l.value[:i+1]...)
+	value = append(value, l.value[i].Clone())
+	l.value = append(value, l.value[i+1:]...)
+
+	return nil
+}
+
+// Remove removes the current referenced token at the given index. The error return argument is not nil, if the index is out of bound or if the minimum repetition is already reached.
+func (l *Repeat) Remove(i int) error {
+	if i < 0 || i >= len(l.value) {
+		return &ListError{ListErrorOutOfBound}
+	}
+	if int64(len(l.value)) <= l.From() {
+		return &ListError{ListErrorOutOfRepeatRange}
+	}
+
+	value := make([]token.Token, 0, len(l.value)-1)
+	value = append(value, l.value[:i]...)
+	l.value = append(value, l.value[i+1:]...)
+
+	return nil
+}
+
 // Token interface methods
 
 // Clone returns a copy of the token and all its children
@@ -76,6 +129,11 @@ func (l *Repeat) Clone() token.Token {
 		to:    l.to,
 		token: l.token.Clone(),
 		value: make([]token.Token, len(l.value)),
+
+		weighted:    l.weighted,
+		probability: l.probability,
+
+		position: l.position,
 	}
 
 	for i, tok := range l.value {
@@ -172,7 +230,11 @@ func (l *Repeat) PermutationsAll() uint {
 	tokenPermutations := l.token.PermutationsAll()
 
 	for i := from; i <= l.To(); i++ {
-		sum += uint(math.Pow(float64(tokenPermutations), float64(i)))
+		sum = token.AddPermutations(sum, token.PowPermutations(tokenPermutations, int(i)))
+
+		if token.PermutationsOverflow(sum) {
+			break
+		}
 	}
 
 	return sum
@@ -435,6 +497,51 @@ func (l *Repeat) Reduce(i uint) error {
 	return nil
 }
 
+// ReduceSelect reduces the token to the given selection of its original children
+func (l *Repeat) ReduceSelect(selection []int) error {
+	n := l.ReduceSelectable()
+
+	if n == 0 || int64(len(selection)) < l.From() {
+		return &token.ReduceError{
+			Type: token.ReduceErrorInvalidSelection,
+		}
+	}
+	for i, c := range selection {
+		if c < 0 || c >= n || (i > 0 && c <= selection[i-1]) {
+			return &token.ReduceError{
+				Type: token.ReduceErrorInvalidSelection,
+			}
+		}
+	}
+
+	if !l.reducing {
+		l.reducing = true
+		l.reducingOriginalValue = l.value
+	}
+
+	tokens := make([]token.Token, len(selection))
+
+	for i, c := range selection {
+		tokens[i] = l.reducingOriginalValue[c]
+	}
+
+	l.value = tokens
+
+	return nil
+}
+
+// ReduceSelectable returns the number of original children which can be selected
+// This is zero if the token cannot be reduced at all.
+func (l *Repeat) ReduceSelectable() int {
+	if l.reducing {
+		return len(l.reducingOriginalValue)
+	} else if int(l.From()) < len(l.value) {
+		return len(l.value)
+	}
+
+	return 0
+}
+
 func factorial(n uint) uint {
 	c := n
 	n--
@@ -515,3 +622,49 @@ func (l *Repeat) Reset() error {
 
 	return nil
 }
+
+// Distributed interface methods
+
+// Uniform returns true if all permutations have the same probability
+func (l *Repeat) Uniform() bool {
+	return !l.weighted
+}
+
+// Quantile returns the permutation at the given quantile in [0, 1) of the distribution of the permutations
+func (l *Repeat) Quantile(x float64) uint {
+	n := l.Permutations()
+	if l.Uniform() {
+		return uint(x * float64(n))
+	} else if n == 0 {
+		return 0
+	}
+
+	// every additional repetition is done with the probability which makes the count of additional repetitions geometrically distributed, the last permutation takes the remaining probability
+	var k float64
+	switch l.probability {
+	case 0:
+		k = 0
+	case 1:
+		k = float64(n - 1)
+	default:
+		k = math.Floor(math.Log(1-x) / math.Log(l.probability))
+	}
+
+	if k >= float64(n-1) {
+		return n - 1
+	}
+
+	return uint(k)
+}
+
+// Positioned interface methods
+
+// Position returns the position of the token in the format source which is not valid if the position is unknown
+func (l *Repeat) Position() scanner.Position {
+	return l.position
+}
+
+// SetPosition sets the position of the token in the format source
+func (l *Repeat) SetPosition(pos scanner.Position) {
+	l.position = pos
+}
@@ -1,6 +1,8 @@
 package lists
 
 import (
+	"fmt"
+	"math"
 	"testing"
 
 	. "github.com/zimmski/tavor/test/assert"
@@ -88,6 +90,12 @@ func TestRepeat(t *testing.T) {
 
 	o2 := o.Clone()
 	Equal(t, o.String(), o2.String())
+
+	// counts which do not fit saturate instead of wrapping around
+	o = NewRepeat(primitives.NewRangeInt(0, 99), 1, 20)
+	Equal(t, token.MaxPermutations, o.PermutationsAll())
+	Equal(t, token.MaxPermutations, NewConcatenation(o, primitives.NewRangeInt(1, 3)).PermutationsAll())
+	Equal(t, token.MaxPermutations, NewOne(o, primitives.NewRangeInt(1, 3)).PermutationsAll())
 }
 
 func TestRepeatReduces(t *testing.T) {
@@ -388,3 +396,95 @@ func TestRepeatReduce(t *testing.T) {
 		}
 	}
 }
+
+func TestRepeatDuplicateAndRemove(t *testing.T) {
+	o := NewRepeat(primitives.NewRangeInt(0, 9), 1, 3)
+	o.value = []token.Token{
+		primitives.NewConstantInt(1),
+		primitives.NewConstantInt(2),
+	}
+
+	Equal(t, &ListError{ListErrorOutOfBound}, o.Duplicate(2))
+	Equal(t, &ListError{ListErrorOutOfBound}, o.Remove(-1))
+
+	Nil(t, o.Duplicate(0))
+	Equal(t, "112", o.String())
+	NotEqual(t, fmt.Sprintf("%p", o.value[0]), fmt.Sprintf("%p", o.value[1]))
+
+	Equal(t, &ListError{ListErrorOutOfRepeatRange}, o.Duplicate(0))
+
+	Nil(t, o.Remove(0))
+	Equal(t, "12", o.String())
+	Nil(t, o.Remove(1))
+	Equal(t, "1", o.String())
+
+	Equal(t, &ListError{ListErrorOutOfRepeatRange}, o.Remove(0))
+}
+
+func TestRepeatReduceSelect(t *testing.T) {
+	var reduceSelectTok *token.ReduceSelectToken
+
+	Implements(t, reduceSelectTok, &Repeat{})
+
+	o := NewRepeat(primitives.NewRangeInt(0, 9), 1, 10)
+	Equal(t, 0, o.ReduceSelectable())
+
+	for i := 0; i < 4; i++ {
+		if i > 0 {
+			Nil(t, o.Duplicate(i-1))
+		}
+
+		c, _ := o.Get(i)
+		Nil(t, c.Permutation(uint(i)))
+	}
+	Equal(t, "0123", o.String())
+	Equal(t, 4, o.ReduceSelectable())
+
+	Nil(t, o.ReduceSelect([]int{1, 3}))
+	Equal(t, "13", o.String())
+	Equal(t, 4, o.ReduceSelectable())
+
+	Nil(t, o.ReduceSelect([]int{0, 2, 3}))
+	Equal(t, "023", o.String())
+
+	Nil(t, o.ReduceSelect([]int{0, 1, 2, 3}))
+	Equal(t, "0123", o.String())
+
+	// the minimum repetition must be kept, indexes must be ascending and in bound
+	for _, selection := range [][]int{nil, {1, 0}, {1, 1}, {4}, {-1}} {
+		Equal(t, o.ReduceSelect(selection).(*token.ReduceError).Type, token.ReduceErrorInvalidSelection)
+	}
+	Equal(t, "0123", o.String())
+}
+
+func TestRepeatWithProbability(t *testing.T) {
+	a := primitives.NewConstantString("a")
+
+	True(t, NewRepeat(a, 0, 3).Uniform())
+	Equal(t, uint(2), NewRepeat(a, 0, 3).Quantile(0.5))
+
+	o := NewRepeatWithProbability(a, primitives.NewConstantInt(0), primitives.NewConstantInt(3), 0.5)
+	Equal(t, "", o.String())
+	Equal(t, 4, o.Permutations())
+	False(t, o.Uniform())
+
+	// the probabilities of the permutations are 0.5, 0.25, 0.125 and 0.125
+	for x, expected := range map[float64]uint{0: 0, 0.49: 0, 0.51: 1, 0.74: 1, 0.76: 2, 0.87: 2, 0.88: 3, 0.99: 3} {
+		Equal(t, expected, o.Quantile(x), x)
+	}
+
+	o2 := o.Clone().(*Repeat)
+	Equal(t, uint(2), o2.Quantile(0.8))
+
+	// every repetition is done
+	o = NewRepeatWithProbability(a, primitives.NewConstantInt(1), primitives.NewConstantInt(3), 1)
+	Equal(t, uint(2), o.Quantile(0))
+
+	// the permutations of huge repeats are not enumerated
+	o = NewRepeatWithProbability(a, primitives.NewConstantInt(0), primitives.NewConstantInt(math.MaxInt32), 0.5)
+	Equal(t, uint(6), o.Quantile(0.99))
+
+	Panics(t, func() {
+		NewRepeatWithProbability(a, primitives.NewConstantInt(1), primitives.NewConstantInt(3), -0.5)
+	})
+}
@@ -4,9 +4,9 @@ package token
 
 import "fmt"
 
-const _ParserErrorType_name = "ParseErrorNoStartParseErrorNewLineNeededParseErrorEarlyNewLineParseErrorEmptyExpressionIsInvalidParseErrorEmptyStringParseErrorEmptyTokenDefinitionParseErrorInvalidArgumentValueParseErrorInvalidTokenNameParseErrorInvalidTokenTypeParseErrorUnusedTokenParseErrorMissingTypedTokenArgumentParseErrorNonTerminatedStringParseErrorNoTokenForVariableParseErrorNotAlwaysUsedAsAVariableParseErrorRepeatWithOptionalTermParseErrorTokenAlreadyDefinedParseErrorTokenNotDefinedParseErrorTypeNotDefinedForTypedTokenParseErrorExpectRuneParseErrorExpectOperatorParseErrorUnknownBooleanOperatorParseErrorUnknownConditionParseErrorUnkownOperatorParseErrorUnknownTypedTokenArgumentParseErrorUnknownTypedTokenTypeParseErrorUnknownTokenAttributeParseErrorUnexpectedTokenDefinitionTerminationParseErrorExpectedExpressionTermParseErrEndlessLoopDetectedParseErrorExpectedEOFParseErrorRootIsNilParseErrorUnexpectedEOFParseErrorUnexpectedData"
+const _ParserErrorType_name = "ParseErrorNoStartParseErrorNewLineNeededParseErrorEarlyNewLineParseErrorEmptyExpressionIsInvalidParseErrorEmptyStringParseErrorEmptyTokenDefinitionParseErrorInvalidArgumentValueParseErrorInvalidByteLiteralParseErrorInvalidTokenNameParseErrorInvalidTokenTypeParseErrorUnusedTokenParseErrorMissingTypedTokenArgumentParseErrorNonTerminatedStringParseErrorNoTokenForVariableParseErrorNotAlwaysUsedAsAVariableParseErrorRepeatWithOptionalTermParseErrorTokenAlreadyDefinedParseErrorTokenNotDefinedParseErrorTypeNotDefinedForTypedTokenParseErrorExpectRuneParseErrorExpectOperatorParseErrorUnknownBooleanOperatorParseErrorUnknownConditionParseErrorUnkownOperatorParseErrorUnknownTypedTokenArgumentParseErrorUnknownTypedTokenTypeParseErrorUnknownTokenAttributeParseErrorUnexpectedTokenDefinitionTerminationParseErrorExpectedExpressionTermParseErrEndlessLoopDetectedParseErrorExpectedEOFParseErrorRootIsNilParseErrorUnexpectedEOFParseErrorUnexpectedData"
 
-var _ParserErrorType_index = [...]uint16{0, 17, 40, 62, 96, 117, 147, 177, 203, 229, 250, 285, 314, 342, 376, 408, 437, 462, 499, 519, 543, 575, 601, 625, 660, 691, 722, 768, 800, 827, 848, 867, 890, 914}
+var _ParserErrorType_index = [...]uint16{0, 17, 40, 62, 96, 117, 147, 177, 205, 231, 257, 278, 313, 342, 370, 404, 436, 465, 490, 527, 547, 571, 603, 629, 653, 688, 719, 750, 796, 828, 855, 876, 895, 918, 942}
 
 func (i ParserErrorType) String() string {
 	if i < 0 || i >= ParserErrorType(len(_ParserErrorType_index)-1) {
@@ -0,0 +1,59 @@
+package token
+
+import (
+	"fmt"
+)
+
+// MaxPermutations is the saturated permutation count. It marks that the real count of permutations does not fit into an uint.
+const MaxPermutations = ^uint(0)
+
+// AddPermutations returns the sum of two permutation counts which saturates at MaxPermutations
+func AddPermutations(a, b uint) uint {
+	if a > MaxPermutations-b {
+		return MaxPermutations
+	}
+
+	return a + b
+}
+
+// MulPermutations returns the product of two permutation counts which saturates at MaxPermutations
+func MulPermutations(a, b uint) uint {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	if a > MaxPermutations/b {
+		return MaxPermutations
+	}
+
+	return a * b
+}
+
+// PowPermutations returns the permutation count to the power of the given exponent which saturates at MaxPermutations
+func PowPermutations(base uint, exp int) uint {
+	var p uint = 1
+
+	for i := 0; i < exp; i++ {
+		p = MulPermutations(p, base)
+
+		if p == MaxPermutations || p == 0 {
+			break
+		}
+	}
+
+	return p
+}
+
+// PermutationsOverflow returns true if the permutation count is saturated
+func PermutationsOverflow(p uint) bool {
+	return p == MaxPermutations
+}
+
+// FormatPermutations returns the permutation count as text which marks saturated counts as overflow
+func FormatPermutations(p uint) string {
+	if PermutationsOverflow(p) {
+		return fmt.Sprintf("overflow (at least %d)", p)
+	}
+
+	return fmt.Sprintf("%d", p)
+}
@@ -0,0 +1,30 @@
+package token_test
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/token"
+)
+
+func TestPermutationsSaturation(t *testing.T) {
+	Equal(t, 5, token.AddPermutations(2, 3))
+	Equal(t, token.MaxPermutations, token.AddPermutations(token.MaxPermutations, 1))
+	Equal(t, token.MaxPermutations, token.AddPermutations(token.MaxPermutations-1, 2))
+
+	Equal(t, 6, token.MulPermutations(2, 3))
+	Equal(t, 0, token.MulPermutations(0, token.MaxPermutations))
+	Equal(t, token.MaxPermutations, token.MulPermutations(token.MaxPermutations/2+1, 2))
+
+	Equal(t, 1, token.PowPermutations(7, 0))
+	Equal(t, 343, token.PowPermutations(7, 3))
+	Equal(t, 0, token.PowPermutations(0, 3))
+	Equal(t, token.MaxPermutations, token.PowPermutations(10, 20))
+
+	False(t, token.PermutationsOverflow(token.MaxPermutations-1))
+	True(t, token.PermutationsOverflow(token.MaxPermutations))
+
+	Equal(t, "343", token.FormatPermutations(343))
+	Equal(t, "overflow (at least 18446744073709551615)", token.FormatPermutations(token.MaxPermutations))
+}
@@ -0,0 +1,278 @@
+package primitives
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/token"
+)
+
+// BinaryInt implements an unsigned integer token which is encoded as raw bytes
+// The integer is either encoded with a fixed width of 1, 2, 4 or 8 bytes in the given byte order, or if the width is 0 as an unsigned varint (LEB128) with a variable width of up to 10 bytes. Every permutation generates a new value within the defined range.
+type BinaryInt struct {
+	width int
+	order binary.ByteOrder
+	from  uint64
+	to    uint64
+
+	value uint64
+}
+
+// NewBinaryInt returns a new instance of a BinaryInt token with the given width in bytes, byte order and range.
+// A width of 0 defines a varint, in this case the byte order is ignored.
+func NewBinaryInt(width int, order binary.ByteOrder, from, to uint64) *BinaryInt {
+	switch width {
+	case 0, 1, 2, 4, 8:
+	default:
+		panic(fmt.Sprintf("invalid binary integer width %d", width))
+	}
+	if from > to {
+		panic("TODO implement that From can be bigger than To")
+	}
+	if to > binaryIntMax(width) {
+		panic(fmt.Sprintf("%d does not fit into %d bytes", to, width))
+	}
+	if order == nil {
+		order = binary.BigEndian
+	}
+
+	return &BinaryInt{
+		width: width,
+		order: order,
+		from:  from,
+		to:    to,
+
+		value: from,
+	}
+}
+
+func binaryIntMax(width int) uint64 {
+	if width == 0 || width == 8 {
+		return math.MaxUint64
+	}
+
+	return 1<<(8*uint(width)) - 1
+}
+
+type binaryIntType struct {
+	name  string
+	width int
+	order binary.ByteOrder
+}
+
+var binaryIntTypes = []binaryIntType{
+	{"u8", 1, binary.BigEndian},
+	{"u16le", 2, binary.LittleEndian},
+	{"u16be", 2, binary.BigEndian},
+	{"u32le", 4, binary.LittleEndian},
+	{"u32be", 4, binary.BigEndian},
+	{"u64le", 8, binary.LittleEndian},
+	{"u64be", 8, binary.BigEndian},
+	{"varint", 0, nil},
+}
+
+// NewBinaryIntType returns a new instance of a BinaryInt token holding the whole range of the binary integer type with the given name e.g. "u16be".
+// The second return argument is false if the type name is unknown.
+func NewBinaryIntType(name string) (*BinaryInt, bool) {
+	for _, t := range binaryIntTypes {
+		if t.name == name {
+			return NewBinaryInt(t.width, t.order, 0, binaryIntMax(t.width)), true
+		}
+	}
+
+	return nil, false
+}
+
+func init() {
+	for _, t := range binaryIntTypes {
+		t := t
+
+		token.RegisterTyped(t.name, func(argParser token.ArgumentsTypedParser) (token.Token, error) {
+			max := binaryIntMax(t.width)
+			if max > math.MaxInt64 {
+				max = math.MaxInt64
+			}
+
+			from := argParser.GetInt("from", 0)
+			to := argParser.GetInt("to", int(max))
+
+			if err := argParser.Err(); err != nil {
+				return nil, err
+			}
+
+			if from < 0 || uint64(from) > max {
+				return nil, fmt.Errorf("%q must be in the range 0-%d", "from", max)
+			}
+			if to < from || uint64(to) > max {
+				return nil, fmt.Errorf("%q must be in the range %d-%d", "to", from, max)
+			}
+
+			return NewBinaryInt(t.width, t.order, uint64(from), uint64(to)), nil
+		})
+	}
+}
+
+// From returns the from value of the range
+func (p *BinaryInt) From() uint64 {
+	return p.from
+}
+
+// To returns the to value of the range
+func (p *BinaryInt) To() uint64 {
+	return p.to
+}
+
+// Value returns the current value of the token
+func (p *BinaryInt) Value() uint64 {
+	return p.value
+}
+
+// Width returns the width of the encoded integer in bytes or 0 if the integer is encoded as a varint
+func (p *BinaryInt) Width() int {
+	return p.width
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (p *BinaryInt) Clone() token.Token {
+	return &BinaryInt{
+		width: p.width,
+		order: p.order,
+		from:  p.from,
+		to:    p.to,
+
+		value: p.value,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (p *BinaryInt) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	var v uint64
+	var nextIndex int
+
+	if p.width == 0 {
+		var n int
+		v, n = binary.Uvarint(pars.Data[cur:pars.DataLen])
+
+		if n == 0 {
+			return cur, []error{&token.ParserError{
+				Message: fmt.Sprintf("expected varint in range %d-%d but got early EOF", p.from, p.to),
+				Type:    token.ParseErrorUnexpectedEOF,
+
+				Position: pars.GetPosition(cur),
+			}}
+		} else if n < 0 {
+			return cur, []error{&token.ParserError{
+				Message: fmt.Sprintf("expected varint in range %d-%d but got an overflowing varint", p.from, p.to),
+				Type:    token.ParseErrorUnexpectedData,
+
+				Position: pars.GetPosition(cur),
+			}}
+		}
+
+		nextIndex = cur + n
+	} else {
+		nextIndex = cur + p.width
+
+		if nextIndex > pars.DataLen {
+			return cur, []error{&token.ParserError{
+				Message: fmt.Sprintf("expected %d byte integer in range %d-%d but got early EOF", p.width, p.from, p.to),
+				Type:    token.ParseErrorUnexpectedEOF,
+
+				Position: pars.GetPosition(cur),
+			}}
+		}
+
+		v = p.decode(pars.Data[cur:nextIndex])
+	}
+
+	if v < p.from || v > p.to {
+		return cur, []error{&token.ParserError{
+			Message: fmt.Sprintf("expected integer in range %d-%d but got %d", p.from, p.to, v),
+			Type:    token.ParseErrorUnexpectedData,
+
+			Position: pars.GetPosition(cur),
+		}}
+	}
+
+	p.value = v
+
+	log.Debugf("Parsed %d", p.value)
+
+	return nextIndex, nil
+}
+
+func (p *BinaryInt) decode(b []byte) uint64 {
+	switch p.width {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(p.order.Uint16(b))
+	case 4:
+		return uint64(p.order.Uint32(b))
+	default:
+		return p.order.Uint64(b)
+	}
+}
+
+// Encode returns the given value encoded with the width and byte order of the token. Bits which do not fit into the width are discarded.
+func (p *BinaryInt) Encode(v uint64) []byte {
+	if p.width == 0 {
+		b := make([]byte, binary.MaxVarintLen64)
+
+		return b[:binary.PutUvarint(b, v)]
+	}
+
+	b := make([]byte, p.width)
+
+	switch p.width {
+	case 1:
+		b[0] = byte(v)
+	case 2:
+		p.order.PutUint16(b, uint16(v))
+	case 4:
+		p.order.PutUint32(b, uint32(v))
+	default:
+		p.order.PutUint64(b, v)
+	}
+
+	return b
+}
+
+// Permutation sets a specific permutation for this token
+func (p *BinaryInt) Permutation(i uint) error {
+	permutations := p.Permutations()
+
+	if i < 0 || i >= permutations {
+		return &token.PermutationError{
+			Type: token.PermutationErrorIndexOutOfBound,
+		}
+	}
+
+	// ranges with more values than permutations are sampled evenly, the last permutation is always the last value
+	if i == permutations-1 {
+		p.value = p.to
+	} else {
+		p.value = p.from + uint64(i)*((p.to-p.from)/uint64(permutations-1))
+	}
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (p *BinaryInt) Permutations() uint {
+	return token.AddPermutations(uint(p.to-p.from), 1)
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (p *BinaryInt) PermutationsAll() uint {
+	return p.Permutations()
+}
+
+func (p *BinaryInt) String() string {
+	return string(p.Encode(p.value))
+}
@@ -0,0 +1,91 @@
+package primitives
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/token"
+)
+
+func TestBinaryIntTokensToBeTokens(t *testing.T) {
+	var tok *token.Token
+
+	Implements(t, tok, &BinaryInt{})
+}
+
+func TestBinaryInt(t *testing.T) {
+	o := NewBinaryInt(1, nil, 0, 255)
+	Equal(t, "\x00", o.String())
+
+	Equal(t, 256, o.Permutations())
+
+	Nil(t, o.Permutation(65))
+	Equal(t, "A", o.String())
+	Equal(t, uint64(65), o.Value())
+
+	Equal(t, o.Permutation(256).(*token.PermutationError).Type, token.PermutationErrorIndexOutOfBound)
+
+	o2 := o.Clone()
+	Equal(t, o.String(), o2.String())
+
+	// byte orders
+	o = NewBinaryInt(2, binary.LittleEndian, 0x0102, 0x0102)
+	Equal(t, "\x02\x01", o.String())
+
+	o = NewBinaryInt(2, binary.BigEndian, 0x0102, 0x0102)
+	Equal(t, "\x01\x02", o.String())
+
+	o = NewBinaryInt(4, binary.LittleEndian, 0x01020304, 0x01020304)
+	Equal(t, "\x04\x03\x02\x01", o.String())
+
+	o = NewBinaryInt(8, binary.BigEndian, 1, 1)
+	Equal(t, "\x00\x00\x00\x00\x00\x00\x00\x01", o.String())
+
+	// varint
+	o = NewBinaryInt(0, nil, 300, 300)
+	Equal(t, "\xac\x02", o.String())
+
+	o = NewBinaryInt(8, nil, 0, 1<<63)
+	Equal(t, uint(1<<63+1), o.Permutations())
+
+	Nil(t, o.Permutation(0))
+	Equal(t, uint64(0), o.Value())
+	Nil(t, o.Permutation(o.Permutations()-1))
+	Equal(t, uint64(1<<63), o.Value())
+
+	// ranges with more values than permutations
+	o = NewBinaryInt(8, nil, 0, math.MaxUint64)
+	Equal(t, token.MaxPermutations, o.Permutations())
+
+	Nil(t, o.Permutation(o.Permutations()-2))
+	Equal(t, uint64(math.MaxUint64-2), o.Value())
+	Nil(t, o.Permutation(o.Permutations()-1))
+	Equal(t, uint64(math.MaxUint64), o.Value())
+
+	// parse
+	o = NewBinaryInt(2, binary.BigEndian, 0, 1000)
+
+	i, errs := o.Parse(&token.InternalParser{Data: []byte("\x01\x02"), DataLen: 2}, 0)
+	Nil(t, errs)
+	Equal(t, 2, i)
+	Equal(t, uint64(0x0102), o.Value())
+
+	_, errs = o.Parse(&token.InternalParser{Data: []byte("\x01"), DataLen: 1}, 0)
+	Equal(t, token.ParseErrorUnexpectedEOF, errs[0].(*token.ParserError).Type)
+
+	_, errs = o.Parse(&token.InternalParser{Data: []byte("\xff\xff"), DataLen: 2}, 0)
+	Equal(t, token.ParseErrorUnexpectedData, errs[0].(*token.ParserError).Type)
+
+	o = NewBinaryInt(0, nil, 0, 1000)
+
+	i, errs = o.Parse(&token.InternalParser{Data: []byte("\xac\x02\x00"), DataLen: 3}, 0)
+	Nil(t, errs)
+	Equal(t, 2, i)
+	Equal(t, uint64(300), o.Value())
+
+	_, errs = o.Parse(&token.InternalParser{Data: []byte("\xac"), DataLen: 1}, 0)
+	Equal(t, token.ParseErrorUnexpectedEOF, errs[0].(*token.ParserError).Type)
+}
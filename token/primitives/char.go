@@ -3,6 +3,7 @@ package primitives
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -15,6 +16,7 @@ import (
 var simpleEscapes = map[rune]rune{
 	'-':  '-',
 	'\\': '\\',
+	'^':  '^',
 	'f':  '\f',
 	'n':  '\n',
 	'r':  '\r',
@@ -27,6 +29,27 @@ var characterClassEscapes = map[rune][]rune{
 	'w': []rune{'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'N', 'M', 'O', 'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z', 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'n', 'm', 'o', 'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z', '_'},
 }
 
+// CharacterUniverse defines the set of all characters which is used to complement character classes e.g. with negated patterns or with the \D escape
+type CharacterUniverse string
+
+const (
+	// CharacterUniversePrintableASCII holds all printable ASCII characters from the space to the tilde character
+	CharacterUniversePrintableASCII CharacterUniverse = "printable-ascii"
+	// CharacterUniverseASCII holds all ASCII characters
+	CharacterUniverseASCII CharacterUniverse = "ascii"
+	// CharacterUniverseUnicode holds all Unicode code points except the surrogate halves
+	CharacterUniverseUnicode CharacterUniverse = "unicode"
+)
+
+var characterUniverses = map[CharacterUniverse][]characterRange{
+	CharacterUniversePrintableASCII: []characterRange{{from: 0x20, to: 0x7E}},
+	CharacterUniverseASCII:          []characterRange{{from: 0x00, to: 0x7F}},
+	CharacterUniverseUnicode:        []characterRange{{from: 0x00, to: 0xD7FF}, {from: 0xE000, to: unicode.MaxRune}},
+}
+
+// DefaultCharacterUniverse is the universe of character classes which are created with NewCharacterClass
+const DefaultCharacterUniverse = CharacterUniversePrintableASCII
+
 // CharacterClass implements a char token which holds a pattern of characters and character classes
 // Each character class characters is added to the set of characters of the token. Every permutations chooses one character out of the available set of characters as the current value of the token.
 type CharacterClass struct {
@@ -45,12 +68,23 @@ type characterRange struct {
 	from, to rune
 }
 
-// NewCharacterClass returns a new instance of a CharacterClass token
+// NewCharacterClass returns a new instance of a CharacterClass token which complements its characters using the DefaultCharacterUniverse
 func NewCharacterClass(pattern string) *CharacterClass {
+	return NewCharacterClassWithUniverse(pattern, DefaultCharacterUniverse)
+}
+
+// NewCharacterClassWithUniverse returns a new instance of a CharacterClass token which complements its characters using the given universe.
+// A pattern starting with the character ^ is negated and holds all characters of the universe which are not defined by the rest of the pattern.
+func NewCharacterClassWithUniverse(pattern string, universe CharacterUniverse) *CharacterClass {
 	if pattern == "" {
 		panic("pattern is empty")
 	}
 
+	universeRanges, ok := characterUniverses[universe]
+	if !ok {
+		panic(fmt.Sprintf("Unknown character universe %q", universe))
+	}
+
 	/*
 
 		TODO FIXME FIXME FIXME
@@ -66,7 +100,12 @@ func NewCharacterClass(pattern string) *CharacterClass {
 	var lastChar rune
 	var isRange = false
 
+	negated := strings.HasPrefix(pattern, "^")
+
 	runes := strings.NewReader(pattern)
+	if negated {
+		_, _, _ = runes.ReadRune()
+	}
 
 	c, _, err := runes.ReadRune()
 
@@ -117,6 +156,48 @@ PARSING:
 				}
 
 				switch c {
+				case 'p', 'P':
+					if isRange {
+						panic("Range operator without range to character")
+					}
+
+					complement := c == 'P'
+
+					x, _, err := runes.ReadRune()
+					if err == io.EOF {
+						panic("early EOF for escaped character")
+					} else if err != nil {
+						break PARSING
+					}
+
+					name := string(x)
+
+					// the name of the Unicode class is either one letter or enclosed in braces
+					if x == '{' {
+						name = ""
+
+						for {
+							x, _, err = runes.ReadRune()
+							if err == io.EOF {
+								panic("early EOF for escaped character")
+							} else if err != nil {
+								break PARSING
+							} else if x == '}' {
+								break
+							}
+
+							name += string(x)
+						}
+					}
+
+					ranges := unicodeClassRanges(name)
+					if complement {
+						ranges = complementCharacterRanges(ranges, universeRanges)
+					}
+
+					charRanges = append(charRanges, ranges...)
+
+					lastCharIsRangeChar = false
 				case 'x':
 					x, _, err := runes.ReadRune()
 					if err == io.EOF {
@@ -185,13 +266,20 @@ PARSING:
 							panic("Range operator without range to character")
 						}
 
-						esc, ok := characterClassEscapes[c]
-						if !ok {
-							panic(fmt.Sprintf("Unknown escape character %q", c))
-						}
+						if esc, ok := characterClassEscapes[unicode.ToLower(c)]; ok && unicode.IsUpper(c) {
+							// uppercase escapes hold all characters of the universe which are not in their lowercase counterpart
+							var ranges []characterRange
+							for _, v := range esc {
+								ranges = append(ranges, characterRange{from: v, to: v})
+							}
 
-						for _, v := range esc {
-							add(v)
+							charRanges = append(charRanges, complementCharacterRanges(ranges, universeRanges)...)
+						} else if ok {
+							for _, v := range esc {
+								add(v)
+							}
+						} else {
+							panic(fmt.Sprintf("Unknown escape character %q", c))
 						}
 
 						lastCharIsRangeChar = false
@@ -209,6 +297,15 @@ PARSING:
 		panic(err)
 	}
 
+	if negated {
+		for _, v := range chars {
+			charRanges = append(charRanges, characterRange{from: v, to: v})
+		}
+
+		chars = nil
+		charRanges = complementCharacterRanges(charRanges, universeRanges)
+	}
+
 	if len(chars) == 0 && len(charRanges) == 0 {
 		panic("empty character class is not allowed")
 	}
@@ -245,6 +342,76 @@ PARSING:
 	}
 }
 
+// unicodeClassRanges returns the character ranges of the Unicode category or script with the given name
+func unicodeClassRanges(name string) []characterRange {
+	table, ok := unicode.Categories[name]
+	if !ok {
+		table, ok = unicode.Scripts[name]
+	}
+	if !ok {
+		panic(fmt.Sprintf("Unknown Unicode class %q", name))
+	}
+
+	var ranges []characterRange
+
+	add := func(lo, hi, stride rune) {
+		if stride == 1 {
+			ranges = append(ranges, characterRange{from: lo, to: hi})
+
+			return
+		}
+
+		for v := lo; v <= hi; v += stride {
+			ranges = append(ranges, characterRange{from: v, to: v})
+		}
+	}
+
+	for _, r := range table.R16 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	for _, r := range table.R32 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+
+	return ranges
+}
+
+// complementCharacterRanges returns the sorted ranges of all characters of the universe which are not in the given ranges
+func complementCharacterRanges(ranges []characterRange, universe []characterRange) []characterRange {
+	sorted := make([]characterRange, len(ranges))
+	copy(sorted, ranges)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].from < sorted[j].from
+	})
+
+	var complement []characterRange
+
+	for _, u := range universe {
+		from := u.from
+
+		for _, r := range sorted {
+			if r.to < from {
+				continue
+			} else if r.from > u.to {
+				break
+			}
+
+			if r.from > from {
+				complement = append(complement, characterRange{from: from, to: r.from - 1})
+			}
+
+			from = r.to + 1
+		}
+
+		if from <= u.to {
+			complement = append(complement, characterRange{from: from, to: u.to})
+		}
+	}
+
+	return complement
+}
+
 // Clone returns a copy of the token and all its children
 func (c *CharacterClass) Clone() token.Token {
 	chars := make([]rune, len(c.chars))
@@ -280,15 +447,14 @@ func (c *CharacterClass) Clone() token.Token {
 func (c *CharacterClass) Parse(pars *token.InternalParser, cur int) (int, []error) {
 	if cur+1 > pars.DataLen {
 		return cur, []error{&token.ParserError{
-			Message: fmt.Sprintf("expected %q but got early EOF", c.charsLookup),
+			Message: fmt.Sprintf("expected character of [%s] but got early EOF", c.pattern),
 			Type:    token.ParseErrorUnexpectedEOF,
 
 			Position: pars.GetPosition(cur),
 		}}
 	}
 
-	// TODO FIXME NOW we can see the need to put pars.Data into a reader... since we cannot do a readRune here!
-	v := rune(pars.Data[cur])
+	v, size := utf8.DecodeRune(pars.Data[cur:])
 
 	if _, ok := c.charsLookup[v]; !ok {
 		found := false
@@ -303,7 +469,7 @@ func (c *CharacterClass) Parse(pars *token.InternalParser, cur int) (int, []erro
 
 		if !found {
 			return cur, []error{&token.ParserError{
-				Message: fmt.Sprintf("expected %q or %+v but got %q", c.charsLookup, c.charRanges, v),
+				Message: fmt.Sprintf("expected character of [%s] but got %q", c.pattern, v),
 				Type:    token.ParseErrorUnexpectedData,
 
 				Position: pars.GetPosition(cur),
@@ -315,7 +481,7 @@ func (c *CharacterClass) Parse(pars *token.InternalParser, cur int) (int, []erro
 
 	log.Debugf("Parsed %q", v)
 
-	return cur + 1, nil
+	return cur + size, nil
 }
 
 func (c *CharacterClass) permutation(i uint) {
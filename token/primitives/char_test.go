@@ -2,6 +2,7 @@ package primitives
 
 import (
 	"testing"
+	"unicode"
 
 	. "github.com/zimmski/tavor/test/assert"
 
@@ -86,3 +87,99 @@ func TestCharacterClass(t *testing.T) {
 	o2 := o.Clone()
 	Equal(t, o.String(), o2.String())
 }
+
+func TestCharacterClassNegated(t *testing.T) {
+	o := NewCharacterClassWithUniverse(`^a-z`, CharacterUniversePrintableASCII)
+	Equal(t, " ", o.String())
+	Equal(t, 95-26, o.Permutations())
+
+	Nil(t, o.Permutation(64))
+	Equal(t, "`", o.String())
+
+	Nil(t, o.Permutation(65))
+	Equal(t, "{", o.String())
+
+	Nil(t, o.Permutation(68))
+	Equal(t, "~", o.String())
+
+	o = NewCharacterClassWithUniverse(`^\x00-\x1F\x7F`, CharacterUniverseASCII)
+	Equal(t, " ", o.String())
+	Equal(t, 95, o.Permutations())
+
+	o = NewCharacterClassWithUniverse(`^a`, CharacterUniverseUnicode)
+	Equal(t, 0x10FFFF+1-0x800-1, o.Permutations())
+
+	Nil(t, o.Permutation(0xD7FF-1))
+	Equal(t, string(rune(0xD7FF)), o.String())
+
+	Nil(t, o.Permutation(0xD7FF))
+	Equal(t, string(rune(0xE000)), o.String())
+
+	Panics(t, func() {
+		NewCharacterClassWithUniverse(`^\x00-\x7F`, CharacterUniverseASCII)
+	})
+
+	// ^ is only special as the first character
+	o = NewCharacterClass(`a\^`)
+	Equal(t, 2, o.Permutations())
+
+	Nil(t, o.Permutation(1))
+	Equal(t, "^", o.String())
+
+	o2 := o.Clone()
+	Equal(t, o.String(), o2.String())
+}
+
+func TestCharacterClassComplementEscapes(t *testing.T) {
+	o := NewCharacterClassWithUniverse(`\D`, CharacterUniversePrintableASCII)
+	Equal(t, 95-10, o.Permutations())
+
+	o = NewCharacterClassWithUniverse(`\S`, CharacterUniverseASCII)
+	Equal(t, 128-5, o.Permutations())
+
+	o = NewCharacterClassWithUniverse(`\W`, CharacterUniversePrintableASCII)
+	Equal(t, 95-63, o.Permutations())
+
+	for i := uint(0); i < o.Permutations(); i++ {
+		Nil(t, o.Permutation(i))
+
+		c := o.String()[0]
+		False(t, c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'), c)
+	}
+
+	// \D and \d together are the whole universe
+	o = NewCharacterClassWithUniverse(`\d\D`, CharacterUniversePrintableASCII)
+	Equal(t, 95, o.Permutations())
+}
+
+func TestCharacterClassUnicodeClasses(t *testing.T) {
+	o := NewCharacterClass(`\p{Greek}`)
+	Equal(t, "Ͱ", o.String())
+
+	for i := uint(0); i < o.Permutations(); i++ {
+		Nil(t, o.Permutation(i))
+
+		r := []rune(o.String())[0]
+		True(t, unicode.Is(unicode.Greek, r), r)
+	}
+
+	o = NewCharacterClass(`\pN`)
+	Equal(t, "0", o.String())
+
+	o = NewCharacterClass(`\p{Lu}a`)
+	Equal(t, "a", o.String())
+
+	Nil(t, o.Permutation(1))
+	Equal(t, "A", o.String())
+
+	// complements are limited to the universe
+	o = NewCharacterClassWithUniverse(`\P{L}`, CharacterUniversePrintableASCII)
+	Equal(t, 95-52, o.Permutations())
+
+	o = NewCharacterClassWithUniverse(`^\p{L}\p{N}`, CharacterUniverseASCII)
+	Equal(t, 128-62, o.Permutations())
+
+	Panics(t, func() {
+		NewCharacterClass(`\p{Unknown}`)
+	})
+}
@@ -0,0 +1,189 @@
+package primitives
+
+import (
+	"fmt"
+
+	"github.com/zimmski/tavor/encoding"
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/token"
+)
+
+// Encoded implements a token which transcodes the UTF-8 text of its referenced token into a character encoding
+// The token can additionally hold an invalid byte sequence of the encoding if it is injectable.
+type Encoded struct {
+	token    token.Token
+	name     string
+	encoding encoding.Encoding
+	mode     encoding.ErrorMode
+
+	injectable bool
+	injected   bool
+}
+
+// NewEncoded returns a new instance of an Encoded token given the referenced token, the name of the encoding and the mode for invalid and unrepresentable characters.
+// The error return argument is not nil if the encoding or the mode is unknown.
+func NewEncoded(tok token.Token, name string, mode encoding.ErrorMode) (*Encoded, error) {
+	enc, err := encoding.New(name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case encoding.ErrorModeReport, encoding.ErrorModeReplace, encoding.ErrorModeRaw:
+	default:
+		return nil, fmt.Errorf("unknown encoding error mode %q", mode)
+	}
+
+	return &Encoded{
+		token:    tok,
+		name:     name,
+		encoding: enc,
+		mode:     mode,
+	}, nil
+}
+
+// Name returns the name of the encoding
+func (e *Encoded) Name() string {
+	return e.name
+}
+
+// Mode returns the mode for invalid and unrepresentable characters
+func (e *Encoded) Mode() encoding.ErrorMode {
+	return e.mode
+}
+
+// Injectable returns if the token can hold an invalid byte sequence
+func (e *Encoded) Injectable() bool {
+	return e.injectable
+}
+
+// SetInjectable enables or disables that the token can hold an invalid byte sequence
+// Encodings for which every byte sequence is valid cannot hold an invalid byte sequence.
+func (e *Encoded) SetInjectable(injectable bool) {
+	e.injectable = injectable && e.encoding.Invalid() != nil
+
+	if !e.injectable {
+		e.injected = false
+	}
+}
+
+// Error returns the first invalid or unrepresentable character of the current value as error, or nil if there is none.
+// Errors are only returned with the report mode.
+func (e *Encoded) Error() error {
+	_, err := encoding.Encode(e.encoding, []byte(e.token.String()), e.mode)
+
+	return err
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (e *Encoded) Clone() token.Token {
+	return &Encoded{
+		token:    e.token.Clone(),
+		name:     e.name,
+		encoding: e.encoding,
+		mode:     e.mode,
+
+		injectable: e.injectable,
+		injected:   e.injected,
+	}
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+// The data is decoded before it is parsed by the referenced token. Errors of the referenced token therefore hold positions of the decoded text.
+func (e *Encoded) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	text, offsets, decodeErr := encoding.Decode(e.encoding, pars.Data[cur:pars.DataLen], e.mode)
+
+	i, errs := e.token.Parse(&token.InternalParser{
+		Data:    text,
+		DataLen: len(text),
+	}, 0)
+	if len(errs) > 0 {
+		// with the report mode the decoding stops at the first invalid byte sequence which is the more helpful error
+		if err, ok := decodeErr.(*encoding.Error); ok {
+			return cur, []error{&token.ParserError{
+				Message: fmt.Sprintf("expected %s data but got %s", e.name, err.Message),
+				Type:    token.ParseErrorUnexpectedData,
+
+				Position: pars.GetPosition(cur + err.Offset),
+			}}
+		}
+
+		return cur, errs
+	}
+
+	log.Debugf("Parsed %q as %s", text[:i], e.name)
+
+	return cur + offsets[i], nil
+}
+
+// Permutation sets a specific permutation for this token
+func (e *Encoded) Permutation(i uint) error {
+	permutations := e.Permutations()
+
+	if i < 0 || i >= permutations {
+		return &token.PermutationError{
+			Type: token.PermutationErrorIndexOutOfBound,
+		}
+	}
+
+	e.injected = i == 1
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (e *Encoded) Permutations() uint {
+	if e.injectable {
+		return 2
+	}
+
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (e *Encoded) PermutationsAll() uint {
+	return token.MulPermutations(e.Permutations(), e.token.PermutationsAll())
+}
+
+func (e *Encoded) String() string {
+	data, _ := encoding.Encode(e.encoding, []byte(e.token.String()), e.mode)
+
+	if e.injected {
+		data = append(e.encoding.Invalid(), data...)
+	}
+
+	return string(data)
+}
+
+// ForwardToken interface methods
+
+// Get returns the current referenced token
+func (e *Encoded) Get() token.Token {
+	return e.token
+}
+
+// InternalGet returns the current referenced internal token
+func (e *Encoded) InternalGet() token.Token {
+	return e.token
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (e *Encoded) InternalLogicalRemove(tok token.Token) token.Token {
+	if e.token == tok {
+		return nil
+	}
+
+	return e
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (e *Encoded) InternalReplace(oldToken, newToken token.Token) error {
+	if e.token == oldToken {
+		e.token = newToken
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package primitives
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/encoding"
+	"github.com/zimmski/tavor/token"
+)
+
+func TestEncodedTokensToBeTokens(t *testing.T) {
+	var tok *token.Token
+
+	Implements(t, tok, &Encoded{})
+
+	var forward *token.ForwardToken
+
+	Implements(t, forward, &Encoded{})
+}
+
+func TestEncoded(t *testing.T) {
+	_, err := NewEncoded(NewConstantString("a"), "ebcdic", encoding.ErrorModeReport)
+	NotNil(t, err)
+
+	_, err = NewEncoded(NewConstantString("a"), "utf16le", encoding.ErrorMode("ignore"))
+	NotNil(t, err)
+
+	o := NewCharacterClass(`a\x{20AC}`)
+
+	e, err := NewEncoded(o, "utf16le", encoding.ErrorModeReport)
+	Nil(t, err)
+	Equal(t, "utf16le", e.Name())
+	Equal(t, encoding.ErrorModeReport, e.Mode())
+	Equal(t, 1, e.Permutations())
+	Equal(t, 2, e.PermutationsAll())
+
+	Equal(t, "a\x00", e.String())
+	Nil(t, e.Error())
+
+	Nil(t, o.Permutation(1))
+	Equal(t, "\xac\x20", e.String())
+	Nil(t, e.Error())
+
+	e2 := e.Clone().(*Encoded)
+	Nil(t, e2.Get().Permutation(0))
+	Equal(t, "a\x00", e2.String())
+	Equal(t, "\xac\x20", e.String())
+
+	Equal(t, e.Permutation(1).(*token.PermutationError).Type, token.PermutationErrorIndexOutOfBound)
+
+	// permutations of injectable tokens saturate
+	e, err = NewEncoded(NewBinaryInt(8, nil, 0, 1<<63), "utf8", encoding.ErrorModeReport)
+	Nil(t, err)
+	e.SetInjectable(true)
+	Equal(t, 2, e.Permutations())
+	Equal(t, token.MaxPermutations, e.PermutationsAll())
+}
+
+func TestEncodedErrors(t *testing.T) {
+	s := NewConstantString("a€")
+
+	e, err := NewEncoded(s, "latin1", encoding.ErrorModeReport)
+	Nil(t, err)
+	Equal(t, "a?", e.String())
+	NotNil(t, e.Error())
+	Equal(t, encoding.ErrUnrepresentableCharacter, e.Error().(*encoding.Error).Type)
+
+	e, err = NewEncoded(s, "latin1", encoding.ErrorModeReplace)
+	Nil(t, err)
+	Equal(t, "a?", e.String())
+	Nil(t, e.Error())
+
+	e, err = NewEncoded(s, "latin1", encoding.ErrorModeRaw)
+	Nil(t, err)
+	Equal(t, "a€", e.String())
+	Nil(t, e.Error())
+}
+
+func TestEncodedInjection(t *testing.T) {
+	e, err := NewEncoded(NewConstantString("a"), "utf16be", encoding.ErrorModeReport)
+	Nil(t, err)
+
+	e.SetInjectable(true)
+	True(t, e.Injectable())
+	Equal(t, 2, e.Permutations())
+	Equal(t, "\x00a", e.String())
+
+	Nil(t, e.Permutation(1))
+	Equal(t, "\xd8\x00\x00a", e.String())
+	Equal(t, "\xd8\x00\x00a", e.Clone().String())
+
+	e.SetInjectable(false)
+	Equal(t, 1, e.Permutations())
+	Equal(t, "\x00a", e.String())
+
+	// every byte sequence is valid Latin-1
+	e, err = NewEncoded(NewConstantString("a"), "latin1", encoding.ErrorModeReport)
+	Nil(t, err)
+
+	e.SetInjectable(true)
+	False(t, e.Injectable())
+	Equal(t, 1, e.Permutations())
+}
+
+func TestEncodedParse(t *testing.T) {
+	o := NewCharacterClass(`a\x{20AC}`)
+
+	e, err := NewEncoded(o, "utf16le", encoding.ErrorModeReport)
+	Nil(t, err)
+
+	pars := &token.InternalParser{
+		Data: []byte("\xac\x20!"),
+	}
+	pars.DataLen = len(pars.Data)
+
+	i, errs := e.Parse(pars, 0)
+	Nil(t, errs)
+	Equal(t, 2, i)
+
+	pars = &token.InternalParser{
+		Data: []byte("x\x00a\x00b\x00"),
+	}
+	pars.DataLen = len(pars.Data)
+
+	i, errs = e.Parse(pars, 2)
+	Nil(t, errs)
+	Equal(t, 4, i)
+
+	// parse errors of the referenced token
+	pars = &token.InternalParser{
+		Data: []byte("c\x00"),
+	}
+	pars.DataLen = len(pars.Data)
+
+	i, errs = e.Parse(pars, 0)
+	NotNil(t, errs)
+	Equal(t, 0, i)
+
+	// an unpaired surrogate is reported as invalid data
+	pars = &token.InternalParser{
+		Data: []byte("a\x00\x00\xd8"),
+	}
+	pars.DataLen = len(pars.Data)
+
+	e, err = NewEncoded(NewConstantString("ab"), "utf16le", encoding.ErrorModeReport)
+	Nil(t, err)
+
+	i, errs = e.Parse(pars, 0)
+	NotNil(t, errs)
+	Equal(t, 0, i)
+	Equal(t, token.ParseErrorUnexpectedData, errs[0].(*token.ParserError).Type)
+	Equal(t, 3, errs[0].(*token.ParserError).Position.Column)
+
+	// the raw mode hands invalid byte sequences over
+	e, err = NewEncoded(NewConstantString("\x00\xd8"), "utf16le", encoding.ErrorModeRaw)
+	Nil(t, err)
+
+	i, errs = e.Parse(pars, 2)
+	Nil(t, errs)
+	Equal(t, 4, i)
+}
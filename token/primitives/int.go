@@ -57,7 +57,7 @@ func (p *ConstantInt) Parse(pars *token.InternalParser, cur int) (int, []error)
 		}}
 	}
 
-	if got := pars.Data[cur:nextIndex]; v != got {
+	if got := string(pars.Data[cur:nextIndex]); v != got {
 		return cur, []error{&token.ParserError{
 			Message: fmt.Sprintf("expected %q but got %q", v, got),
 			Type:    token.ParseErrorUnexpectedData,
@@ -200,6 +200,12 @@ func (p *RangeInt) Parse(pars *token.InternalParser, cur int) (int, []error) {
 	i := cur
 	v := ""
 
+	// negative integers are only parsed if they are in the range
+	if pars.Data[i] == '-' && p.from < 0 && i+1 < pars.DataLen {
+		v = "-"
+		i++
+	}
+
 	for {
 		c := pars.Data[i]
 
@@ -209,7 +215,7 @@ func (p *RangeInt) Parse(pars *token.InternalParser, cur int) (int, []error) {
 
 		v += string(c)
 
-		if ci, _ := strconv.Atoi(v); ci > p.to {
+		if ci, _ := strconv.Atoi(v); (v[0] != '-' && ci > p.to) || (v[0] == '-' && ci < p.from) {
 			v = v[:len(v)-1] // remove last digit
 
 			break
@@ -224,9 +230,9 @@ func (p *RangeInt) Parse(pars *token.InternalParser, cur int) (int, []error) {
 
 	i--
 
-	ci, _ := strconv.Atoi(v)
+	ci, err := strconv.Atoi(v)
 
-	if v == "" || (ci < p.from || ci > p.to) || ci%p.step != 0 {
+	if err != nil || (ci < p.from || ci > p.to) || ci%p.step != 0 {
 		// is the first character already invalid
 		if i < cur {
 			i = cur
@@ -268,14 +274,8 @@ func (p *RangeInt) Permutation(i uint) error {
 
 // Permutations returns the number of permutations for this token
 func (p *RangeInt) Permutations() uint {
-	// TODO FIXME this
-	perms := (p.to-p.from)/p.step + 1
-
-	if perms < 0 {
-		return math.MaxUint32
-	}
-
-	return uint(perms)
+	// the difference of the range does not fit into an int for ranges like the whole int range
+	return token.AddPermutations((uint(p.to)-uint(p.from))/uint(p.step), 1)
 }
 
 // PermutationsAll returns the number of all possible permutations for this token including its children
@@ -1,6 +1,8 @@
 package primitives
 
 import (
+	"math"
+	"strconv"
 	"testing"
 
 	. "github.com/zimmski/tavor/test/assert"
@@ -53,4 +55,60 @@ func TestRangeInt(t *testing.T) {
 	// range with negative lower value
 	o = NewRangeInt(-2, 1)
 	Equal(t, "-2", o.String())
+
+	// ranges which do not fit into an int
+	o = NewRangeInt(math.MinInt64, math.MaxInt64)
+	Equal(t, token.MaxPermutations, o.Permutations())
+
+	Nil(t, o.Permutation(0))
+	Equal(t, strconv.Itoa(math.MinInt64), o.String())
+
+	o = NewRangeInt(-1, math.MaxInt64)
+	Equal(t, uint(math.MaxInt64)+2, o.Permutations())
+
+	Nil(t, o.Permutation(o.Permutations()-1))
+	Equal(t, strconv.Itoa(math.MaxInt64), o.String())
+
+	o = NewRangeIntWithStep(math.MinInt64, math.MaxInt64, 4)
+	Equal(t, uint(1<<62), o.Permutations())
+}
+
+func TestRangeIntParse(t *testing.T) {
+	o := NewRangeInt(-20, 30)
+
+	for _, tc := range []struct {
+		data string
+		nex  int
+	}{
+		{"-20", 3},
+		{"-2", 2},
+		{"0", 1},
+		{"30", 2},
+		{"300", 2},
+		{"-200", 3},
+	} {
+		nex, errs := o.Parse(&token.InternalParser{
+			Data:    []byte(tc.data),
+			DataLen: len(tc.data),
+		}, 0)
+		Nil(t, errs, tc.data)
+		Equal(t, tc.nex, nex, tc.data)
+	}
+
+	for _, data := range []string{"-", "-a", "a"} {
+		_, errs := o.Parse(&token.InternalParser{
+			Data:    []byte(data),
+			DataLen: len(data),
+		}, 0)
+		NotNil(t, errs, data)
+	}
+
+	// negative integers are not parsed for positive ranges
+	o = NewRangeInt(0, 30)
+
+	_, errs := o.Parse(&token.InternalParser{
+		Data:    []byte("-1"),
+		DataLen: 2,
+	}, 0)
+	NotNil(t, errs)
 }
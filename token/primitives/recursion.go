@@ -0,0 +1,278 @@
+package primitives
+
+import (
+	"github.com/zimmski/container/list/linkedlist"
+
+	"github.com/zimmski/tavor/log"
+	"github.com/zimmski/tavor/token"
+)
+
+// Recursion implements a token which embeds a clone of the referenced token definition on its first use
+// Unlike pointers, which are unrolled while parsing, the referenced definition is cloned lazily. Recursion tokens within the clone are connected to their enclosing Recursion token so that the depth of the recursion can be determined. A nested Recursion token which would exceed its maximum depth is removed from the clone like a cut pointer during unrolling.
+type Recursion struct {
+	token    token.Token
+	maxDepth int
+	parent   *Recursion
+
+	instance     token.Token
+	instantiated bool
+	scope        *token.VariableScope
+}
+
+// NewRecursion returns a new instance of a Recursion token referencing the given token definition with the given maximum depth of nested recursions
+func NewRecursion(tok token.Token, maxDepth int) *Recursion {
+	if maxDepth < 1 {
+		panic("maximum depth of a recursion must be at least 1")
+	}
+
+	return &Recursion{
+		token:    tok,
+		maxDepth: maxDepth,
+	}
+}
+
+// MaxDepth returns the maximum depth of the recursion
+func (p *Recursion) MaxDepth() int {
+	return p.maxDepth
+}
+
+// Depth returns the count of Recursion tokens with the same referenced token definition from this token up to the outermost Recursion token
+func (p *Recursion) Depth() int {
+	ref := p.reference()
+	depth := 1
+
+	for r := p.parent; r != nil; r = r.parent {
+		if r.reference() == ref {
+			depth++
+		}
+	}
+
+	return depth
+}
+
+func (p *Recursion) reference() token.Token {
+	if t, ok := p.token.(*Pointer); ok {
+		return t.Resolve()
+	}
+
+	return p.token
+}
+
+func (p *Recursion) instantiate() {
+	p.instantiated = true
+
+	ref := p.reference()
+	if ref == nil {
+		panic("Recursion token does not have a referencing token")
+	}
+
+	c, err := token.UnrollPointers(ref.Clone())
+	if err != nil {
+		panic(err)
+	}
+
+	log.Debugf("instantiate (%p)%#v as (%p)%#v", p, p, c, c)
+
+	parents := make(map[token.Token]token.Token)
+	var exceeded []*Recursion
+
+	queue := linkedlist.New()
+
+	queue.Unshift(c)
+	parents[c] = nil
+
+	for !queue.Empty() {
+		v, _ := queue.Shift()
+
+		switch t := v.(type) {
+		case *Recursion:
+			t.parent = p
+			t.instance = nil
+			t.instantiated = false
+
+			if t.Depth() > t.maxDepth {
+				exceeded = append(exceeded, t)
+			}
+		case token.ForwardToken:
+			if n := t.InternalGet(); n != nil {
+				queue.Unshift(n)
+				parents[n] = t
+			}
+		case token.ListToken:
+			for i := t.InternalLen() - 1; i >= 0; i-- {
+				n, _ := t.InternalGet(i)
+
+				queue.Unshift(n)
+				parents[n] = t
+			}
+
+			// lists like repeats can hold clones of their internal tokens
+			for i := t.Len() - 1; i >= 0; i-- {
+				n, _ := t.Get(i)
+
+				if _, ok := parents[n]; !ok {
+					queue.Unshift(n)
+					parents[n] = t
+				}
+			}
+		}
+	}
+
+	p.instance = c
+
+	// remove recursions which exceed their maximum depth and all tokens which depend on them
+REMOVE:
+	for _, t := range exceeded {
+		log.Debugf("reached max depth of %d for (%p)%#v", t.maxDepth, t, t)
+
+		var ta token.Token = t
+		tt := parents[ta]
+
+		for tt != nil {
+			var r token.Token
+
+			switch l := tt.(type) {
+			case token.ForwardToken:
+				r = l.InternalLogicalRemove(ta)
+			case token.ListToken:
+				r = l.InternalLogicalRemove(ta)
+			}
+
+			if r != nil {
+				continue REMOVE
+			}
+
+			ta = tt
+			tt = parents[tt]
+		}
+
+		// every path of the instance depends on the recursion
+		p.instance = nil
+
+		break
+	}
+
+	if p.instance != nil && p.scope != nil {
+		token.SetScope(p.instance, p.scope)
+	}
+}
+
+// Token interface methods
+
+// Clone returns a copy of the token and all its children
+func (p *Recursion) Clone() token.Token {
+	n := &Recursion{
+		token:    p.token, // do not clone the definition
+		maxDepth: p.maxDepth,
+		parent:   p.parent,
+
+		instantiated: p.instantiated,
+		scope:        p.scope,
+	}
+
+	if p.instance != nil {
+		n.instance = p.instance.Clone()
+	}
+
+	return n
+}
+
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+func (p *Recursion) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	if i := p.Get(); i != nil {
+		return i.Parse(pars, cur)
+	}
+
+	return cur, nil
+}
+
+// Permutation sets a specific permutation for this token
+func (p *Recursion) Permutation(i uint) error {
+	permutations := p.Permutations()
+
+	if i < 0 || i >= permutations {
+		return &token.PermutationError{
+			Type: token.PermutationErrorIndexOutOfBound,
+		}
+	}
+
+	// do nothing
+
+	return nil
+}
+
+// Permutations returns the number of permutations for this token
+func (p *Recursion) Permutations() uint {
+	return 1
+}
+
+// PermutationsAll returns the number of all possible permutations for this token including its children
+func (p *Recursion) PermutationsAll() uint {
+	if i := p.Get(); i != nil {
+		return i.PermutationsAll()
+	}
+
+	return 1
+}
+
+func (p *Recursion) String() string {
+	if i := p.Get(); i != nil {
+		return i.String()
+	}
+
+	return ""
+}
+
+// ForwardToken interface methods
+
+// Get returns the current referenced token
+// The referenced token definition is cloned on the first call.
+func (p *Recursion) Get() token.Token {
+	if !p.instantiated {
+		p.instantiate()
+	}
+
+	return p.instance
+}
+
+// InternalGet returns the current referenced internal token
+// This is nil as long as the token was not used, since the referenced token definition is only cloned on its first use.
+func (p *Recursion) InternalGet() token.Token {
+	return p.instance
+}
+
+// InternalLogicalRemove removes the referenced internal token and returns the replacement for the current token or nil if the current token should be removed.
+func (p *Recursion) InternalLogicalRemove(tok token.Token) token.Token {
+	if p.instance == tok {
+		return nil
+	}
+
+	return p
+}
+
+// InternalReplace replaces an old with a new internal token if it is referenced by this token. The error return argument is not nil, if the replacement is not suitable.
+func (p *Recursion) InternalReplace(oldToken, newToken token.Token) error {
+	if p.instance == oldToken {
+		p.instance = newToken
+	}
+
+	return nil
+}
+
+// Minimize interface methods
+
+// Minimize tries to minimize itself and returns a token if it was successful, or nil if there was nothing to minimize
+func (p *Recursion) Minimize() token.Token {
+	// the instance must be created by the token itself to know about its depth
+
+	return nil
+}
+
+// Scope interface methods
+
+// SetScope sets the scope of the token
+// The scope is also used for instances which are created later on.
+func (p *Recursion) SetScope(variableScope *token.VariableScope) {
+	p.scope = variableScope
+}
@@ -0,0 +1,76 @@
+package primitives
+
+import (
+	"testing"
+
+	. "github.com/zimmski/tavor/test/assert"
+
+	"github.com/zimmski/tavor/token"
+)
+
+func TestRecursionTokensToBeTokens(t *testing.T) {
+	var tok *token.Token
+
+	Implements(t, tok, &Recursion{})
+
+	var forward *token.ForwardToken
+
+	Implements(t, forward, &Recursion{})
+}
+
+func TestRecursion(t *testing.T) {
+	a := NewRangeInt(4, 10)
+
+	o := NewRecursion(a, 1)
+	Nil(t, o.InternalGet())
+	Equal(t, 1, o.Depth())
+	Equal(t, 1, o.MaxDepth())
+
+	Equal(t, "4", o.String())
+	NotNil(t, o.InternalGet())
+	Equal(t, 1, o.Permutations())
+	Equal(t, 7, o.PermutationsAll())
+
+	Nil(t, o.Get().Permutation(1))
+	// this uses a clone
+	Equal(t, "5", o.String())
+	// this is the original one which must be untouched
+	Equal(t, "4", a.String())
+
+	o2 := o.Clone()
+	Equal(t, "5", o2.String())
+
+	Nil(t, o2.(*Recursion).Get().Permutation(3))
+	Equal(t, "5", o.String())
+	Equal(t, "7", o2.String())
+
+	Equal(t, o.Permutation(1).(*token.PermutationError).Type, token.PermutationErrorIndexOutOfBound)
+}
+
+func TestRecursionDepth(t *testing.T) {
+	var tokenInterface *token.Token
+
+	// Expr = $Expr.Recursion(3) which has only recursive paths
+	pointer := NewEmptyPointer(tokenInterface)
+	expr := NewScope(NewRecursion(pointer, 3))
+	Nil(t, pointer.Set(expr))
+
+	o := NewRecursion(pointer, 3)
+
+	recursions := 0
+	for r := o; r != nil; {
+		recursions++
+		Equal(t, recursions, r.Depth())
+
+		i := r.Get()
+		if i == nil {
+			break
+		}
+
+		r = i.(*Scope).Get().(*Recursion)
+	}
+
+	// the fourth recursion is removed and with it every path of the third one
+	Equal(t, 3, recursions)
+	Equal(t, "", o.String())
+}
@@ -42,7 +42,7 @@ func (p *ConstantString) Parse(pars *token.InternalParser, cur int) (int, []erro
 		}}
 	}
 
-	if got := pars.Data[cur:nextIndex]; p.value != got {
+	if got := string(pars.Data[cur:nextIndex]); p.value != got {
 		return cur, []error{&token.ParserError{
 			Message: fmt.Sprintf("expected %q but got %q", p.value, got),
 			Type:    token.ParseErrorUnexpectedData,
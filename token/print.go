@@ -12,7 +12,15 @@ func PrettyPrintTree(w io.Writer, root Token) {
 }
 
 func prettyPrintTreeRek(w io.Writer, tok Token, level int) {
-	fmt.Fprintf(w, "%s(%p)%#v %d Permutations\n", strings.Repeat("\t", level), tok, tok, tok.Permutations())
+	fmt.Fprintf(w, "%s(%p)%#v %d Permutations", strings.Repeat("\t", level), tok, tok, tok.Permutations())
+
+	if t, ok := tok.(Weighted); ok {
+		if weights := t.Weights(); weights != nil {
+			fmt.Fprintf(w, " with weights %v", weights)
+		}
+	}
+
+	fmt.Fprintln(w)
 
 	switch t := tok.(type) {
 	case ForwardToken:
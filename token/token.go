@@ -168,6 +168,20 @@ type ReduceToken interface {
 	Reduce
 }
 
+// ReduceSelect defines a reduce token which can be reduced to an arbitrary selection of its original children
+type ReduceSelect interface {
+	// ReduceSelect reduces the token to the given selection of its original children. The indexes of the selection have to be in ascending order.
+	ReduceSelect(selection []int) error
+	// ReduceSelectable returns the number of original children which can be selected
+	ReduceSelectable() int
+}
+
+// ReduceSelectToken combines the Token and ReduceSelect interface
+type ReduceSelectToken interface {
+	Token
+	ReduceSelect
+}
+
 // Release defines a release token which provides methods to release resources on removal
 type Release interface {
 	// Release gives the token a chance to remove resources
@@ -223,6 +237,58 @@ type VariableToken interface {
 	Variable
 }
 
+// Verify defines a verify token which can only check its parsed data after the whole token graph has been parsed, e.g. because its value depends on tokens which are parsed after it
+type Verify interface {
+	// Verify checks the parsed data of the token and returns an error if the data is not valid
+	Verify() error
+}
+
+// VerifyToken combines the Token and Verify interface
+type VerifyToken interface {
+	Token
+	Verify
+}
+
+// Weighted defines a weighted token whose permutations are not equally likely
+type Weighted interface {
+	// Weights returns the relative weights of the permutations of the token or nil if all permutations have the same weight
+	Weights() []float64
+}
+
+// WeightedToken combines the Token and Weighted interface
+type WeightedToken interface {
+	Token
+	Weighted
+}
+
+// Distributed defines a token whose permutations are not equally likely and follow a distribution, which is used instead of weights if the token can have too many permutations to weight each of them
+type Distributed interface {
+	// Uniform returns true if all permutations have the same probability
+	Uniform() bool
+	// Quantile returns the permutation at the given quantile in [0, 1) of the distribution of the permutations
+	Quantile(x float64) uint
+}
+
+// DistributedToken combines the Token and Distributed interface
+type DistributedToken interface {
+	Token
+	Distributed
+}
+
+// Positioned defines a token which knows its position in the format source
+type Positioned interface {
+	// Position returns the position of the token in the format source which is not valid if the position is unknown
+	Position() scanner.Position
+	// SetPosition sets the position of the token in the format source
+	SetPosition(pos scanner.Position)
+}
+
+// PositionedToken combines the Token and Positioned interface
+type PositionedToken interface {
+	Token
+	Positioned
+}
+
 ////////////////////////
 
 // TODO put this somewhere else?
@@ -255,6 +321,8 @@ type ReduceErrorType int
 const (
 	// ReduceErrorIndexOutOfBound an index not in the bound of available reductions was used.
 	ReduceErrorIndexOutOfBound ReduceErrorType = iota
+	// ReduceErrorInvalidSelection a selection of children which is not allowed by the token was used.
+	ReduceErrorInvalidSelection
 )
 
 // ReduceError holds a reduce error
@@ -266,6 +334,8 @@ func (err *ReduceError) Error() string {
 	switch err.Type {
 	case ReduceErrorIndexOutOfBound:
 		return "reduce index out of bound"
+	case ReduceErrorInvalidSelection:
+		return "invalid reduce selection"
 	default:
 		return fmt.Sprintf("unknown reduce error type %#v", err.Type)
 	}
@@ -359,7 +429,7 @@ func (s *VariableScope) Push() *VariableScope {
 
 // InternalParser holds the data information for an internal parser
 type InternalParser struct { // TODO move this some place else
-	Data    string
+	Data    []byte
 	DataLen int
 }
 
@@ -406,6 +476,8 @@ const (
 	ParseErrorEmptyTokenDefinition
 	// ParseErrorInvalidArgumentValue invalid argument value
 	ParseErrorInvalidArgumentValue
+	// ParseErrorInvalidByteLiteral invalid byte literal or byte range
+	ParseErrorInvalidByteLiteral
 	// ParseErrorInvalidTokenName invalid token name
 	ParseErrorInvalidTokenName
 	// ParseErrorInvalidTokenType invalid token type
@@ -49,7 +49,7 @@ func (v *Variable) Clone() token.Token {
 // Parse tries to parse the token beginning from the current position in the parser data.
 // If the parsing is successful the error argument is nil and the next current position after the token is returned.
 func (v *Variable) Parse(pars *token.InternalParser, cur int) (int, []error) {
-	panic("TODO implement")
+	return v.token.Parse(pars, cur)
 }
 
 // Permutation sets a specific permutation for this token
@@ -288,6 +288,13 @@ func (v *VariableSave) String() string {
 	return ""
 }
 
+// Parse tries to parse the token beginning from the current position in the parser data.
+// If the parsing is successful the error argument is nil and the next current position after the token is returned.
+// The referenced token has no output and is therefore not parsed.
+func (v *VariableSave) Parse(pars *token.InternalParser, cur int) (int, []error) {
+	return cur, nil
+}
+
 // NewVariableSave returns a new instance of a VariableSave token
 func NewVariableSave(name string, token token.Token) *VariableSave {
 	return &VariableSave{
@@ -409,8 +416,9 @@ func (v *VariableValue) Clone() token.Token {
 
 // Parse tries to parse the token beginning from the current position in the parser data.
 // If the parsing is successful the error argument is nil and the next current position after the token is returned.
+// The output of the referenced variable has to be present.
 func (v *VariableValue) Parse(pars *token.InternalParser, cur int) (int, []error) {
-	panic("TODO implement")
+	return primitives.NewConstantString(v.String()).Parse(pars, cur)
 }
 
 // Permutation sets a specific permutation for this token
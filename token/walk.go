@@ -126,3 +126,14 @@ func ReleaseTokens(root Token) {
 		return nil
 	})
 }
+
+// VerifyTokens traverses the token graph and calls Verify for every verify token. The first failed verification is returned.
+func VerifyTokens(root Token) error {
+	return Walk(root, func(tok Token) error {
+		if t, ok := tok.(VerifyToken); ok {
+			return t.Verify()
+		}
+
+		return nil
+	})
+}